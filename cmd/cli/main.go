@@ -55,6 +55,18 @@ func invite(authService services.AuthService, args []string) error {
 	return authService.SendInvitation(context.Background(), args[0], "en", true)
 }
 
+func rotateKeys(authService services.AuthService, args []string) error {
+	immediate := false
+	if len(args) > 0 {
+		if args[0] != "--immediate" {
+			fmt.Println("USAGE h-id-cli rotate-keys [--immediate]")
+			os.Exit(1)
+		}
+		immediate = true
+	}
+	return authService.RotateJWTKeys(context.Background(), immediate)
+}
+
 func run(args []string) error {
 	var db repos.DB
 	var err error
@@ -75,7 +87,7 @@ func run(args []string) error {
 	tokenRepo := db.NewTokenRepository()
 	emailService := services.NewEmailService(hid.EmailFS)
 	systemRepo := db.NewSystemRepository()
-	authService, err := services.NewAuthService(userRepo, tokenRepo, nil, nil, systemRepo, nil, emailService)
+	authService, err := services.NewAuthService(userRepo, tokenRepo, nil, nil, systemRepo, nil, nil, emailService, nil)
 	if err != nil {
 		return fmt.Errorf("initialize auth service: %w", err)
 	}
@@ -84,6 +96,7 @@ func run(args []string) error {
 COMMANDS
 		- set-admin <user_id|email> <true|false>
 		- invite <email>
+		- rotate-keys [--immediate]
 		`)
 		os.Exit(1)
 	}
@@ -92,6 +105,8 @@ COMMANDS
 		err = setAdmin(userRepo, args[1:])
 	case "invite":
 		err = invite(authService, args[1:])
+	case "rotate-keys":
+		err = rotateKeys(authService, args[1:])
 	default:
 		err = fmt.Errorf("unknown command: %s", args[0])
 	}
@@ -49,9 +49,11 @@ func run() error {
 	clientRepo := db.NewClientRepository()
 	oauthRepo := db.NewOAuthRepository()
 	systemRepo := db.NewSystemRepository()
+	gatewayRepo := db.NewGatewayRepository()
 
 	handler.SessionManager = scs.New()
-	handler.SessionManager.Store = db.NewSessionRepository()
+	sessionRepo := services.NewSessionStore(db.NewSessionRepository(), handler.SessionManager.Codec)
+	handler.SessionManager.Store = sessionRepo
 	handler.SessionManager.Lifetime = config.SessionLifetime()
 	handler.SessionManager.IdleTimeout = config.SessionIdleTimeout()
 	handler.SessionManager.Cookie.Secure = true
@@ -61,23 +63,36 @@ func run() error {
 	emailService := services.NewEmailService(hid.EmailFS)
 
 	handler.EmailService = emailService
-	handler.AuthService, err = services.NewAuthService(userRepo, tokenRepo, oauthRepo, clientRepo, systemRepo, handler.SessionManager, emailService)
+	handler.AuthGatewayService, err = services.NewAuthGatewayService(context.Background(), gatewayRepo, userRepo, clientRepo)
+	if err != nil {
+		return fmt.Errorf("Failed to initialize auth gateway service: %w", err)
+	}
+
+	handler.AuthService, err = services.NewAuthService(userRepo, tokenRepo, oauthRepo, clientRepo, systemRepo, sessionRepo, handler.SessionManager, emailService, handler.AuthGatewayService)
 	if err != nil {
 		return fmt.Errorf("new auth service: %w", err)
 	}
+	err = handler.AuthService.IndexSessions(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed to index sessions: %w", err)
+	}
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	go handler.AuthService.RunJWTKeyRotation(backgroundCtx, config.JWTKeyRotationInterval())
+	go handler.AuthService.RunBackchannelLogout(backgroundCtx)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go handler.AuthGatewayService.RunConfigWatcher(backgroundCtx, sighup)
+
 	handler.UserService = services.NewUserService(userRepo, handler.AuthService, emailService)
-	handler.ClientService = services.NewClientService(clientRepo)
+	handler.ClientService = services.NewClientService(clientRepo, userRepo)
 
 	handler.Renderer, err = handlers.NewRenderer(hid.HTMLFS)
 	if err != nil {
 		return fmt.Errorf("Failed to initialize renderer: %w", err)
 	}
 
-	handler.AuthGatewayService, err = services.NewAuthGatewayService()
-	if err != nil {
-		return fmt.Errorf("Failed to initialize auth gateway service: %w", err)
-	}
-
 	handler.StaticFS = hid.StaticFS
 	handler.RegisterRoutes()
 
@@ -61,6 +61,18 @@ func BehindProxy() (b bool) {
 	return b
 }
 
+func BackchannelLogoutAllowPrivate() (b bool) {
+	if c, ok := values["BACKCHANNEL_LOGOUT_ALLOW_PRIVATE"]; ok {
+		return c.(bool)
+	}
+	defer func() {
+		values["BACKCHANNEL_LOGOUT_ALLOW_PRIVATE"] = b
+	}()
+	str := os.Getenv("BACKCHANNEL_LOGOUT_ALLOW_PRIVATE")
+	b, _ = strconv.ParseBool(str)
+	return b
+}
+
 func Port() (port int) {
 	if p, ok := values["PORT"]; ok {
 		return p.(int)
@@ -296,6 +308,30 @@ func SessionIdleTimeout() (d time.Duration) {
 	return d
 }
 
+func JWTKeyRotationInterval() (d time.Duration) {
+	if a, ok := values["JWT_KEY_ROTATION_INTERVAL"]; ok {
+		return a.(time.Duration)
+	}
+	defer func() {
+		values["JWT_KEY_ROTATION_INTERVAL"] = d
+	}()
+	def := time.Duration(0)
+	durStr := os.Getenv("JWT_KEY_ROTATION_INTERVAL")
+	if durStr == "" {
+		return def
+	}
+	d, err := time.ParseDuration(durStr)
+	if err != nil {
+		log.Errorf("invalid JWT_KEY_ROTATION_INTERVAL: %s", err)
+		return def
+	}
+	if d < 2*time.Hour {
+		log.Errorf("invalid JWT_KEY_ROTATION_INTERVAL: key rotation interval must not be < 2h")
+		return def
+	}
+	return d
+}
+
 func AuthGatewayConfig() (path string) {
 	if c, ok := values["AUTH_GATEWAY_CONFIG"]; ok {
 		return c.(string)
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN client_type text NOT NULL DEFAULT 'confidential';
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN client_type;
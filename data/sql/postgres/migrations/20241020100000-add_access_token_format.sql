@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN access_token_format text NOT NULL DEFAULT 'opaque';
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN access_token_format;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE jwt_keys (
+	id text PRIMARY KEY,
+	created_at bigint NOT NULL,
+	algorithm text NOT NULL,
+	private bytea NOT NULL,
+	public bytea NOT NULL,
+	active_at bigint NOT NULL,
+	retired_at bigint NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE jwt_keys;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN post_logout_redirect_uris bytea NOT NULL DEFAULT '[]';
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN post_logout_redirect_uris;
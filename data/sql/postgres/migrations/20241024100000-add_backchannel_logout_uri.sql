@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN backchannel_logout_uri text NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN backchannel_logout_uri;
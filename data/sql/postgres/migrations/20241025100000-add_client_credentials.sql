@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN client_credentials_scopes text NOT NULL DEFAULT '';
+-- tokens issued with the client credentials grant have no user
+ALTER TABLE oauth ALTER COLUMN user_id DROP NOT NULL;
+
+-- +migrate Down
+DELETE FROM oauth WHERE user_id IS NULL;
+ALTER TABLE oauth ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE clients DROP COLUMN client_credentials_scopes;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN token_exchange boolean NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN token_exchange;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE scopes (
+	id text NOT NULL PRIMARY KEY,
+	created_at bigint NOT NULL,
+	client_id text NOT NULL,
+	name text NOT NULL UNIQUE,
+	audience text NOT NULL,
+	descriptions bytea NOT NULL,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE scopes;
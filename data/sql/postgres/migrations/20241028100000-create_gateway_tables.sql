@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE gateway_groups (
+	id text NOT NULL PRIMARY KEY,
+	created_at bigint NOT NULL,
+	name text NOT NULL UNIQUE
+);
+
+CREATE TABLE gateway_group_members (
+	group_id text NOT NULL,
+	user_id text NOT NULL,
+	created_at bigint NOT NULL,
+	PRIMARY KEY (group_id, user_id),
+	FOREIGN KEY (group_id) REFERENCES gateway_groups (id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE gateway_domains (
+	id text NOT NULL PRIMARY KEY,
+	created_at bigint NOT NULL,
+	domain text NOT NULL UNIQUE,
+	group_ids text NOT NULL,
+	user_ids text NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE gateway_domains;
+DROP TABLE gateway_group_members;
+DROP TABLE gateway_groups;
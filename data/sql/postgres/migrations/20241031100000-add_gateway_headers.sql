@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE gateway_domains ADD COLUMN headers text NOT NULL DEFAULT 'Remote-User,Remote-Name,Remote-Email,Remote-Groups';
+
+-- +migrate Down
+ALTER TABLE gateway_domains DROP COLUMN headers;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE app_passwords (
+	id text NOT NULL PRIMARY KEY,
+	created_at bigint NOT NULL,
+	user_id text NOT NULL,
+	name text NOT NULL,
+	scopes text NOT NULL,
+	password_hash bytea NOT NULL UNIQUE,
+	last_used bigint NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+ALTER TABLE clients ADD COLUMN password_grant boolean NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN password_grant;
+DROP TABLE app_passwords;
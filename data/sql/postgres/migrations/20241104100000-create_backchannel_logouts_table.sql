@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE backchannel_logouts (
+	id text NOT NULL PRIMARY KEY,
+	created_at bigint NOT NULL,
+	client_id text NOT NULL,
+	user_id text NOT NULL,
+	sid text NOT NULL,
+	attempt bigint NOT NULL,
+	next_attempt bigint NOT NULL,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+CREATE INDEX backchannel_logouts_next_attempt_idx ON backchannel_logouts (next_attempt);
+
+-- +migrate Down
+DROP TABLE backchannel_logouts;
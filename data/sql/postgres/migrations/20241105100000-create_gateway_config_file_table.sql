@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE gateway_config_file (
+	id bigint NOT NULL PRIMARY KEY CHECK (id = 1),
+	applied_at bigint NOT NULL,
+	hash bytea NOT NULL,
+	data bytea NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE gateway_config_file;
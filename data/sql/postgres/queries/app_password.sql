@@ -0,0 +1,16 @@
+-- name: CreateAppPassword :one
+INSERT INTO app_passwords (
+  id, created_at, user_id, name, scopes, password_hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+-- name: FindAppPasswords :many
+SELECT * FROM app_passwords WHERE user_id = $1 ORDER BY created_at;
+-- name: FindAppPassword :one
+SELECT * FROM app_passwords WHERE user_id = $1 AND id = $2;
+-- name: FindAppPasswordByHash :one
+SELECT * FROM app_passwords WHERE user_id = $1 AND password_hash = $2;
+-- name: UpdateAppPasswordLastUsed :execresult
+UPDATE app_passwords SET last_used = $1 WHERE user_id = $2 AND id = $3;
+-- name: DeleteAppPassword :execresult
+DELETE FROM app_passwords WHERE user_id = $1 AND id = $2;
@@ -0,0 +1,12 @@
+-- name: CreateBackchannelLogout :exec
+INSERT INTO backchannel_logouts (
+  id, created_at, client_id, user_id, sid, attempt, next_attempt
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+);
+-- name: FindDueBackchannelLogouts :many
+SELECT * FROM backchannel_logouts WHERE next_attempt <= $1 ORDER BY next_attempt;
+-- name: UpdateBackchannelLogoutAttempt :execresult
+UPDATE backchannel_logouts SET attempt = $1, next_attempt = $2 WHERE id = $3;
+-- name: DeleteBackchannelLogout :execresult
+DELETE FROM backchannel_logouts WHERE id = $1;
@@ -6,14 +6,14 @@ SELECT * FROM clients WHERE user_id = $1 AND id = $2;
 SELECT * FROM clients WHERE user_id = $1;
 -- name: CreateClient :one
 INSERT INTO clients (
-  id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 ) RETURNING *;
 -- name: UpdateClient :one
 UPDATE clients SET
-  name = $1, description = $2, website = $3, redirect_uris = $4
-WHERE user_id = $5 AND id = $6
+  name = $1, description = $2, website = $3, redirect_uris = $4, access_token_format = $5, id_token_signed_response_alg = $6, post_logout_redirect_uris = $7, backchannel_logout_uri = $8, client_credentials_scopes = $9, token_exchange = $10, password_grant = $11
+WHERE user_id = $12 AND id = $13
 RETURNING *;
 -- name: UpdateClientSecret :execresult
 UPDATE clients SET secret_hash = $1 WHERE user_id = $2 AND id = $3;
@@ -0,0 +1,40 @@
+-- name: FindGatewayGroups :many
+SELECT * FROM gateway_groups ORDER BY name;
+-- name: FindGatewayGroup :one
+SELECT * FROM gateway_groups WHERE id = $1;
+-- name: CreateGatewayGroup :one
+INSERT INTO gateway_groups (
+  id, created_at, name
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+-- name: UpdateGatewayGroup :one
+UPDATE gateway_groups SET name = $1 WHERE id = $2 RETURNING *;
+-- name: DeleteGatewayGroup :execresult
+DELETE FROM gateway_groups WHERE id = $1;
+-- name: FindGatewayGroupMembers :many
+SELECT * FROM gateway_group_members WHERE group_id = $1 ORDER BY created_at;
+-- name: FindAllGatewayGroupMembers :many
+SELECT * FROM gateway_group_members;
+-- name: AddGatewayGroupMember :execresult
+INSERT INTO gateway_group_members (
+  group_id, user_id, created_at
+) VALUES (
+  $1, $2, $3
+);
+-- name: RemoveGatewayGroupMember :execresult
+DELETE FROM gateway_group_members WHERE group_id = $1 AND user_id = $2;
+-- name: FindGatewayDomains :many
+SELECT * FROM gateway_domains ORDER BY domain;
+-- name: FindGatewayDomain :one
+SELECT * FROM gateway_domains WHERE id = $1;
+-- name: CreateGatewayDomain :one
+INSERT INTO gateway_domains (
+  id, created_at, domain, group_ids, user_ids, rules, max_auth_age, require_2fa, headers, bearer_scope
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+-- name: UpdateGatewayDomain :one
+UPDATE gateway_domains SET domain = $1, group_ids = $2, user_ids = $3, rules = $4, max_auth_age = $5, require_2fa = $6, headers = $7, bearer_scope = $8 WHERE id = $9 RETURNING *;
+-- name: DeleteGatewayDomain :execresult
+DELETE FROM gateway_domains WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: FindGatewayConfigFile :one
+SELECT * FROM gateway_config_file WHERE id = 1;
+-- name: SetGatewayConfigFile :exec
+INSERT INTO gateway_config_file (
+  id, applied_at, hash, data
+) VALUES (
+  1, $1, $2, $3
+) ON CONFLICT (id) DO UPDATE SET applied_at = excluded.applied_at, hash = excluded.hash, data = excluded.data;
@@ -1,15 +1,19 @@
 -- name: CreateOAuthToken :one
 INSERT INTO oauth (
-  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used
+  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used, grant_id
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11
 ) RETURNING *;
 -- name: FindOAuthToken :one
 SELECT * FROM oauth WHERE category = $1 AND token_hash = $2 AND expires > sqlc.arg(now);
+-- name: UpdateOAuthToken :execresult
+UPDATE oauth SET user_id = $1, data = $2 WHERE client_id = $3 AND category = $4 AND token_hash = $5;
 -- name: UseOAuthToken :execresult
-UPDATE oauth SET used = TRUE WHERE client_id = $1 AND category = $2 AND token_hash = $3;
+UPDATE oauth SET used = TRUE WHERE client_id = $1 AND category = $2 AND token_hash = $3 AND used = FALSE;
 -- name: DeleteOAuthToken :execresult
 DELETE FROM oauth WHERE (client_id = $1 AND category = $2 AND token_hash = $3) OR expires < sqlc.arg(now);
+-- name: DeleteOAuthTokensByGrant :exec
+DELETE FROM oauth WHERE (client_id = $1 AND grant_id = $2) OR expires < sqlc.arg(now);
 -- name: DeleteOAuthTokenByUser :exec
 DELETE FROM oauth WHERE (client_id = $1 AND user_id = $2) OR expires < sqlc.arg(now);
 -- name: SetOAuthPermissions :one
@@ -20,5 +24,7 @@ ON CONFLICT(client_id,user_id) DO UPDATE SET created_at = $1, client_id = $2, us
 RETURNING *;
 -- name: FindOAuthPermissions :one
 SELECT * FROM permissions WHERE client_id = $1 AND user_id = $2;
+-- name: FindOAuthPermissionsByUser :many
+SELECT * FROM permissions WHERE user_id = $1;
 -- name: RevokeOAuthPermissions :execresult
 DELETE FROM permissions WHERE client_id = $1 AND user_id = $2;
@@ -0,0 +1,18 @@
+-- name: FindScope :one
+SELECT * FROM scopes WHERE client_id = $1 AND id = $2;
+-- name: FindScopeByName :one
+SELECT * FROM scopes WHERE name = $1;
+-- name: FindScopesByAudience :many
+SELECT * FROM scopes WHERE audience = $1;
+-- name: FindScopesByClient :many
+SELECT * FROM scopes WHERE client_id = $1 ORDER BY name;
+-- name: CreateScope :one
+INSERT INTO scopes (
+  id, created_at, client_id, name, audience, descriptions
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+-- name: UpdateScope :one
+UPDATE scopes SET audience = $1, descriptions = $2 WHERE client_id = $3 AND id = $4 RETURNING *;
+-- name: DeleteScope :execresult
+DELETE FROM scopes WHERE client_id = $1 AND id = $2;
\ No newline at end of file
@@ -2,12 +2,14 @@
 SELECT data FROM sessions WHERE token = $1 AND expires > sqlc.arg(now);
 -- name: CommitSession :exec
 INSERT INTO sessions (
-  token, data, expires
+  token, data, expires, user_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
-ON CONFLICT(token) DO UPDATE SET token = $1, data = $2, expires = $3;
+ON CONFLICT(token) DO UPDATE SET token = $1, data = $2, expires = $3, user_id = $4;
 -- name: FindSessions :many
 SELECT token,data FROM sessions WHERE expires > sqlc.arg(now);
+-- name: FindUserSessions :many
+SELECT token,data,expires FROM sessions WHERE user_id = $1 AND expires > sqlc.arg(now);
 -- name: DeleteSession :exec
 DELETE FROM sessions WHERE token = $1;
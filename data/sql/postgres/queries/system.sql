@@ -1,8 +1,14 @@
 -- name: GetJWTKeys :one
 SELECT * FROM rsa_keys WHERE name = 'jwt_secret';
--- name: InsertJWTKeys :exec
-INSERT INTO rsa_keys (
-  name,created_at,private,public
+-- name: FindJWTKeys :many
+SELECT * FROM jwt_keys WHERE retired_at = 0 OR retired_at > $1 ORDER BY active_at;
+-- name: CreateJWTKey :one
+INSERT INTO jwt_keys (
+  id,created_at,algorithm,private,public,active_at,retired_at
 ) VALUES (
-  'jwt_secret',$1,$2,$3
-);
+  $1,$2,$3,$4,$5,$6,0
+) RETURNING *;
+-- name: RetireJWTKeys :exec
+UPDATE jwt_keys SET retired_at = $1 WHERE algorithm = $2 AND id != $3 AND retired_at = 0;
+-- name: DeleteRetiredJWTKeys :exec
+DELETE FROM jwt_keys WHERE retired_at != 0 AND retired_at < $1;
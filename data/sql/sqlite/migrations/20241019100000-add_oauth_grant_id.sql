@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE oauth ADD COLUMN grant_id TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE oauth DROP COLUMN grant_id;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE jwt_keys (
+	id TEXT PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	algorithm TEXT NOT NULL,
+	private BLOB NOT NULL,
+	public BLOB NOT NULL,
+	active_at INTEGER NOT NULL,
+	retired_at INTEGER NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE jwt_keys;
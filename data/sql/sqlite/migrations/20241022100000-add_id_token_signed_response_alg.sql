@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN id_token_signed_response_alg TEXT NOT NULL DEFAULT 'RS256';
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN id_token_signed_response_alg;
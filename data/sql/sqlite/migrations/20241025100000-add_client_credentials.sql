@@ -0,0 +1,49 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN client_credentials_scopes TEXT NOT NULL DEFAULT '';
+
+-- tokens issued with the client credentials grant have no user
+CREATE TABLE oauth_new (
+	created_at INTEGER NOT NULL,
+	client_id TEXT NOT NULL,
+	category TEXT NOT NULL,
+	token_hash BLOB NOT NULL,
+	redirect_uri TEXT NOT NULL,
+	user_id TEXT,
+	scopes TEXT NOT NULL,
+	data BLOB,
+	expires INTEGER NOT NULL,
+	used BOOLEAN NOT NULL,
+	grant_id TEXT NOT NULL DEFAULT '',
+
+	PRIMARY KEY (client_id, category, token_hash),
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+INSERT INTO oauth_new SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id FROM oauth;
+DROP TABLE oauth;
+ALTER TABLE oauth_new RENAME TO oauth;
+
+-- +migrate Down
+DELETE FROM oauth WHERE user_id IS NULL;
+CREATE TABLE oauth_old (
+	created_at INTEGER NOT NULL,
+	client_id TEXT NOT NULL,
+	category TEXT NOT NULL,
+	token_hash BLOB NOT NULL,
+	redirect_uri TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	scopes TEXT NOT NULL,
+	data BLOB,
+	expires INTEGER NOT NULL,
+	used BOOLEAN NOT NULL,
+	grant_id TEXT NOT NULL DEFAULT '',
+
+	PRIMARY KEY (client_id, category, token_hash),
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+INSERT INTO oauth_old SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id FROM oauth;
+DROP TABLE oauth;
+ALTER TABLE oauth_old RENAME TO oauth;
+
+ALTER TABLE clients DROP COLUMN client_credentials_scopes;
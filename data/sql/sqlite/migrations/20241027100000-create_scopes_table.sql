@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE scopes (
+	id TEXT NOT NULL PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	client_id TEXT NOT NULL,
+	name TEXT NOT NULL UNIQUE,
+	audience TEXT NOT NULL,
+	descriptions BLOB NOT NULL,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE scopes;
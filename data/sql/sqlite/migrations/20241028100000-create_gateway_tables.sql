@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE gateway_groups (
+	id TEXT NOT NULL PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE gateway_group_members (
+	group_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (group_id, user_id),
+	FOREIGN KEY (group_id) REFERENCES gateway_groups (id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE gateway_domains (
+	id TEXT NOT NULL PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	domain TEXT NOT NULL UNIQUE,
+	group_ids TEXT NOT NULL,
+	user_ids TEXT NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE gateway_domains;
+DROP TABLE gateway_group_members;
+DROP TABLE gateway_groups;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE gateway_domains ADD COLUMN rules BLOB NOT NULL DEFAULT '[]';
+
+-- +migrate Down
+ALTER TABLE gateway_domains DROP COLUMN rules;
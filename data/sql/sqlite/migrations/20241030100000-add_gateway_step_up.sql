@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE gateway_domains ADD COLUMN max_auth_age INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE gateway_domains ADD COLUMN require_2fa BOOLEAN NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE gateway_domains DROP COLUMN require_2fa;
+ALTER TABLE gateway_domains DROP COLUMN max_auth_age;
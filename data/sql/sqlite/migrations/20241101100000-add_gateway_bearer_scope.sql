@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE gateway_domains ADD COLUMN bearer_scope TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE gateway_domains DROP COLUMN bearer_scope;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE app_passwords (
+	id TEXT NOT NULL PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	user_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	scopes TEXT NOT NULL,
+	password_hash BLOB NOT NULL UNIQUE,
+	last_used INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+ALTER TABLE clients ADD COLUMN password_grant BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE clients DROP COLUMN password_grant;
+DROP TABLE app_passwords;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE sessions ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- +migrate Down
+DROP INDEX sessions_user_id_idx;
+ALTER TABLE sessions DROP COLUMN user_id;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE backchannel_logouts (
+	id TEXT NOT NULL PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	client_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	sid TEXT NOT NULL,
+	attempt INTEGER NOT NULL,
+	next_attempt INTEGER NOT NULL,
+	FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+CREATE INDEX backchannel_logouts_next_attempt_idx ON backchannel_logouts (next_attempt);
+
+-- +migrate Down
+DROP TABLE backchannel_logouts;
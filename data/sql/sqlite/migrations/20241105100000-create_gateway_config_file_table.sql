@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE gateway_config_file (
+	id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1),
+	applied_at INTEGER NOT NULL,
+	hash BLOB NOT NULL,
+	data BLOB NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE gateway_config_file;
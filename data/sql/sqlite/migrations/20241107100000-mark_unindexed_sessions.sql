@@ -0,0 +1,6 @@
+-- +migrate Up
+-- sessions stored before the user index existed are indexed on the next start
+UPDATE sessions SET user_id = 'unindexed' WHERE user_id = '';
+
+-- +migrate Down
+UPDATE sessions SET user_id = '' WHERE user_id = 'unindexed';
@@ -0,0 +1,16 @@
+-- name: CreateAppPassword :one
+INSERT INTO app_passwords (
+  id, created_at, user_id, name, scopes, password_hash
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+) RETURNING *;
+-- name: FindAppPasswords :many
+SELECT * FROM app_passwords WHERE user_id = ? ORDER BY created_at;
+-- name: FindAppPassword :one
+SELECT * FROM app_passwords WHERE user_id = ? AND id = ?;
+-- name: FindAppPasswordByHash :one
+SELECT * FROM app_passwords WHERE user_id = ? AND password_hash = ?;
+-- name: UpdateAppPasswordLastUsed :execresult
+UPDATE app_passwords SET last_used = ? WHERE user_id = ? AND id = ?;
+-- name: DeleteAppPassword :execresult
+DELETE FROM app_passwords WHERE user_id = ? AND id = ?;
@@ -0,0 +1,12 @@
+-- name: CreateBackchannelLogout :exec
+INSERT INTO backchannel_logouts (
+  id, created_at, client_id, user_id, sid, attempt, next_attempt
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+);
+-- name: FindDueBackchannelLogouts :many
+SELECT * FROM backchannel_logouts WHERE next_attempt <= ? ORDER BY next_attempt;
+-- name: UpdateBackchannelLogoutAttempt :execresult
+UPDATE backchannel_logouts SET attempt = ?, next_attempt = ? WHERE id = ?;
+-- name: DeleteBackchannelLogout :execresult
+DELETE FROM backchannel_logouts WHERE id = ?;
@@ -6,13 +6,13 @@ SELECT * FROM clients WHERE user_id = ? AND id = ?;
 SELECT * FROM clients WHERE user_id = ?;
 -- name: CreateClient :one
 INSERT INTO clients (
-  id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?  
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) RETURNING *;
 -- name: UpdateClient :one
 UPDATE clients SET
-  name = ?, description = ?, website = ?, redirect_uris = ?
+  name = ?, description = ?, website = ?, redirect_uris = ?, access_token_format = ?, id_token_signed_response_alg = ?, post_logout_redirect_uris = ?, backchannel_logout_uri = ?, client_credentials_scopes = ?, token_exchange = ?, password_grant = ?
 WHERE user_id = ? AND id = ?
 RETURNING *;
 -- name: UpdateClientSecret :execresult
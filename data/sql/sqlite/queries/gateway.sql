@@ -0,0 +1,40 @@
+-- name: FindGatewayGroups :many
+SELECT * FROM gateway_groups ORDER BY name;
+-- name: FindGatewayGroup :one
+SELECT * FROM gateway_groups WHERE id = ?;
+-- name: CreateGatewayGroup :one
+INSERT INTO gateway_groups (
+  id, created_at, name
+) VALUES (
+  ?, ?, ?
+) RETURNING *;
+-- name: UpdateGatewayGroup :one
+UPDATE gateway_groups SET name = ? WHERE id = ? RETURNING *;
+-- name: DeleteGatewayGroup :execresult
+DELETE FROM gateway_groups WHERE id = ?;
+-- name: FindGatewayGroupMembers :many
+SELECT * FROM gateway_group_members WHERE group_id = ? ORDER BY created_at;
+-- name: FindAllGatewayGroupMembers :many
+SELECT * FROM gateway_group_members;
+-- name: AddGatewayGroupMember :execresult
+INSERT INTO gateway_group_members (
+  group_id, user_id, created_at
+) VALUES (
+  ?, ?, ?
+);
+-- name: RemoveGatewayGroupMember :execresult
+DELETE FROM gateway_group_members WHERE group_id = ? AND user_id = ?;
+-- name: FindGatewayDomains :many
+SELECT * FROM gateway_domains ORDER BY domain;
+-- name: FindGatewayDomain :one
+SELECT * FROM gateway_domains WHERE id = ?;
+-- name: CreateGatewayDomain :one
+INSERT INTO gateway_domains (
+  id, created_at, domain, group_ids, user_ids, rules, max_auth_age, require_2fa, headers, bearer_scope
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING *;
+-- name: UpdateGatewayDomain :one
+UPDATE gateway_domains SET domain = ?, group_ids = ?, user_ids = ?, rules = ?, max_auth_age = ?, require_2fa = ?, headers = ?, bearer_scope = ? WHERE id = ? RETURNING *;
+-- name: DeleteGatewayDomain :execresult
+DELETE FROM gateway_domains WHERE id = ?;
\ No newline at end of file
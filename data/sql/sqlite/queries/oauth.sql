@@ -1,15 +1,19 @@
 -- name: CreateOAuthToken :one
 INSERT INTO oauth (
-  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used
+  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used, grant_id
 ) VALUES (
-  ?,?,?,?,?,?,?,?,?,?
+  ?,?,?,?,?,?,?,?,?,?,?
 ) RETURNING *;
 -- name: FindOAuthToken :one
 SELECT * FROM oauth WHERE category = ? AND token_hash = ? AND expires > sqlc.arg(now);
+-- name: UpdateOAuthToken :execresult
+UPDATE oauth SET user_id = ?, data = ? WHERE client_id = ? AND category = ? AND token_hash = ?;
 -- name: UseOAuthToken :execresult
-UPDATE oauth SET used = TRUE WHERE client_id = ? AND category = ? AND token_hash = ?;
+UPDATE oauth SET used = TRUE WHERE client_id = ? AND category = ? AND token_hash = ? AND used = FALSE;
 -- name: DeleteOAuthToken :execresult
 DELETE FROM oauth WHERE (client_id = ? AND category = ? AND token_hash = ?) OR expires < sqlc.arg(now);
+-- name: DeleteOAuthTokensByGrant :exec
+DELETE FROM oauth WHERE (client_id = ? AND grant_id = ?) OR expires < sqlc.arg(now);
 -- name: DeleteOAuthTokenByUser :exec
 DELETE FROM oauth WHERE (client_id = ? AND user_id = ?) OR expires < sqlc.arg(now);
 -- name: SetOAuthPermissions :one
@@ -19,5 +23,7 @@ REPLACE INTO permissions (
 RETURNING *;
 -- name: FindOAuthPermissions :one
 SELECT * FROM permissions WHERE client_id = ? AND user_id = ?;
+-- name: FindOAuthPermissionsByUser :many
+SELECT * FROM permissions WHERE user_id = ?;
 -- name: RevokeOAuthPermissions :execresult
 DELETE FROM permissions WHERE client_id = ? AND user_id = ?;
@@ -0,0 +1,18 @@
+-- name: FindScope :one
+SELECT * FROM scopes WHERE client_id = ? AND id = ?;
+-- name: FindScopeByName :one
+SELECT * FROM scopes WHERE name = ?;
+-- name: FindScopesByAudience :many
+SELECT * FROM scopes WHERE audience = ?;
+-- name: FindScopesByClient :many
+SELECT * FROM scopes WHERE client_id = ? ORDER BY name;
+-- name: CreateScope :one
+INSERT INTO scopes (
+  id, created_at, client_id, name, audience, descriptions
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+) RETURNING *;
+-- name: UpdateScope :one
+UPDATE scopes SET audience = ?, descriptions = ? WHERE client_id = ? AND id = ? RETURNING *;
+-- name: DeleteScope :execresult
+DELETE FROM scopes WHERE client_id = ? AND id = ?;
\ No newline at end of file
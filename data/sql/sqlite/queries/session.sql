@@ -2,11 +2,13 @@
 SELECT data FROM sessions WHERE token = ? AND expires > sqlc.arg(now);
 -- name: CommitSession :exec
 REPLACE INTO sessions (
-  token, data, expires
+  token, data, expires, user_id
 ) VALUES (
-  ?, ?, ?
+  ?, ?, ?, ?
 );
 -- name: FindSessions :many
 SELECT token,data FROM sessions WHERE expires > sqlc.arg(now);
+-- name: FindUserSessions :many
+SELECT token,data,expires FROM sessions WHERE user_id = ? AND expires > sqlc.arg(now);
 -- name: DeleteSession :exec
 DELETE FROM sessions WHERE token = ?;
@@ -1,8 +1,14 @@
 -- name: GetJWTKeys :one
 SELECT * FROM rsa_keys WHERE name = 'jwt_secret';
--- name: InsertJWTKeys :exec
-INSERT INTO rsa_keys (
-  name,created_at,private,public
+-- name: FindJWTKeys :many
+SELECT * FROM jwt_keys WHERE retired_at = 0 OR retired_at > ? ORDER BY active_at;
+-- name: CreateJWTKey :one
+INSERT INTO jwt_keys (
+  id,created_at,algorithm,private,public,active_at,retired_at
 ) VALUES (
-  'jwt_secret',?,?,?
-);
+  ?,?,?,?,?,?,0
+) RETURNING *;
+-- name: RetireJWTKeys :exec
+UPDATE jwt_keys SET retired_at = ? WHERE algorithm = ? AND id != ? AND retired_at = 0;
+-- name: DeleteRetiredJWTKeys :exec
+DELETE FROM jwt_keys WHERE retired_at != 0 AND retired_at < ?;
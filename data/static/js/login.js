@@ -16,6 +16,8 @@ function decode(base64urlString) {
 }
 
 const passkeyBtn = document.getElementById("use-passkey-btn");
+// the step-up page verifies the passkey of the logged in user at a different endpoint
+const passkeyEndpoint = passkeyBtn.dataset.endpoint || "/user/passkey/verify";
 const errorList = document.getElementById("login-error-list");
 const invalidCredentialsError = document.getElementById("invalid-credentials");
 passkeyBtn.addEventListener("click", async (e) => {
@@ -26,7 +28,7 @@ passkeyBtn.addEventListener("click", async (e) => {
     errorList.classList.add("invisible");
     invalidCredentialsError.classList.add("invisible");
     console.log("passkey begin")
-    const res = await fetch("/user/passkey/verify/begin", { method: "POST" });
+    const res = await fetch(passkeyEndpoint + "/begin", { method: "POST" });
     if (res.status !== 200) {
       alert("ERROR: status: " + res.status);
       return;
@@ -37,7 +39,7 @@ passkeyBtn.addEventListener("click", async (e) => {
       publicKey: authOptions.publicKey
     });
     console.log("passkey finish")
-    const res2 = await fetch("/user/passkey/verify/finish", {
+    const res2 = await fetch(passkeyEndpoint + "/finish", {
       method: "POST",
       body: JSON.stringify({
         id: credential.id,
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/juho05/h-id/repos"
@@ -22,6 +24,22 @@ func (h *Handler) adminRoutes(r chi.Router) {
 	r.Post("/user/{userID}/delete", h.adminDeleteUser)
 	r.Get("/user/invite", h.newPage("adminInvite"))
 	r.Post("/user/invite", h.adminInvite)
+
+	r.Get("/groups", h.adminListGroups)
+	r.Get("/groups/create", h.newPage("createGroup"))
+	r.Post("/groups/create", h.adminCreateGroup)
+	r.Get("/groups/{groupID}", h.adminViewGroup)
+	r.Post("/groups/{groupID}/update", h.adminUpdateGroup)
+	r.Post("/groups/{groupID}/delete", h.adminDeleteGroup)
+	r.Post("/groups/{groupID}/members/add", h.adminAddGroupMember)
+	r.Post("/groups/{groupID}/members/{userID}/remove", h.adminRemoveGroupMember)
+
+	r.Get("/domains", h.adminListDomains)
+	r.Get("/domains/create", h.adminCreateDomainPage)
+	r.Post("/domains/create", h.adminCreateDomain)
+	r.Get("/domains/{domainID}", h.adminViewDomain)
+	r.Post("/domains/{domainID}/update", h.adminUpdateDomain)
+	r.Post("/domains/{domainID}/delete", h.adminDeleteDomain)
 }
 
 // GET /admin/user
@@ -166,3 +184,674 @@ func (h *Handler) adminInvite(w http.ResponseWriter, r *http.Request) {
 	}
 	h.Renderer.render(w, r, http.StatusOK, "adminInvite", tmplData)
 }
+
+// GET /admin/groups
+func (h *Handler) adminListGroups(w http.ResponseWriter, r *http.Request) {
+	repoGroups, err := h.AuthGatewayService.FindGroups(r.Context())
+	if err != nil {
+		serverError(w, fmt.Errorf("admin list groups: %w", err))
+		return
+	}
+	type group struct {
+		ID   string
+		Name string
+	}
+	groups := make([]group, len(repoGroups))
+	for i, g := range repoGroups {
+		groups[i] = group{
+			ID:   g.ID.String(),
+			Name: g.Name,
+		}
+	}
+	type data struct {
+		Groups []group
+	}
+	h.Renderer.render(w, r, http.StatusOK, "listGroups", h.newTemplateDataWithData(r, data{
+		Groups: groups,
+	}))
+}
+
+// POST /admin/groups/create
+func (h *Handler) adminCreateGroup(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Name string `form:"name" validate:"required,notblank,max=64"`
+	}
+	tmplData := h.newTemplateData(r)
+	body, ok := decodeAndValidateBody[request](h, w, r, "createGroup", &tmplData)
+	if !ok {
+		return
+	}
+
+	group, err := h.AuthGatewayService.CreateGroup(r.Context(), body.Name)
+	if err != nil {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		tmplData.Form = body
+		if errors.Is(err, services.ErrInvalidGroupName) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "invalidGroupName")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createGroup", tmplData)
+		} else if errors.Is(err, repos.ErrExists) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "groupExists")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createGroup", tmplData)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/admin/groups/"+group.ID.String(), http.StatusSeeOther)
+}
+
+type adminGroupMember struct {
+	ID    string
+	Name  string
+	Email string
+}
+
+type adminGroupData struct {
+	ID      string
+	Name    string
+	Members []adminGroupMember
+}
+
+func (h *Handler) adminGroupData(r *http.Request, group *repos.GatewayGroupModel) (adminGroupData, error) {
+	users, err := h.AuthGatewayService.FindGroupMembers(r.Context(), group.ID)
+	if err != nil {
+		return adminGroupData{}, err
+	}
+	members := make([]adminGroupMember, len(users))
+	for i, u := range users {
+		members[i] = adminGroupMember{
+			ID:    u.ID.String(),
+			Name:  u.Name,
+			Email: u.Email,
+		}
+	}
+	return adminGroupData{
+		ID:      group.ID.String(),
+		Name:    group.Name,
+		Members: members,
+	}, nil
+}
+
+// findGroup loads the group referenced by the groupID URL parameter and writes an error response on failure.
+func (h *Handler) findGroup(w http.ResponseWriter, r *http.Request) (*repos.GatewayGroupModel, bool) {
+	groupID, err := ulid.Parse(chi.URLParam(r, "groupID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return nil, false
+	}
+	group, err := h.AuthGatewayService.FindGroup(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return nil, false
+	}
+	return group, true
+}
+
+// GET /admin/groups/{groupID}
+func (h *Handler) adminViewGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+	data, err := h.adminGroupData(r, group)
+	if err != nil {
+		serverError(w, fmt.Errorf("admin view group: %w", err))
+		return
+	}
+	tmplData := h.newTemplateDataWithData(r, data)
+	tmplData.Form = struct {
+		Name string
+	}{
+		Name: group.Name,
+	}
+	h.Renderer.render(w, r, http.StatusOK, "group", tmplData)
+}
+
+// POST /admin/groups/{groupID}/update
+func (h *Handler) adminUpdateGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+	data, err := h.adminGroupData(r, group)
+	if err != nil {
+		serverError(w, fmt.Errorf("admin update group: %w", err))
+		return
+	}
+
+	type request struct {
+		Name string `form:"name" validate:"required,notblank,max=64"`
+	}
+	tmplData := h.newTemplateDataWithData(r, data)
+	body, ok := decodeAndValidateBody[request](h, w, r, "group", &tmplData)
+	if !ok {
+		return
+	}
+
+	_, err = h.AuthGatewayService.UpdateGroup(r.Context(), group.ID, body.Name)
+	if err != nil {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		tmplData.Form = body
+		if errors.Is(err, services.ErrInvalidGroupName) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "invalidGroupName")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "group", tmplData)
+		} else if errors.Is(err, repos.ErrExists) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "groupExists")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "group", tmplData)
+		} else if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/admin/groups/"+group.ID.String(), http.StatusSeeOther)
+}
+
+// POST /admin/groups/{groupID}/delete
+func (h *Handler) adminDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	ok = h.verifyConfirmation(w, r, group.Name, false)
+	if !ok {
+		return
+	}
+
+	err := h.AuthGatewayService.DeleteGroup(r.Context(), group.ID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/admin/groups", http.StatusSeeOther)
+}
+
+// POST /admin/groups/{groupID}/members/add
+func (h *Handler) adminAddGroupMember(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+	data, err := h.adminGroupData(r, group)
+	if err != nil {
+		serverError(w, fmt.Errorf("admin add group member: %w", err))
+		return
+	}
+
+	type request struct {
+		Email string `form:"email" validate:"required,email"`
+	}
+	body, err := decodeBody[request](r)
+	if err != nil {
+		badRequest(w)
+		return
+	}
+	// The page also contains the form to rename the group, so the form data of this request cannot be used.
+	lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+	tmplData := h.newTemplateDataWithData(r, data)
+	tmplData.Form = struct {
+		Name string
+	}{
+		Name: group.Name,
+	}
+	if invalid := findInvalidFields(lang, body); len(invalid) > 0 {
+		tmplData.FieldErrors = invalid
+		h.Renderer.render(w, r, http.StatusUnprocessableEntity, "group", tmplData)
+		return
+	}
+
+	user, err := h.UserService.FindByEmail(r.Context(), body.Email)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			tmplData.FieldErrors["Email"] = services.MustTranslate(lang, "unknownUser")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "group", tmplData)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	err = h.AuthGatewayService.AddGroupMember(r.Context(), group.ID, user.ID)
+	if err != nil && !errors.Is(err, repos.ErrExists) {
+		serverError(w, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/groups/"+group.ID.String(), http.StatusSeeOther)
+}
+
+// POST /admin/groups/{groupID}/members/{userID}/remove
+func (h *Handler) adminRemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+	userID, err := ulid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	err = h.AuthGatewayService.RemoveGroupMember(r.Context(), group.ID, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/admin/groups/"+group.ID.String(), http.StatusSeeOther)
+}
+
+// GET /admin/domains
+func (h *Handler) adminListDomains(w http.ResponseWriter, r *http.Request) {
+	repoDomains, err := h.AuthGatewayService.FindDomains(r.Context())
+	if err != nil {
+		serverError(w, fmt.Errorf("admin list domains: %w", err))
+		return
+	}
+	type domain struct {
+		ID     string
+		Domain string
+	}
+	domains := make([]domain, len(repoDomains))
+	for i, d := range repoDomains {
+		domains[i] = domain{
+			ID:     d.ID.String(),
+			Domain: d.Domain,
+		}
+	}
+	type data struct {
+		Domains []domain
+	}
+	h.Renderer.render(w, r, http.StatusOK, "listDomains", h.newTemplateDataWithData(r, data{
+		Domains: domains,
+	}))
+}
+
+type adminDomainRequest struct {
+	Domain string `form:"domain" validate:"required,notblank,max=253"`
+	Groups string `form:"groups" validate:"max=1024"`
+	Users  string `form:"users" validate:"max=4096"`
+	Rules  string `form:"rules" validate:"max=8192"`
+	// MaxAuthAge is in minutes.
+	MaxAuthAge  int    `form:"maxAuthAge" validate:"min=0,max=525600"`
+	Require2FA  bool   `form:"require2FA"`
+	Headers     string `form:"headers" validate:"max=256"`
+	BearerScope string `form:"bearerScope" validate:"max=128"`
+}
+
+// resolveDomainAccess converts the space separated group names and user email addresses of the domain form to IDs
+// and parses the rules. Unknown groups and users and invalid rules are reported in fieldErrors.
+func (h *Handler) resolveDomainAccess(r *http.Request, body adminDomainRequest) (groups, users []ulid.ULID, rules []repos.GatewayRuleModel, fieldErrors map[string]string, err error) {
+	lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+	fieldErrors = make(map[string]string)
+
+	repoGroups, err := h.AuthGatewayService.FindGroups(r.Context())
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	groupIDs := make(map[string]ulid.ULID, len(repoGroups))
+	for _, g := range repoGroups {
+		groupIDs[g.Name] = g.ID
+	}
+	for _, name := range strings.Fields(body.Groups) {
+		id, ok := groupIDs[name]
+		if !ok {
+			fieldErrors["Groups"] = services.MustTranslate(lang, "unknownGroups")
+			break
+		}
+		if !slices.Contains(groups, id) {
+			groups = append(groups, id)
+		}
+	}
+
+	for _, email := range strings.Fields(body.Users) {
+		user, err := h.UserService.FindByEmail(r.Context(), email)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				fieldErrors["Users"] = services.MustTranslate(lang, "unknownUsers")
+				break
+			}
+			return nil, nil, nil, nil, err
+		}
+		if !slices.Contains(users, user.ID) {
+			users = append(users, user.ID)
+		}
+	}
+
+	rules, invalidLine, err := h.parseGatewayRules(r, body.Rules, groupIDs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if invalidLine != "" {
+		fieldErrors["Rules"] = services.MustTranslate(lang, "invalidGatewayRule") + " " + invalidLine
+	}
+	return groups, users, rules, fieldErrors, nil
+}
+
+// parseGatewayRules parses the rules of the domain form. Every line contains a rule in the format
+// `policy methods path [group:name]... [user:email]...`, e.g. `allow GET,POST /admin/* group:admins`.
+// Methods can be * to match all methods. Users and groups that no longer exist are referenced by their ID.
+// If a line cannot be parsed or contains an invalid rule, it is returned as invalidLine.
+func (h *Handler) parseGatewayRules(r *http.Request, text string, groupIDs map[string]ulid.ULID) (rules []repos.GatewayRuleModel, invalidLine string, err error) {
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, line, nil
+		}
+		rule := repos.GatewayRuleModel{
+			Policy: repos.GatewayPolicy(strings.ToLower(fields[0])),
+			Path:   fields[2],
+		}
+		if fields[1] != "*" {
+			rule.Methods = strings.Split(strings.ToUpper(fields[1]), ",")
+		}
+		for _, subject := range fields[3:] {
+			if name, ok := strings.CutPrefix(subject, "group:"); ok {
+				id, ok := groupIDs[name]
+				if !ok {
+					id, err = ulid.Parse(name)
+					if err != nil {
+						return nil, line, nil
+					}
+				}
+				rule.Groups = append(rule.Groups, id)
+			} else if email, ok := strings.CutPrefix(subject, "user:"); ok {
+				id, err := ulid.Parse(email)
+				if err != nil {
+					user, err := h.UserService.FindByEmail(r.Context(), email)
+					if err != nil {
+						if errors.Is(err, repos.ErrNoRecord) {
+							return nil, line, nil
+						}
+						return nil, "", err
+					}
+					id = user.ID
+				}
+				rule.Users = append(rule.Users, id)
+			} else {
+				return nil, line, nil
+			}
+		}
+		if services.ValidateGatewayRule(rule) != nil {
+			return nil, line, nil
+		}
+		rules = append(rules, rule)
+	}
+	return rules, "", nil
+}
+
+// formatGatewayRules is the inverse of parseGatewayRules.
+func (h *Handler) formatGatewayRules(r *http.Request, rules []repos.GatewayRuleModel, groupNames map[ulid.ULID]string) (string, error) {
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		methods := "*"
+		if len(rule.Methods) > 0 {
+			methods = strings.Join(rule.Methods, ",")
+		}
+		fields := []string{string(rule.Policy), methods, rule.Path}
+		for _, id := range rule.Groups {
+			name, ok := groupNames[id]
+			if !ok {
+				name = id.String()
+			}
+			fields = append(fields, "group:"+name)
+		}
+		for _, id := range rule.Users {
+			user, err := h.UserService.Find(r.Context(), id)
+			if err != nil {
+				if errors.Is(err, repos.ErrNoRecord) {
+					fields = append(fields, "user:"+id.String())
+					continue
+				}
+				return "", err
+			}
+			fields = append(fields, "user:"+user.Email)
+		}
+		lines[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// parseGatewayHeaders parses the space separated header names of the domain form case-insensitively.
+func parseGatewayHeaders(str string) ([]repos.GatewayHeader, bool) {
+	fields := strings.Fields(str)
+	headers := make([]repos.GatewayHeader, 0, len(fields))
+	for _, f := range fields {
+		i := slices.IndexFunc(services.GatewayHeaders, func(h repos.GatewayHeader) bool {
+			return strings.EqualFold(string(h), f)
+		})
+		if i < 0 {
+			return nil, false
+		}
+		if !slices.Contains(headers, services.GatewayHeaders[i]) {
+			headers = append(headers, services.GatewayHeaders[i])
+		}
+	}
+	return headers, true
+}
+
+// formatGatewayHeaders is the inverse of parseGatewayHeaders.
+func formatGatewayHeaders(headers []repos.GatewayHeader) string {
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		names[i] = string(h)
+	}
+	return strings.Join(names, " ")
+}
+
+// saveDomain creates the domain if id is nil and updates it otherwise.
+// It renders page with the appropriate field errors if the form is invalid.
+func (h *Handler) saveDomain(w http.ResponseWriter, r *http.Request, id *ulid.ULID, page string, tmplData templateData) (*repos.GatewayDomainModel, bool) {
+	body, ok := decodeAndValidateBody[adminDomainRequest](h, w, r, page, &tmplData)
+	if !ok {
+		return nil, false
+	}
+	tmplData.Form = body
+
+	groups, users, rules, fieldErrors, err := h.resolveDomainAccess(r, body)
+	if err != nil {
+		serverError(w, err)
+		return nil, false
+	}
+	if len(fieldErrors) > 0 {
+		tmplData.FieldErrors = fieldErrors
+		h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		return nil, false
+	}
+
+	domain := strings.ToLower(body.Domain)
+	headers, ok := parseGatewayHeaders(body.Headers)
+	if !ok {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		tmplData.FieldErrors["Headers"] = services.MustTranslate(lang, "invalidGatewayHeader")
+		h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		return nil, false
+	}
+
+	maxAuthAge := time.Duration(body.MaxAuthAge) * time.Minute
+	var d *repos.GatewayDomainModel
+	if id == nil {
+		d, err = h.AuthGatewayService.CreateDomain(r.Context(), domain, groups, users, rules, maxAuthAge, body.Require2FA, headers, body.BearerScope)
+	} else {
+		d, err = h.AuthGatewayService.UpdateDomain(r.Context(), *id, domain, groups, users, rules, maxAuthAge, body.Require2FA, headers, body.BearerScope)
+	}
+	if err != nil {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		if errors.Is(err, services.ErrInvalidDomain) {
+			tmplData.FieldErrors["Domain"] = services.MustTranslate(lang, "invalidDomain")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, services.ErrInvalidGatewayRule) {
+			tmplData.FieldErrors["Rules"] = services.MustTranslate(lang, "invalidGatewayRule")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, services.ErrInvalidScope) {
+			tmplData.FieldErrors["BearerScope"] = services.MustTranslate(lang, "invalidBearerScope")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, services.ErrInvalidGatewayHeader) {
+			tmplData.FieldErrors["Headers"] = services.MustTranslate(lang, "invalidGatewayHeader")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, services.ErrInvalidMaxAuthAge) {
+			tmplData.FieldErrors["MaxAuthAge"] = services.MustTranslate(lang, "invalidMaxAuthAge")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, repos.ErrExists) {
+			tmplData.FieldErrors["Domain"] = services.MustTranslate(lang, "domainExists")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, page, tmplData)
+		} else if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return nil, false
+	}
+	return d, true
+}
+
+// GET /admin/domains/create
+func (h *Handler) adminCreateDomainPage(w http.ResponseWriter, r *http.Request) {
+	tmplData := h.newTemplateData(r)
+	tmplData.Form = adminDomainRequest{
+		Headers: formatGatewayHeaders(repos.DefaultGatewayHeaders),
+	}
+	h.Renderer.render(w, r, http.StatusOK, "createDomain", tmplData)
+}
+
+// POST /admin/domains/create
+func (h *Handler) adminCreateDomain(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.saveDomain(w, r, nil, "createDomain", h.newTemplateData(r))
+	if !ok {
+		return
+	}
+	http.Redirect(w, r, "/admin/domains/"+d.ID.String(), http.StatusSeeOther)
+}
+
+// findDomain loads the domain referenced by the domainID URL parameter and writes an error response on failure.
+func (h *Handler) findDomain(w http.ResponseWriter, r *http.Request) (*repos.GatewayDomainModel, bool) {
+	domainID, err := ulid.Parse(chi.URLParam(r, "domainID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return nil, false
+	}
+	domain, err := h.AuthGatewayService.FindDomain(r.Context(), domainID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return nil, false
+	}
+	return domain, true
+}
+
+// GET /admin/domains/{domainID}
+func (h *Handler) adminViewDomain(w http.ResponseWriter, r *http.Request) {
+	domain, ok := h.findDomain(w, r)
+	if !ok {
+		return
+	}
+
+	repoGroups, err := h.AuthGatewayService.FindGroups(r.Context())
+	if err != nil {
+		serverError(w, fmt.Errorf("admin view domain: %w", err))
+		return
+	}
+	groupNames := make(map[ulid.ULID]string, len(repoGroups))
+	for _, g := range repoGroups {
+		groupNames[g.ID] = g.Name
+	}
+	groups := make([]string, 0, len(domain.Groups))
+	for _, id := range domain.Groups {
+		if name, ok := groupNames[id]; ok {
+			groups = append(groups, name)
+		}
+	}
+	users := make([]string, 0, len(domain.Users))
+	for _, id := range domain.Users {
+		user, err := h.UserService.Find(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			serverError(w, fmt.Errorf("admin view domain: %w", err))
+			return
+		}
+		users = append(users, user.Email)
+	}
+	rules, err := h.formatGatewayRules(r, domain.Rules, groupNames)
+	if err != nil {
+		serverError(w, fmt.Errorf("admin view domain: %w", err))
+		return
+	}
+
+	tmplData := h.newTemplateDataWithData(r, struct {
+		ID     string
+		Domain string
+	}{ID: domain.ID.String(), Domain: domain.Domain})
+	tmplData.Form = adminDomainRequest{
+		Domain:      domain.Domain,
+		Groups:      strings.Join(groups, " "),
+		Users:       strings.Join(users, " "),
+		Rules:       rules,
+		MaxAuthAge:  int(domain.MaxAuthAge / time.Minute),
+		Require2FA:  domain.Require2FA,
+		Headers:     formatGatewayHeaders(domain.Headers),
+		BearerScope: domain.BearerScope,
+	}
+	h.Renderer.render(w, r, http.StatusOK, "domain", tmplData)
+}
+
+// POST /admin/domains/{domainID}/update
+func (h *Handler) adminUpdateDomain(w http.ResponseWriter, r *http.Request) {
+	domain, ok := h.findDomain(w, r)
+	if !ok {
+		return
+	}
+	tmplData := h.newTemplateDataWithData(r, struct {
+		ID     string
+		Domain string
+	}{ID: domain.ID.String(), Domain: domain.Domain})
+	_, ok = h.saveDomain(w, r, &domain.ID, "domain", tmplData)
+	if !ok {
+		return
+	}
+	http.Redirect(w, r, "/admin/domains/"+domain.ID.String(), http.StatusSeeOther)
+}
+
+// POST /admin/domains/{domainID}/delete
+func (h *Handler) adminDeleteDomain(w http.ResponseWriter, r *http.Request) {
+	domain, ok := h.findDomain(w, r)
+	if !ok {
+		return
+	}
+
+	ok = h.verifyConfirmation(w, r, domain.Domain, false)
+	if !ok {
+		return
+	}
+
+	err := h.AuthGatewayService.DeleteDomain(r.Context(), domain.ID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/admin/domains", http.StatusSeeOther)
+}
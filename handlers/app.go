@@ -4,11 +4,14 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/oklog/ulid/v2"
 
 	"github.com/juho05/h-id/repos"
+	"github.com/juho05/h-id/services"
 )
 
 func (h *Handler) appRoutes(r chi.Router) {
@@ -18,15 +21,29 @@ func (h *Handler) appRoutes(r chi.Router) {
 	r.Get("/{id}", h.appGet)
 	r.Post("/{id}/update", h.appUpdate)
 	r.Post("/{id}/delete", h.appDelete)
+	r.Get("/{id}/scopes", h.appScopes)
+	r.Get("/{id}/scopes/create", h.appScopeCreatePage)
+	r.Post("/{id}/scopes/create", h.appScopeCreate)
+	r.Get("/{id}/scopes/{scopeID}", h.appScopeGet)
+	r.Post("/{id}/scopes/{scopeID}/update", h.appScopeUpdate)
+	r.Post("/{id}/scopes/{scopeID}/delete", h.appScopeDelete)
 }
 
 // POST /app/create
 func (h *Handler) appCreate(w http.ResponseWriter, r *http.Request) {
 	type request struct {
-		Name         string   `form:"name" validate:"required,notblank,min=3,max=32"`
-		Description  string   `form:"description" validate:"max=512"`
-		Website      string   `form:"website" validate:"required,http_url"`
-		RedirectURIs []string `form:"redirectURIs" validate:"required,min=1,dive,required,http_url"`
+		Name                     string   `form:"name" validate:"required,notblank,min=3,max=32"`
+		Description              string   `form:"description" validate:"max=512"`
+		Website                  string   `form:"website" validate:"required,http_url"`
+		RedirectURIs             []string `form:"redirectURIs" validate:"required,min=1,dive,required,http_url"`
+		PostLogoutRedirectURIs   []string `form:"postLogoutRedirectURIs" validate:"dive,omitempty,http_url"`
+		BackchannelLogoutURI     string   `form:"backchannelLogoutURI" validate:"omitempty,http_url"`
+		ClientCredentialsScopes  string   `form:"clientCredentialsScopes" validate:"max=512"`
+		TokenExchange            bool     `form:"tokenExchange"`
+		PasswordGrant            bool     `form:"passwordGrant"`
+		ClientType               string   `form:"clientType" validate:"required,oneof=confidential public"`
+		AccessTokenFormat        string   `form:"accessTokenFormat" validate:"required,oneof=opaque jwt"`
+		IDTokenSignedResponseAlg string   `form:"idTokenSignedResponseAlg" validate:"required,oneof=RS256 ES256 EdDSA"`
 	}
 
 	body, ok := decodeAndValidateBody[request](h, w, r, "createApp", nil)
@@ -44,15 +61,50 @@ func (h *Handler) appCreate(w http.ResponseWriter, r *http.Request) {
 		serverError(w, err)
 		return
 	}
+	postLogoutRedirectURLs, err := stringsToStdURLs(slices.DeleteFunc(body.PostLogoutRedirectURIs, func(u string) bool { return u == "" }))
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	var backchannelLogoutURL *url.URL
+	if body.BackchannelLogoutURI != "" {
+		backchannelLogoutURL, err = url.Parse(body.BackchannelLogoutURI)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+	}
 
 	userID := h.AuthService.AuthenticatedUserID(r.Context())
-	client, secret, err := h.ClientService.Create(r.Context(), userID, body.Name, body.Description, website, redirectURLs)
+	client, secret, err := h.ClientService.Create(r.Context(), userID, body.Name, body.Description, website, redirectURLs, postLogoutRedirectURLs, backchannelLogoutURL, strings.Fields(body.ClientCredentialsScopes), body.TokenExchange, body.PasswordGrant, repos.ClientType(body.ClientType), repos.AccessTokenFormat(body.AccessTokenFormat), body.IDTokenSignedResponseAlg)
 	if err != nil {
-		serverError(w, err)
+		if errors.Is(err, services.ErrInvalidScope) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData := h.newTemplateData(r)
+			tmplData.Form = body
+			tmplData.FieldErrors["ClientCredentialsScopes"] = services.MustTranslate(lang, "invalidClientCredentialsScopes")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createApp", tmplData)
+		} else if errors.Is(err, services.ErrUnauthorizedClient) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData := h.newTemplateData(r)
+			tmplData.Form = body
+			tmplData.FieldErrors["TokenExchange"] = services.MustTranslate(lang, "tokenExchangePublicClient")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createApp", tmplData)
+		} else if errors.Is(err, services.ErrPasswordGrantNotAllowed) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData := h.newTemplateData(r)
+			tmplData.Form = body
+			tmplData.FieldErrors["PasswordGrant"] = services.MustTranslate(lang, "passwordGrantNotAllowed")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createApp", tmplData)
+		} else {
+			serverError(w, err)
+		}
 		return
 	}
 
-	h.SessionManager.Put(r.Context(), "clientSecret:"+client.ID.String(), secret)
+	if secret != "" {
+		h.SessionManager.Put(r.Context(), "clientSecret:"+client.ID.String(), secret)
+	}
 	http.Redirect(w, r, "/app/"+client.ID.String(), http.StatusSeeOther)
 }
 
@@ -116,22 +168,42 @@ func (h *Handler) appGet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type data struct {
-		ID string
+		ID         string
+		ClientType string
 	}
 	tmplData := h.newTemplateDataWithData(r, data{
-		ID: id.String(),
+		ID:         id.String(),
+		ClientType: string(client.Type),
 	})
+	var backchannelLogoutURI string
+	if client.BackchannelLogoutURI != nil {
+		backchannelLogoutURI = client.BackchannelLogoutURI.String()
+	}
 	type form struct {
-		Name         string
-		Description  string
-		Website      string
-		RedirectURIs []string
+		Name                     string
+		Description              string
+		Website                  string
+		RedirectURIs             []string
+		PostLogoutRedirectURIs   []string
+		BackchannelLogoutURI     string
+		ClientCredentialsScopes  string
+		TokenExchange            bool
+		PasswordGrant            bool
+		AccessTokenFormat        string
+		IDTokenSignedResponseAlg string
 	}
 	tmplData.Form = form{
-		Name:         client.Name,
-		Description:  client.Description,
-		Website:      client.Website.String(),
-		RedirectURIs: urlsToStrings(client.RedirectURIs),
+		Name:                     client.Name,
+		Description:              client.Description,
+		Website:                  client.Website.String(),
+		RedirectURIs:             urlsToStrings(client.RedirectURIs),
+		PostLogoutRedirectURIs:   urlsToStrings(client.PostLogoutRedirectURIs),
+		BackchannelLogoutURI:     backchannelLogoutURI,
+		ClientCredentialsScopes:  strings.Join(client.ClientCredentialsScopes, " "),
+		TokenExchange:            client.TokenExchange,
+		PasswordGrant:            client.PasswordGrant,
+		AccessTokenFormat:        string(client.AccessTokenFormat),
+		IDTokenSignedResponseAlg: client.IDTokenSignedResponseAlg,
 	}
 	h.Renderer.render(w, r, http.StatusOK, "app", tmplData)
 }
@@ -145,13 +217,34 @@ func (h *Handler) appUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type request struct {
-		Name         string   `form:"name" validate:"required,notblank,min=3,max=32"`
-		Description  string   `form:"description" validate:"max=512"`
-		Website      string   `form:"website" validate:"required,http_url"`
-		RedirectURIs []string `form:"redirectURIs" validate:"required,min=1,dive,required,http_url"`
+		Name                     string   `form:"name" validate:"required,notblank,min=3,max=32"`
+		Description              string   `form:"description" validate:"max=512"`
+		Website                  string   `form:"website" validate:"required,http_url"`
+		RedirectURIs             []string `form:"redirectURIs" validate:"required,min=1,dive,required,http_url"`
+		PostLogoutRedirectURIs   []string `form:"postLogoutRedirectURIs" validate:"dive,omitempty,http_url"`
+		BackchannelLogoutURI     string   `form:"backchannelLogoutURI" validate:"omitempty,http_url"`
+		ClientCredentialsScopes  string   `form:"clientCredentialsScopes" validate:"max=512"`
+		TokenExchange            bool     `form:"tokenExchange"`
+		PasswordGrant            bool     `form:"passwordGrant"`
+		AccessTokenFormat        string   `form:"accessTokenFormat" validate:"required,oneof=opaque jwt"`
+		IDTokenSignedResponseAlg string   `form:"idTokenSignedResponseAlg" validate:"required,oneof=RS256 ES256 EdDSA"`
 	}
 
-	tmplData := h.newTemplateDataWithData(r, struct{ ID string }{ID: id.String()})
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	client, err := h.ClientService.FindByUserAndID(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	tmplData := h.newTemplateDataWithData(r, struct {
+		ID         string
+		ClientType string
+	}{ID: id.String(), ClientType: string(client.Type)})
 	body, ok := decodeAndValidateBody[request](h, w, r, "app", &tmplData)
 	if !ok {
 		return
@@ -167,13 +260,42 @@ func (h *Handler) appUpdate(w http.ResponseWriter, r *http.Request) {
 		serverError(w, err)
 		return
 	}
-
-	userID := h.AuthService.AuthenticatedUserID(r.Context())
-	err = h.ClientService.Update(r.Context(), userID, id, body.Name, body.Description, website, redirectURLs)
+	postLogoutRedirectURLs, err := stringsToStdURLs(slices.DeleteFunc(body.PostLogoutRedirectURIs, func(u string) bool { return u == "" }))
 	if err != nil {
 		serverError(w, err)
 		return
 	}
+	var backchannelLogoutURL *url.URL
+	if body.BackchannelLogoutURI != "" {
+		backchannelLogoutURL, err = url.Parse(body.BackchannelLogoutURI)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+	}
+
+	err = h.ClientService.Update(r.Context(), userID, id, body.Name, body.Description, website, redirectURLs, postLogoutRedirectURLs, backchannelLogoutURL, strings.Fields(body.ClientCredentialsScopes), body.TokenExchange, body.PasswordGrant, repos.AccessTokenFormat(body.AccessTokenFormat), body.IDTokenSignedResponseAlg)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidScope) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData.Form = body
+			tmplData.FieldErrors["ClientCredentialsScopes"] = services.MustTranslate(lang, "invalidClientCredentialsScopes")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "app", tmplData)
+		} else if errors.Is(err, services.ErrUnauthorizedClient) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData.Form = body
+			tmplData.FieldErrors["TokenExchange"] = services.MustTranslate(lang, "tokenExchangePublicClient")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "app", tmplData)
+		} else if errors.Is(err, services.ErrPasswordGrantNotAllowed) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData.Form = body
+			tmplData.FieldErrors["PasswordGrant"] = services.MustTranslate(lang, "passwordGrantNotAllowed")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "app", tmplData)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
 
 	http.Redirect(w, r, "/app/"+id.String(), http.StatusSeeOther)
 }
@@ -213,3 +335,257 @@ func (h *Handler) appDelete(w http.ResponseWriter, r *http.Request) {
 	}
 	http.Redirect(w, r, "/app/list", http.StatusSeeOther)
 }
+
+// GET /app/{id}/scopes
+func (h *Handler) appScopes(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	scopes, err := h.ClientService.FindScopes(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	type scope struct {
+		ID   string
+		Name string
+	}
+	type data struct {
+		AppID  string
+		Scopes []scope
+	}
+	list := make([]scope, len(scopes))
+	for i, s := range scopes {
+		list[i] = scope{
+			ID:   s.ID.String(),
+			Name: s.Name,
+		}
+	}
+	h.Renderer.render(w, r, http.StatusOK, "appScopes", h.newTemplateDataWithData(r, data{
+		AppID:  id.String(),
+		Scopes: list,
+	}))
+}
+
+// GET /app/{id}/scopes/create
+func (h *Handler) appScopeCreatePage(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	_, err = h.ClientService.FindByUserAndID(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), id)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	h.Renderer.render(w, r, http.StatusOK, "createAppScope", h.newTemplateDataWithData(r, struct {
+		AppID string
+	}{AppID: id.String()}))
+}
+
+// POST /app/{id}/scopes/create
+func (h *Handler) appScopeCreate(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	type request struct {
+		Name          string `form:"name" validate:"required,notblank,max=64"`
+		Audience      string `form:"audience" validate:"max=256"`
+		DescriptionEN string `form:"descriptionEN" validate:"required,notblank,max=128"`
+		DescriptionDE string `form:"descriptionDE" validate:"max=128"`
+	}
+
+	tmplData := h.newTemplateDataWithData(r, struct {
+		AppID string
+	}{AppID: id.String()})
+	body, ok := decodeAndValidateBody[request](h, w, r, "createAppScope", &tmplData)
+	if !ok {
+		return
+	}
+
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	_, err = h.ClientService.CreateScope(r.Context(), userID, id, body.Name, body.Audience, map[string]string{
+		"en": body.DescriptionEN,
+		"de": body.DescriptionDE,
+	})
+	if err != nil {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		tmplData.Form = body
+		if errors.Is(err, services.ErrInvalidScope) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "invalidScopeName")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createAppScope", tmplData)
+		} else if errors.Is(err, repos.ErrExists) {
+			tmplData.FieldErrors["Name"] = services.MustTranslate(lang, "scopeExists")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createAppScope", tmplData)
+		} else if errors.Is(err, services.ErrInvalidAudience) {
+			tmplData.FieldErrors["Audience"] = services.MustTranslate(lang, "invalidAudience")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createAppScope", tmplData)
+		} else if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/app/"+id.String()+"/scopes", http.StatusSeeOther)
+}
+
+// GET /app/{id}/scopes/{scopeID}
+func (h *Handler) appScopeGet(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	scopeID, err := ulid.Parse(chi.URLParam(r, "scopeID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	scope, err := h.ClientService.FindScope(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), id, scopeID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	type data struct {
+		AppID string
+		ID    string
+		Name  string
+	}
+	type form struct {
+		Audience      string
+		DescriptionEN string
+		DescriptionDE string
+	}
+	tmplData := h.newTemplateDataWithData(r, data{
+		AppID: id.String(),
+		ID:    scope.ID.String(),
+		Name:  scope.Name,
+	})
+	tmplData.Form = form{
+		Audience:      scope.Audience,
+		DescriptionEN: scope.Descriptions["en"],
+		DescriptionDE: scope.Descriptions["de"],
+	}
+	h.Renderer.render(w, r, http.StatusOK, "appScope", tmplData)
+}
+
+// POST /app/{id}/scopes/{scopeID}/update
+func (h *Handler) appScopeUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	scopeID, err := ulid.Parse(chi.URLParam(r, "scopeID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	scope, err := h.ClientService.FindScope(r.Context(), userID, id, scopeID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	type request struct {
+		Audience      string `form:"audience" validate:"max=256"`
+		DescriptionEN string `form:"descriptionEN" validate:"required,notblank,max=128"`
+		DescriptionDE string `form:"descriptionDE" validate:"max=128"`
+	}
+
+	tmplData := h.newTemplateDataWithData(r, struct {
+		AppID string
+		ID    string
+		Name  string
+	}{AppID: id.String(), ID: scope.ID.String(), Name: scope.Name})
+	body, ok := decodeAndValidateBody[request](h, w, r, "appScope", &tmplData)
+	if !ok {
+		return
+	}
+
+	err = h.ClientService.UpdateScope(r.Context(), userID, id, scopeID, body.Audience, map[string]string{
+		"en": body.DescriptionEN,
+		"de": body.DescriptionDE,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAudience) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData.Form = body
+			tmplData.FieldErrors["Audience"] = services.MustTranslate(lang, "invalidAudience")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "appScope", tmplData)
+		} else if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/app/"+id.String()+"/scopes/"+scopeID.String(), http.StatusSeeOther)
+}
+
+// POST /app/{id}/scopes/{scopeID}/delete
+func (h *Handler) appScopeDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := ulid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	scopeID, err := ulid.Parse(chi.URLParam(r, "scopeID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	scope, err := h.ClientService.FindScope(r.Context(), userID, id, scopeID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	ok := h.verifyConfirmation(w, r, scope.Name, false)
+	if !ok {
+		return
+	}
+
+	err = h.ClientService.DeleteScope(r.Context(), userID, id, scopeID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/app/"+id.String()+"/scopes", http.StatusSeeOther)
+}
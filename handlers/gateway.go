@@ -1,36 +1,348 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/config"
+	"github.com/juho05/h-id/repos"
+	"github.com/juho05/h-id/services"
 )
 
+type gatewayRequestCtxKey struct{}
+
+// gatewayRequest is the request to a protected service that the reverse proxy asks the gateway to check.
+type gatewayRequest struct {
+	method string
+	url    *url.URL
+}
+
 func (h *Handler) authGatewayRoutes(r chi.Router) {
-	r.Get("/verify", h.authGatewayVerify)
+	// Caddy forward_auth and Traefik forwardAuth
+	r.With(parseGatewayRequest(forwardedRequest), h.gatewayRules, h.gatewayCredentials, h.auth).Get("/verify", h.authGatewayVerify)
+	r.With(parseGatewayRequest(forwardedRequest), h.gatewayRules, h.gatewayCredentials, h.auth).Get("/verify/traefik", h.authGatewayVerify)
+	// nginx auth_request only understands 2xx, 401 and 403, so users are sent to /gateway/login by error_page 401
+	r.With(parseGatewayRequest(nginxRequest), h.gatewayRules, h.gatewayCredentials).HandleFunc("/verify/nginx", h.authGatewayVerifyNginx)
+	// Envoy ext_authz appends the original path to the path prefix and keeps the original method
+	r.With(parseGatewayRequest(envoyRequest), h.gatewayRules, h.gatewayCredentials, h.auth).HandleFunc("/verify/envoy", h.authGatewayVerify)
+	r.With(parseGatewayRequest(envoyRequest), h.gatewayRules, h.gatewayCredentials, h.auth).HandleFunc("/verify/envoy/*", h.authGatewayVerify)
+	r.With(h.auth).Get("/login", h.authGatewayLogin)
 }
 
-// GET /gateway/verify
-func (h *Handler) authGatewayVerify(w http.ResponseWriter, r *http.Request) {
+// forwardedRequest reconstructs the checked request from the X-Forwarded-Method, X-Forwarded-Proto,
+// X-Forwarded-Host and X-Forwarded-Uri headers set by Caddy and Traefik.
+func forwardedRequest(r *http.Request) (gatewayRequest, bool) {
+	method := r.Header.Get("X-Forwarded-Method")
+	u, ok := forwardedURL(r)
+	if !ok || method == "" {
+		return gatewayRequest{}, false
+	}
+	return gatewayRequest{method: method, url: u}, true
+}
+
+// nginxRequest reconstructs the checked request from the X-Original-URL and X-Original-Method headers,
+// which have to be set with proxy_set_header in the auth_request location.
+func nginxRequest(r *http.Request) (gatewayRequest, bool) {
+	method := r.Header.Get("X-Original-Method")
+	if method == "" {
+		method = r.Header.Get("X-Forwarded-Method")
+	}
+	u, err := url.Parse(r.Header.Get("X-Original-URL"))
+	if err != nil || method == "" || !u.IsAbs() || u.Host == "" {
+		return gatewayRequest{}, false
+	}
+	return gatewayRequest{method: method, url: u}, true
+}
+
+// envoyRequest reconstructs the checked request of Envoy's ext_authz HTTP service, which sends the original method
+// and headers to the path prefix /gateway/verify/envoy followed by the original path.
+func envoyRequest(r *http.Request) (gatewayRequest, bool) {
+	host := r.Header.Get("X-Forwarded-Host")
+	if host == "" {
+		host = r.Host
+	}
+	proto := r.Header.Get("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "https"
+	}
+	uri := strings.TrimPrefix(r.URL.RequestURI(), "/gateway/verify/envoy")
+	if !strings.HasPrefix(uri, "/") {
+		uri = "/" + uri
+	}
+	u, err := url.Parse(proto + "://" + host + uri)
+	if err != nil || u.Host != host {
+		return gatewayRequest{}, false
+	}
+	return gatewayRequest{method: r.Method, url: u}, true
+}
+
+// forwardedURL reconstructs the URL of the request that is checked by the gateway from the
+// X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Uri headers set by the reverse proxy.
+func forwardedURL(r *http.Request) (*url.URL, bool) {
 	redirectProto := r.Header.Get("X-Forwarded-Proto")
 	redirectHost := r.Header.Get("X-Forwarded-Host")
-	redirectMethod := r.Header.Get("X-Forwarded-Method")
 	redirectURLStr := r.Header.Get("X-Forwarded-Uri")
-	if redirectProto == "" || redirectHost == "" || redirectMethod == "" || redirectURLStr == "" {
-		clientError(w, http.StatusBadRequest)
-		return
+	if redirectProto == "" || redirectHost == "" || redirectURLStr == "" {
+		return nil, false
 	}
 	redirectURL, err := url.Parse(redirectProto + "://" + redirectHost + redirectURLStr)
 	if err != nil || !redirectURL.IsAbs() || redirectURL.Host != redirectHost || redirectURL.RequestURI() != redirectURLStr {
-		clientError(w, http.StatusBadRequest)
+		return nil, false
+	}
+	return redirectURL, true
+}
+
+// parseGatewayRequest stores the checked request in the request context for the following handlers.
+func parseGatewayRequest(parse func(r *http.Request) (gatewayRequest, bool)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, ok := parse(r)
+			if !ok {
+				clientError(w, http.StatusBadRequest)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), gatewayRequestCtxKey{}, req)))
+		})
+	}
+}
+
+// gatewayPath returns the cleaned path of u, so that rules cannot be circumvented with paths like /public/../admin.
+func gatewayPath(u *url.URL) string {
+	p := path.Clean("/" + u.Path)
+	if strings.HasSuffix(u.Path, "/") && p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// gatewayRules decides requests that do not require a user (bypass and deny rules) before the auth middleware
+// redirects unauthenticated users to the login page.
+func (h *Handler) gatewayRules(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest)
+		switch h.AuthGatewayService.Authorize(ulid.ULID{}, req.url.Hostname(), req.method, gatewayPath(req.url)) {
+		case services.GatewayBypass:
+			w.WriteHeader(http.StatusOK)
+		case services.GatewayDeny:
+			clientError(w, http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// gatewayCredentials authenticates scripts and API clients with an H-ID access token (Bearer) or an app password (Basic)
+// in the Authorization header of the checked request. Credentials are only accepted for domains with a bearer scope, otherwise
+// the header is left for the protected service. Requests with credentials are decided here without using the session.
+func (h *Handler) gatewayCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest)
+		scope := h.AuthGatewayService.BearerScope(req.url.Hostname())
+		if scope == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var userID ulid.ULID
+		var source services.GatewayAuthSource
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			userID, ok = h.gatewayBearerUser(w, r, token, scope)
+			if !ok {
+				return
+			}
+			source = services.GatewayAuthBearer
+		} else if username, password, ok := r.BasicAuth(); ok {
+			userID, ok = h.gatewayAppPasswordUser(w, r, username, password, scope)
+			if !ok {
+				return
+			}
+			source = services.GatewayAuthAppPassword
+		} else {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), services.AuthUserIDCtxKey{}, userID))
+		switch h.AuthGatewayService.Authorize(userID, req.url.Hostname(), req.method, gatewayPath(req.url)) {
+		case services.GatewayAllow, services.GatewayBypass:
+		default:
+			clientError(w, http.StatusForbidden)
+			return
+		}
+		err := h.setGatewayHeaders(w, r, userID, req.url, source)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// gatewayBearerUser returns the user of an access token with the bearer scope of the domain.
+// If ok is false, an error response has already been written.
+func (h *Handler) gatewayBearerUser(w http.ResponseWriter, r *http.Request, token, scope string) (userID ulid.ULID, ok bool) {
+	access, err := h.AuthService.VerifyAccessToken(r.Context(), token, []string{scope})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer scope=\"%s\" error=\"%s\"", scope, "invalid_token"))
+			clientError(w, http.StatusUnauthorized)
+		} else if errors.Is(err, services.ErrInsufficientScope) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer scope=\"%s\" error=\"%s\"", scope, "insufficient_scope"))
+			clientError(w, http.StatusForbidden)
+		} else {
+			serverError(w, err)
+		}
+		return ulid.ULID{}, false
+	}
+	// tokens of the client credentials grant are not issued for a user
+	if access.UserID == nil {
+		clientError(w, http.StatusForbidden)
+		return ulid.ULID{}, false
+	}
+	return *access.UserID, true
+}
+
+// gatewayAppPasswordUser returns the user of an app password with the bearer scope of the domain.
+// The username is the email address of the user. If ok is false, an error response has already been written.
+func (h *Handler) gatewayAppPasswordUser(w http.ResponseWriter, r *http.Request, username, password, scope string) (userID ulid.ULID, ok bool) {
+	appPassword, err := h.AuthService.VerifyAppPassword(r.Context(), username, password, []string{scope})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"H-ID\", charset=\"UTF-8\"")
+			clientError(w, http.StatusUnauthorized)
+		} else if errors.Is(err, services.ErrInsufficientScope) {
+			clientError(w, http.StatusForbidden)
+		} else {
+			serverError(w, err)
+		}
+		return ulid.ULID{}, false
+	}
+	return appPassword.UserID, true
+}
+
+// GET /gateway/verify
+// GET /gateway/verify/traefik
+// ANY /gateway/verify/envoy/*
+func (h *Handler) authGatewayVerify(w http.ResponseWriter, r *http.Request) {
+	req := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest)
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	switch h.AuthGatewayService.Authorize(userID, req.url.Hostname(), req.method, gatewayPath(req.url)) {
+	case services.GatewayAllow:
+		if h.AuthGatewayService.RequiresStepUp(req.url.Hostname(), h.AuthService.AuthTime(r.Context()), h.AuthService.AMR(r.Context())) {
+			http.Redirect(w, r, fmt.Sprintf("%s/user/2fa/stepup?redirect=%s", config.BaseURL(), url.QueryEscape(req.url.String())), http.StatusSeeOther)
+			return
+		}
+	case services.GatewayBypass:
+	default:
+		clientError(w, http.StatusForbidden)
 		return
 	}
+	err := h.setGatewayHeaders(w, r, userID, req.url, services.GatewayAuthSession)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ANY /gateway/verify/nginx
+func (h *Handler) authGatewayVerifyNginx(w http.ResponseWriter, r *http.Request) {
+	req := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest)
 	userID := h.AuthService.AuthenticatedUserID(r.Context())
-	if !h.AuthGatewayService.IsAuthorized(userID, redirectURL.Hostname()) {
+	if userID != (ulid.ULID{}) {
+		confirmed, otpActive, hasRecovery, err := h.AuthService.CheckLoginPrerequisites(r.Context())
+		if err != nil || !confirmed || !otpActive || !hasRecovery {
+			// /gateway/login completes the login
+			userID = ulid.ULID{}
+		}
+	}
+	switch h.AuthGatewayService.Authorize(userID, req.url.Hostname(), req.method, gatewayPath(req.url)) {
+	case services.GatewayAllow:
+		if h.AuthGatewayService.RequiresStepUp(req.url.Hostname(), h.AuthService.AuthTime(r.Context()), h.AuthService.AMR(r.Context())) {
+			clientError(w, http.StatusUnauthorized)
+			return
+		}
+	case services.GatewayBypass:
+	case services.GatewayLoginRequired:
+		clientError(w, http.StatusUnauthorized)
+		return
+	default:
 		clientError(w, http.StatusForbidden)
 		return
 	}
-	w.Header().Add("Remote-User", userID.String())
+	err := h.setGatewayHeaders(w, r, userID, req.url, services.GatewayAuthSession)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// GET /gateway/login?redirect=<url>
+//
+// The login helper for nginx' error_page 401. The rest of the query string after redirect= is used as the URL,
+// so it does not have to be escaped, e.g. redirect=$scheme://$http_host$request_uri.
+func (h *Handler) authGatewayLogin(w http.ResponseWriter, r *http.Request) {
+	redirect, ok := strings.CutPrefix(r.URL.RawQuery, "redirect=")
+	if !ok {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(redirect, "://") {
+		unescaped, err := url.QueryUnescape(redirect)
+		if err != nil {
+			clientError(w, http.StatusBadRequest)
+			return
+		}
+		redirect = unescaped
+	}
+	if !h.AuthGatewayService.IsAllowedURL(redirect) {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	u, _ := url.Parse(redirect)
+	if h.AuthGatewayService.RequiresStepUp(u.Hostname(), h.AuthService.AuthTime(r.Context()), h.AuthService.AMR(r.Context())) {
+		http.Redirect(w, r, "/user/2fa/stepup?redirect="+url.QueryEscape(redirect), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// setGatewayHeaders adds the identity headers configured for the domain of u to the response.
+// source is the credential that identified the user.
+func (h *Handler) setGatewayHeaders(w http.ResponseWriter, r *http.Request, userID ulid.ULID, u *url.URL, source services.GatewayAuthSource) error {
+	headers := h.AuthGatewayService.Headers(u.Hostname())
+	if len(headers) == 0 || userID == (ulid.ULID{}) {
+		return nil
+	}
+	user, err := h.UserService.Find(r.Context(), userID)
+	if err != nil {
+		return fmt.Errorf("set gateway headers: %w", err)
+	}
+	for _, header := range headers {
+		var value string
+		switch header {
+		case repos.GatewayHeaderUser:
+			value = userID.String()
+		case repos.GatewayHeaderName:
+			value = user.Name
+		case repos.GatewayHeaderEmail:
+			value = user.Email
+		case repos.GatewayHeaderGroups:
+			value = strings.Join(h.AuthGatewayService.Groups(userID), ",")
+		case repos.GatewayHeaderAssertion:
+			value, err = h.AuthService.CreateGatewayAssertion(r.Context(), userID, u.Scheme+"://"+u.Host, source)
+			if err != nil {
+				return fmt.Errorf("set gateway headers: %w", err)
+			}
+		}
+		w.Header().Set(string(header), value)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -384,6 +385,15 @@ func (h *Handler) authUser(w http.ResponseWriter, r *http.Request) (user *repos.
 	return user, true
 }
 
+// clientIP returns the IP address of the client. The RealIP middleware has already applied the forwarding headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func noCache(w http.ResponseWriter) {
 	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Pragma", "no-cache")
@@ -84,7 +84,9 @@ func recoverPanic(next http.Handler) http.Handler {
 
 func csrf(next http.Handler) http.Handler {
 	handler := nosurf.New(next)
-	handler.ExemptGlobs("/user/passkey/create/*", "/user/passkey/verify/*")
+	handler.ExemptGlobs("/user/passkey/create/*", "/user/passkey/verify/*", "/user/2fa/stepup/passkey/*")
+	// nginx and Envoy check requests with the method of the original request
+	handler.ExemptRegexp("^/gateway/verify/")
 	handler.SetBaseCookie(http.Cookie{
 		HttpOnly: true,
 		Path:     "/",
@@ -108,20 +110,12 @@ func (h *Handler) noauth(next http.Handler) http.Handler {
 func (h *Handler) auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		redirect := r.URL.RequestURI()
-		if r.URL.Path == "/gateway/verify" {
-			redirectProto := r.Header.Get("X-Forwarded-Proto")
-			redirectHost := r.Header.Get("X-Forwarded-Host")
-			redirectURI := r.Header.Get("X-Forwarded-Uri")
-			if !h.AuthGatewayService.IsAllowedDomain(redirectHost) {
+		if req, ok := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest); ok {
+			if !h.AuthGatewayService.IsAllowedDomain(req.url.Hostname()) {
 				clientError(w, http.StatusForbidden)
 				return
 			}
-			uri, err := url.Parse(redirectProto + "://" + redirectHost + redirectURI)
-			if err != nil || !uri.IsAbs() || uri.Host != redirectHost || uri.RequestURI() != redirectURI {
-				clientError(w, http.StatusBadRequest)
-				return
-			}
-			redirect = uri.String()
+			redirect = req.url.String()
 		}
 		redirect = url.QueryEscape(redirect)
 
@@ -134,7 +128,11 @@ func (h *Handler) auth(next http.Handler) http.Handler {
 		if !slices.Contains([]string{"/user/logout", "/user/confirmEmail", "/user/2fa/otp/activate", "/user/2fa/recovery"}, r.URL.Path) {
 			confirmed, otpActive, hasRecovery, err := h.AuthService.CheckLoginPrerequisites(r.Context())
 			if err != nil {
-				h.SessionManager.Destroy(r.Context())
+				logoutErr := h.AuthService.Logout(r.Context())
+				if logoutErr != nil {
+					serverError(w, fmt.Errorf("auth middleware: logout after failed login prerequisites check (%s): %w", err, logoutErr))
+					return
+				}
 				http.Redirect(w, r, fmt.Sprintf("%s/user/login?redirect=%s", config.BaseURL(), redirect), http.StatusSeeOther)
 				return
 			}
@@ -152,6 +150,12 @@ func (h *Handler) auth(next http.Handler) http.Handler {
 			}
 		}
 
+		ip, userAgent := clientIP(r), r.UserAgent()
+		if _, ok := r.Context().Value(gatewayRequestCtxKey{}).(gatewayRequest); ok {
+			// forward-auth requests are sent by the gateway, not by the browser of the session
+			ip, userAgent = "", ""
+		}
+		h.AuthService.RecordSessionActivity(r.Context(), ip, userAgent)
 		r = r.WithContext(context.WithValue(r.Context(), services.AuthUserIDCtxKey{}, userID))
 
 		next.ServeHTTP(w, r)
@@ -161,7 +165,7 @@ func (h *Handler) auth(next http.Handler) http.Handler {
 func (h *Handler) oauth(requiredScopes ...string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userID, scopes, err := h.AuthService.VerifyAccessToken(r.Context(), strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), requiredScopes)
+			token, err := h.AuthService.VerifyAccessToken(r.Context(), strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), requiredScopes)
 			if err != nil {
 				if errors.Is(err, services.ErrInvalidCredentials) {
 					if r.Header.Get("Authorization") == "" {
@@ -178,8 +182,14 @@ func (h *Handler) oauth(requiredScopes ...string) func(next http.Handler) http.H
 				}
 				return
 			}
+			// tokens of the client credentials grant have no user, the zero ID prevents falling back to the session user
+			var userID ulid.ULID
+			if token.UserID != nil {
+				userID = *token.UserID
+			}
 			r = r.WithContext(context.WithValue(r.Context(), services.AuthUserIDCtxKey{}, userID))
-			r = r.WithContext(context.WithValue(r.Context(), services.AuthScopesCtxKey{}, scopes))
+			r = r.WithContext(context.WithValue(r.Context(), services.AuthClientIDCtxKey{}, token.ClientID))
+			r = r.WithContext(context.WithValue(r.Context(), services.AuthScopesCtxKey{}, token.Scopes))
 			next.ServeHTTP(w, r)
 		})
 	}
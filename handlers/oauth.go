@@ -1,31 +1,41 @@
 package handlers
 
 import (
-	"crypto/sha1"
-	"crypto/x509"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/oklog/ulid/v2"
 
+	"github.com/juho05/h-id/config"
 	"github.com/juho05/h-id/repos"
 	"github.com/juho05/h-id/services"
 	"github.com/juho05/log"
 )
 
 func (h *Handler) oauthRoutes(r chi.Router) {
-	r.With(h.auth).Get("/auth", h.oauthAuth)
+	r.Get("/auth", h.oauthAuth)
 	r.With(h.auth).Get("/consent", h.oauthConsentPage)
 	r.With(h.auth).Post("/consent", h.oauthConsent)
 
 	r.Get("/certs", h.oauthCerts)
 
+	r.Get("/logout", h.oauthLogout)
+	r.Post("/logout", h.oauthLogout)
+
+	r.Post("/device_authorization", h.oauthDeviceAuthorization)
 	r.Post("/token", h.oauthToken)
+	r.Post("/revoke", h.oauthRevoke)
+	r.Post("/introspect", h.oauthIntrospect)
 }
 
 func (h *Handler) oauthAuth(w http.ResponseWriter, r *http.Request) {
@@ -35,6 +45,12 @@ func (h *Handler) oauthAuth(w http.ResponseWriter, r *http.Request) {
 	responseType := r.URL.Query().Get("response_type")
 	state := r.URL.Query().Get("state")
 	nonce := r.URL.Query().Get("nonce")
+	codeChallenge := r.URL.Query().Get("code_challenge")
+	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
+	prompt := r.URL.Query().Get("prompt")
+	maxAge := r.URL.Query().Get("max_age")
+	loginHint := r.URL.Query().Get("login_hint")
+	idTokenHint := r.URL.Query().Get("id_token_hint")
 
 	clientID, err := ulid.Parse(clientIDStr)
 	if err != nil {
@@ -56,7 +72,7 @@ func (h *Handler) oauthAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.AuthService.StartOAuthCodeFlow(r.Context(), clientID, redirectURI, responseType, scope, state, nonce)
+	err = h.AuthService.StartOAuthCodeFlow(r.Context(), clientID, redirectURI, responseType, scope, state, nonce, codeChallenge, codeChallengeMethod, prompt, maxAge, idTokenHint)
 	if err != nil {
 		if errors.Is(err, repos.ErrNoRecord) {
 			clientError(w, http.StatusNotFound)
@@ -64,21 +80,26 @@ func (h *Handler) oauthAuth(w http.ResponseWriter, r *http.Request) {
 			clientError(w, http.StatusBadRequest)
 			log.Errorf("Invalid redirect URI: %s", redirectURI.String())
 		} else if errors.Is(err, services.ErrUnsupportedResponseType) {
-			q := redirectURI.Query()
-			q.Add("error", "unsupported_response_type")
-			if state != "" {
-				q.Add("state", state)
-			}
-			redirectURI.RawQuery = q.Encode()
-			http.Redirect(w, r, redirectURI.String(), http.StatusSeeOther)
+			oauthErrorRedirect(w, r, redirectURI, state, "unsupported_response_type")
 		} else if errors.Is(err, services.ErrInvalidScope) {
-			q := redirectURI.Query()
-			q.Add("error", "invalid_scope")
-			if state != "" {
-				q.Add("state", state)
+			oauthErrorRedirect(w, r, redirectURI, state, "invalid_scope")
+		} else if errors.Is(err, services.ErrInvalidRequest) {
+			oauthErrorRedirect(w, r, redirectURI, state, "invalid_request")
+		} else if errors.Is(err, services.ErrConsentRequired) {
+			oauthErrorRedirect(w, r, redirectURI, state, "consent_required")
+		} else if errors.Is(err, services.ErrLoginRequired) {
+			// an authenticated user only gets here if the id_token_hint belongs to another user
+			if slices.Contains(strings.Fields(prompt), "none") || h.AuthService.AuthenticatedUserID(r.Context()) != (ulid.ULID{}) {
+				oauthErrorRedirect(w, r, redirectURI, state, "login_required")
+				return
 			}
-			redirectURI.RawQuery = q.Encode()
-			http.Redirect(w, r, redirectURI.String(), http.StatusSeeOther)
+			h.oauthLoginRedirect(w, r, "/user/login", loginHint)
+		} else if errors.Is(err, services.ErrReauthenticationRequired) {
+			if slices.Contains(strings.Fields(prompt), "none") {
+				oauthErrorRedirect(w, r, redirectURI, state, "login_required")
+				return
+			}
+			h.oauthLoginRedirect(w, r, "/user/2fa/stepup", "")
 		} else {
 			serverError(w, err)
 		}
@@ -88,6 +109,39 @@ func (h *Handler) oauthAuth(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/oauth/consent", http.StatusSeeOther)
 }
 
+// oauthLoginRedirect redirects to the login page or, to re-authenticate the current user without ending their session, to the step-up page.
+// After the login the user returns to the authorization request without the parameters that forced the login.
+func (h *Handler) oauthLoginRedirect(w http.ResponseWriter, r *http.Request, page, loginHint string) {
+	if loginHint != "" {
+		h.SessionManager.Put(r.Context(), "email", loginHint)
+	}
+
+	query := r.URL.Query()
+	prompts := slices.DeleteFunc(strings.Fields(query.Get("prompt")), func(p string) bool {
+		return p == "login"
+	})
+	if len(prompts) > 0 {
+		query.Set("prompt", strings.Join(prompts, " "))
+	} else {
+		query.Del("prompt")
+	}
+	query.Del("max_age")
+	query.Del("id_token_hint")
+	redirect := r.URL.Path + "?" + query.Encode()
+
+	http.Redirect(w, r, fmt.Sprintf("%s%s?redirect=%s", config.BaseURL(), page, url.QueryEscape(redirect)), http.StatusSeeOther)
+}
+
+func oauthErrorRedirect(w http.ResponseWriter, r *http.Request, redirectURI *url.URL, state, errorCode string) {
+	q := redirectURI.Query()
+	q.Add("error", errorCode)
+	if state != "" {
+		q.Add("state", state)
+	}
+	redirectURI.RawQuery = q.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusSeeOther)
+}
+
 func (h *Handler) oauthConsentPage(w http.ResponseWriter, r *http.Request) {
 	authRequest, err := h.AuthService.GetAuthRequest(r.Context())
 	if err != nil {
@@ -132,7 +186,7 @@ func (h *Handler) oauthConsentPage(w http.ResponseWriter, r *http.Request) {
 		ClientName:        client.Name,
 		ClientDescription: client.Description,
 		ClientWebsite:     client.Website.String(),
-		Scopes:            h.AuthService.DescribeScopes(lang, authRequest.Scopes),
+		Scopes:            h.AuthService.DescribeScopes(r.Context(), lang, authRequest.Scopes),
 	}))
 }
 
@@ -155,14 +209,13 @@ func (h *Handler) oauthConsent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.UserCodeHash != nil {
+		h.oauthDeviceConsent(w, r, data.Choice == "accept")
+		return
+	}
+
 	if data.Choice != "accept" {
-		q := req.RedirectURI.Query()
-		q.Add("error", "access_denied")
-		if req.State != "" {
-			q.Add("state", req.State)
-		}
-		req.RedirectURI.RawQuery = q.Encode()
-		http.Redirect(w, r, req.RedirectURI.String(), http.StatusSeeOther)
+		oauthErrorRedirect(w, r, req.RedirectURI, req.State, "access_denied")
 		return
 	}
 
@@ -181,14 +234,64 @@ func (h *Handler) oauthConsent(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, req.RedirectURI.String(), http.StatusSeeOther)
 }
 
-func (h *Handler) oauthToken(w http.ResponseWriter, r *http.Request) {
+// GET/POST /oauth/logout
+func (h *Handler) oauthLogout(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	var clientID ulid.ULID
+	if clientIDStr := r.Form.Get("client_id"); clientIDStr != "" {
+		clientID, err = ulid.Parse(clientIDStr)
+		if err != nil {
+			clientError(w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	redirect, confirmed, err := h.AuthService.EndSession(r.Context(), r.Form.Get("id_token_hint"), clientID, r.Form.Get("post_logout_redirect_uri"), r.Form.Get("state"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRequest) || errors.Is(err, services.ErrInvalidRedirectURI) || errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusBadRequest)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	// without a matching id_token_hint the user has to confirm the logout to prevent other sites from logging them out
+	if !confirmed && h.AuthService.AuthenticatedUserID(r.Context()) != (ulid.ULID{}) {
+		if redirect != nil {
+			// the redirect is bound to this logout request so that a canceled confirmation does not affect later logouts
+			logoutRequest := services.GenerateToken(16)
+			h.SessionManager.Put(r.Context(), "postLogoutRequest", logoutRequest)
+			h.SessionManager.Put(r.Context(), "postLogoutRedirect", redirect.String())
+			http.Redirect(w, r, "/user/logout?request="+logoutRequest, http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, "/user/logout", http.StatusSeeOther)
+		return
+	}
+
+	err = h.AuthService.Logout(r.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	if redirect != nil {
+		http.Redirect(w, r, redirect.String(), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// POST /oauth/device_authorization
+func (h *Handler) oauthDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
 	noCache(w)
 
 	type request struct {
-		GrantType    string `form:"grant_type"`
-		Code         string `form:"code"`
-		RedirectURI  string `form:"redirect_uri"`
-		RefreshToken string `form:"refresh_token"`
+		Scope string `form:"scope"`
 	}
 
 	data, err := decodeBody[request](r)
@@ -197,49 +300,183 @@ func (h *Handler) oauthToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username, password, ok := r.BasicAuth()
+	clientID, clientSecret, ok := clientCredentials(w, r)
 	if !ok {
-		w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
-		respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
 		return
 	}
-	redirectURI, err := url.Parse(data.RedirectURI)
+
+	auth, err := h.AuthService.OAuthDeviceAuthorization(r.Context(), clientID, clientSecret, data.Scope)
 	if err != nil {
-		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
+			respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
+		} else if errors.Is(err, services.ErrInvalidScope) {
+			respondJSONError(w, errors.New("invalid_scope"), http.StatusBadRequest)
+		} else {
+			serverError(w, err)
+		}
 		return
 	}
-	clientIDStr, err := url.QueryUnescape(username)
+
+	type response struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURI         string `json:"verification_uri"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+		ExpiresIn               int64  `json:"expires_in"`
+		Interval                int64  `json:"interval"`
+	}
+	respondJSON(w, http.StatusOK, response{
+		DeviceCode:              auth.DeviceCode,
+		UserCode:                auth.UserCode,
+		VerificationURI:         config.BaseURL() + "/user/device",
+		VerificationURIComplete: config.BaseURL() + "/user/device?user_code=" + url.QueryEscape(auth.UserCode),
+		ExpiresIn:               int64(auth.ExpiresIn.Seconds()),
+		Interval:                int64(auth.Interval.Seconds()),
+	})
+}
+
+// GET /user/device
+func (h *Handler) oauthDevicePage(w http.ResponseWriter, r *http.Request) {
+	type form struct {
+		UserCode string
+	}
+	tmplData := h.newTemplateData(r)
+	tmplData.Form = form{
+		UserCode: r.URL.Query().Get("user_code"),
+	}
+	h.Renderer.render(w, r, http.StatusOK, "device", tmplData)
+}
+
+// POST /user/device
+func (h *Handler) oauthDevice(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		UserCode string `form:"userCode" validate:"required,max=32"`
+	}
+	body, ok := decodeAndValidateBody[request](h, w, r, "device", nil)
+	if !ok {
+		return
+	}
+
+	err := h.AuthService.StartOAuthDeviceFlow(r.Context(), body.UserCode)
 	if err != nil {
-		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		if errors.Is(err, services.ErrInvalidGrant) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData := h.newTemplateData(r)
+			tmplData.Form = body
+			tmplData.FieldErrors["UserCode"] = services.MustTranslate(lang, "invalidUserCode")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "device", tmplData)
+		} else {
+			serverError(w, err)
+		}
 		return
 	}
-	clientID, err := ulid.Parse(clientIDStr)
+	http.Redirect(w, r, "/oauth/consent", http.StatusSeeOther)
+}
+
+// oauthDeviceConsent handles the consent form of a device authorization.
+// Instead of redirecting to the client, the user is told to return to their device.
+func (h *Handler) oauthDeviceConsent(w http.ResponseWriter, r *http.Request, accept bool) {
+	err := h.AuthService.OAuthDeviceConsent(r.Context(), accept)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGrant) || errors.Is(err, services.ErrMissingRequiredSessionData) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			tmplData := h.newTemplateData(r)
+			tmplData.FieldErrors["UserCode"] = services.MustTranslate(lang, "invalidUserCode")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "device", tmplData)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	type data struct {
+		Approved bool
+		Denied   bool
+	}
+	h.Renderer.render(w, r, http.StatusOK, "device", h.newTemplateDataWithData(r, data{
+		Approved: accept,
+		Denied:   !accept,
+	}))
+}
+
+func (h *Handler) oauthToken(w http.ResponseWriter, r *http.Request) {
+	noCache(w)
+
+	type request struct {
+		GrantType    string `form:"grant_type"`
+		Code         string `form:"code"`
+		RedirectURI  string `form:"redirect_uri"`
+		RefreshToken string `form:"refresh_token"`
+		DeviceCode   string `form:"device_code"`
+		CodeVerifier string `form:"code_verifier"`
+		Scope        string `form:"scope"`
+		Username     string `form:"username"`
+		Password     string `form:"password"`
+
+		SubjectToken       string `form:"subject_token"`
+		SubjectTokenType   string `form:"subject_token_type"`
+		RequestedTokenType string `form:"requested_token_type"`
+		Audience           string `form:"audience"`
+	}
+
+	data, err := decodeBody[request](r)
 	if err != nil {
 		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
 		return
 	}
-	clientSecret, err := url.QueryUnescape(password)
+
+	redirectURI, err := url.Parse(data.RedirectURI)
 	if err != nil {
 		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
 		return
 	}
 
+	clientID, clientSecret, ok := clientCredentials(w, r)
+	if !ok {
+		return
+	}
+
 	var grant string
+	var issuedTokenType string
 	switch data.GrantType {
 	case "authorization_code":
 		grant = data.Code
 	case "refresh_token":
 		grant = data.RefreshToken
+	case services.GrantTypeDeviceCode:
+		grant = data.DeviceCode
+	case "password":
+		grant = data.Password
+	case services.GrantTypeTokenExchange:
+		// only access tokens can be exchanged for access tokens
+		if data.SubjectToken == "" || data.SubjectTokenType != services.TokenTypeAccessToken || (data.RequestedTokenType != "" && data.RequestedTokenType != services.TokenTypeAccessToken) {
+			respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+			return
+		}
+		grant = data.SubjectToken
+		issuedTokenType = services.TokenTypeAccessToken
 	}
 
-	access, refresh, id, err := h.AuthService.OAuthGenerateTokens(r.Context(), clientID, clientSecret, redirectURI, data.GrantType, grant)
+	access, refresh, id, err := h.AuthService.OAuthGenerateTokens(r.Context(), clientID, clientSecret, redirectURI, data.GrantType, grant, data.CodeVerifier, data.Scope, data.Audience, data.Username)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
 			respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
 		} else if errors.Is(err, services.ErrUnsupportedGrantType) {
 			respondJSONError(w, errors.New("unsupported_grant_type"), http.StatusBadRequest)
-		} else if errors.Is(err, services.ErrInvalidGrant) || errors.Is(err, services.ErrReusedToken) {
+		} else if errors.Is(err, services.ErrUnauthorizedClient) {
+			respondJSONError(w, errors.New("unauthorized_client"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrInvalidScope) {
+			respondJSONError(w, errors.New("invalid_scope"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrAuthorizationPending) {
+			respondJSONError(w, errors.New("authorization_pending"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrSlowDown) {
+			respondJSONError(w, errors.New("slow_down"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrAccessDenied) {
+			respondJSONError(w, errors.New("access_denied"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrInvalidTarget) {
+			respondJSONError(w, errors.New("invalid_target"), http.StatusBadRequest)
+		} else if errors.Is(err, services.ErrInvalidGrant) || errors.Is(err, services.ErrReusedToken) || errors.Is(err, services.ErrInvalidCodeVerifier) {
 			respondJSONError(w, errors.New("invalid_grant"), http.StatusBadRequest)
 		} else if errors.Is(err, services.ErrInvalidRedirectURI) {
 			respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
@@ -250,51 +487,197 @@ func (h *Handler) oauthToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type response struct {
-		TokenType    string `json:"token_type"`
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		IDToken      string `json:"id_token,omitempty"`
+		TokenType       string `json:"token_type"`
+		IssuedTokenType string `json:"issued_token_type,omitempty"`
+		AccessToken     string `json:"access_token"`
+		RefreshToken    string `json:"refresh_token,omitempty"`
+		IDToken         string `json:"id_token,omitempty"`
+	}
+	respondJSON(w, http.StatusOK, response{
+		TokenType:       "bearer",
+		IssuedTokenType: issuedTokenType,
+		AccessToken:     access,
+		RefreshToken:    refresh,
+		IDToken:         id,
+	})
+}
+
+// POST /oauth/revoke
+func (h *Handler) oauthRevoke(w http.ResponseWriter, r *http.Request) {
+	noCache(w)
+
+	type request struct {
+		Token         string `form:"token"`
+		TokenTypeHint string `form:"token_type_hint"`
+	}
+
+	data, err := decodeBody[request](r)
+	if err != nil || data.Token == "" {
+		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := clientCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.AuthService.RevokeOAuthToken(r.Context(), clientID, clientSecret, data.Token, data.TokenTypeHint)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
+			respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// POST /oauth/introspect
+func (h *Handler) oauthIntrospect(w http.ResponseWriter, r *http.Request) {
+	noCache(w)
+
+	type request struct {
+		Token         string `form:"token"`
+		TokenTypeHint string `form:"token_type_hint"`
+	}
+
+	data, err := decodeBody[request](r)
+	if err != nil || data.Token == "" {
+		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := clientCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.AuthService.IntrospectOAuthToken(r.Context(), clientID, clientSecret, data.Token, data.TokenTypeHint)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
+			respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	type response struct {
+		Active    bool            `json:"active"`
+		Scope     string          `json:"scope,omitempty"`
+		Subject   string          `json:"sub,omitempty"`
+		ClientID  string          `json:"client_id,omitempty"`
+		Audience  []string        `json:"aud,omitempty"`
+		Act       *services.Actor `json:"act,omitempty"`
+		ExpiresAt int64           `json:"exp,omitempty"`
+		IssuedAt  int64           `json:"iat,omitempty"`
+	}
+	if token == nil {
+		respondJSON(w, http.StatusOK, response{Active: false})
+		return
+	}
+	// tokens of the client credentials grant have the client as their subject
+	subject := token.ClientID.String()
+	if token.UserID != nil {
+		subject = token.UserID.String()
 	}
 	respondJSON(w, http.StatusOK, response{
-		TokenType:    "bearer",
-		AccessToken:  access,
-		RefreshToken: refresh,
-		IDToken:      id,
+		Active:    true,
+		Scope:     strings.Join(token.Scopes, " "),
+		Subject:   subject,
+		ClientID:  token.ClientID.String(),
+		Audience:  token.Audience,
+		Act:       token.Act,
+		ExpiresAt: token.Expires.Unix(),
+		IssuedAt:  token.CreatedAt.Unix(),
 	})
 }
 
+// clientCredentials extracts the client credentials of a token endpoint request.
+// Confidential clients use HTTP basic auth, public clients only send their client_id in the request body.
+// The form must already be parsed.
+func clientCredentials(w http.ResponseWriter, r *http.Request) (ulid.ULID, string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		if r.PostForm.Get("client_id") == "" {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"client authentication\"")
+			respondJSONError(w, errors.New("invalid_client"), http.StatusUnauthorized)
+			return ulid.ULID{}, "", false
+		}
+		clientID, err := ulid.Parse(r.PostForm.Get("client_id"))
+		if err != nil {
+			respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+			return ulid.ULID{}, "", false
+		}
+		return clientID, "", true
+	}
+	clientIDStr, err := url.QueryUnescape(username)
+	if err != nil {
+		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		return ulid.ULID{}, "", false
+	}
+	clientID, err := ulid.Parse(clientIDStr)
+	if err != nil {
+		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		return ulid.ULID{}, "", false
+	}
+	clientSecret, err := url.QueryUnescape(password)
+	if err != nil {
+		respondJSONError(w, errors.New("invalid_request"), http.StatusBadRequest)
+		return ulid.ULID{}, "", false
+	}
+	return clientID, clientSecret, true
+}
+
 func (h *Handler) oauthCerts(w http.ResponseWriter, r *http.Request) {
 	type key struct {
 		Type      string `json:"kty"`
 		Use       string `json:"use"`
 		Algorithm string `json:"alg"`
 		ID        string `json:"kid"`
-		N         string `json:"n"`
-		E         string `json:"e"`
+		N         string `json:"n,omitempty"`
+		E         string `json:"e,omitempty"`
+		Curve     string `json:"crv,omitempty"`
+		X         string `json:"x,omitempty"`
+		Y         string `json:"y,omitempty"`
 	}
 	type response struct {
 		Keys []key `json:"keys"`
 	}
 
-	pubKey := h.AuthService.PublicJWTKey()
-
-	n := base64.URLEncoding.EncodeToString(pubKey.N.Bytes())
-	e := base64.URLEncoding.EncodeToString(big.NewInt(int64(pubKey.E)).Bytes())
-
-	idHash := sha1.Sum(x509.MarshalPKCS1PublicKey(pubKey))
-	kid := base64.URLEncoding.EncodeToString(idHash[:])
-
+	pubKeys := h.AuthService.PublicJWTKeys()
 	resp := response{
-		Keys: []key{
-			{
-				Type:      "RSA",
-				Use:       "sig",
-				Algorithm: "RS256",
-				ID:        kid,
-				N:         n,
-				E:         e,
-			},
-		},
+		Keys: make([]key, 0, len(pubKeys)),
+	}
+	for _, k := range pubKeys {
+		jwk := key{
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+			ID:        k.ID,
+		}
+		switch pubKey := k.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.Type = "RSA"
+			jwk.N = base64.URLEncoding.EncodeToString(pubKey.N.Bytes())
+			jwk.E = base64.URLEncoding.EncodeToString(big.NewInt(int64(pubKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pubKey.Curve.Params().BitSize + 7) / 8
+			jwk.Type = "EC"
+			jwk.Curve = pubKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(pubKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(pubKey.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Type = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pubKey)
+		default:
+			continue
+		}
+		resp.Keys = append(resp.Keys, jwk)
 	}
 	respondJSON(w, http.StatusOK, resp)
 }
@@ -40,8 +40,12 @@ func (h *Handler) userRoutes(r chi.Router) {
 	r.With(h.noauth, rateLimit(3, 20*time.Second)).Post("/forgotPassword", h.forgotPassword)
 	r.With(h.noauth).Get("/resetPassword", h.resetPasswordPage)
 	r.With(h.noauth, rateLimit(2, 10*time.Second)).Post("/resetPassword", h.resetPassword)
+	r.With(h.auth).Get("/logout", h.userLogoutPage)
 	r.With(h.auth).Post("/logout", h.userLogout)
 
+	r.With(h.auth).Get("/device", h.oauthDevicePage)
+	r.With(h.auth, rateLimit(2, time.Second)).Post("/device", h.oauthDevice)
+
 	r.With(h.auth).Get("/confirmEmail", h.userConfirmEmailPage)
 	r.With(h.auth, rateLimit(2, time.Second)).Post("/confirmEmail", h.userConfirmEmail)
 
@@ -56,6 +60,11 @@ func (h *Handler) userRoutes(r chi.Router) {
 	r.With(h.auth).Get("/2fa/recovery/reset", h.newPage("resetRecoveryCodes"))
 	r.With(h.auth, rateLimit(2, time.Second)).Post("/2fa/recovery/reset", h.resetRecoveryCodes)
 
+	r.With(h.auth).Get("/2fa/stepup", h.stepUpPage)
+	r.With(h.auth, rateLimit(2, time.Second)).Post("/2fa/stepup", h.stepUp)
+	r.With(h.auth).Post("/2fa/stepup/passkey/begin", h.verifyPasskeyBegin)
+	r.With(h.auth).Post("/2fa/stepup/passkey/finish", h.stepUpPasskeyFinish)
+
 	r.With(h.noauth).Get("/2fa/otp/verify", h.verifyOTPPage)
 	r.With(h.noauth, rateLimit(2, time.Second)).Post("/2fa/otp/verify", h.verifyOTP)
 
@@ -67,6 +76,16 @@ func (h *Handler) userRoutes(r chi.Router) {
 	r.With(h.auth).Post("/passkey/create/begin", h.createPasskeyBegin)
 	r.With(h.auth).Post("/passkey/create/finish", h.createPasskeyFinish)
 
+	r.With(h.auth).Get("/appPassword", h.listAppPasswords)
+	r.With(h.auth).Get("/appPassword/create", h.newPage("createAppPassword"))
+	r.With(h.auth, rateLimit(2, time.Second)).Post("/appPassword/create", h.createAppPassword)
+	r.With(h.auth).Get("/appPassword/{appPasswordID}", h.getAppPassword)
+	r.With(h.auth).Post("/appPassword/{appPasswordID}/delete", h.deleteAppPassword)
+
+	r.With(h.auth).Get("/sessions", h.listSessions)
+	r.With(h.auth).Post("/sessions/others/delete", h.deleteOtherSessions)
+	r.With(h.auth).Post("/sessions/{sessionID}/delete", h.deleteSession)
+
 	r.With(h.noauth).Post("/passkey/verify/begin", h.verifyPasskeyBegin)
 	r.With(h.noauth).Post("/passkey/verify/finish", h.verifyPasskeyFinish)
 
@@ -160,7 +179,7 @@ func (h *Handler) userSignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.AuthService.Login(r.Context(), user.ID)
+	err = h.AuthService.Login(r.Context(), user.ID, []string{services.AMRPassword}, clientIP(r), r.UserAgent())
 	if err != nil {
 		serverError(w, err)
 		return
@@ -329,7 +348,7 @@ func (h *Handler) verifyOTPPage(w http.ResponseWriter, r *http.Request) {
 	}
 	remember2FAErr := h.AuthService.VerifyRemember2FACookie(r.Context(), userID, r)
 	if !active || remember2FAErr == nil {
-		err = h.AuthService.Login(r.Context(), userID)
+		err = h.AuthService.Login(r.Context(), userID, []string{services.AMRPassword}, clientIP(r), r.UserAgent())
 		if err != nil {
 			serverError(w, err)
 			return
@@ -362,7 +381,7 @@ func (h *Handler) verifyOTP(w http.ResponseWriter, r *http.Request) {
 
 	lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
 
-	err := h.AuthService.VerifyOTPCode(r.Context(), userID, body.Code)
+	recoveryCode, err := h.AuthService.VerifyOTPCode(r.Context(), userID, body.Code)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			data := h.newTemplateData(r)
@@ -376,7 +395,11 @@ func (h *Handler) verifyOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.AuthService.Login(r.Context(), userID)
+	amr := []string{services.AMRPassword, services.AMROTP}
+	if recoveryCode {
+		amr = []string{services.AMRPassword, services.AMRRecoveryCode}
+	}
+	err = h.AuthService.Login(r.Context(), userID, amr, clientIP(r), r.UserAgent())
 	if err != nil {
 		serverError(w, err)
 		return
@@ -392,6 +415,74 @@ func (h *Handler) verifyOTP(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "login")
 }
 
+// GET /user/2fa/stepup
+func (h *Handler) stepUpPage(w http.ResponseWriter, r *http.Request) {
+	h.storeRedirect(r, "stepUp")
+	h.Renderer.render(w, r, http.StatusOK, "stepUp", h.newTemplateData(r))
+}
+
+// POST /user/2fa/stepup
+func (h *Handler) stepUp(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		// recovery codes are not accepted, because they do not prove possession of the second factor
+		Code string `form:"code" validate:"required,len=6,numeric"`
+	}
+	body, ok := decodeAndValidateBody[request](h, w, r, "stepUp", nil)
+	if !ok {
+		return
+	}
+
+	recoveryCode, err := h.AuthService.VerifyOTPCode(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), body.Code)
+	if err == nil && recoveryCode {
+		err = services.ErrInvalidCredentials
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+			data := h.newTemplateData(r)
+			data.Errors = []string{services.MustTranslate(lang, "invalidCredentials")}
+			h.Renderer.render(w, r, http.StatusUnauthorized, "stepUp", data)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	err = h.AuthService.StepUp(r.Context(), services.AMROTP)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	h.redirect(w, r, "stepUp")
+}
+
+// POST /user/2fa/stepup/passkey/finish
+func (h *Handler) stepUpPasskeyFinish(w http.ResponseWriter, r *http.Request) {
+	user, err := h.AuthService.PasskeyFinishLogin(r.Context(), r)
+	if err == nil && user.ID != h.AuthService.AuthenticatedUserID(r.Context()) {
+		err = services.ErrInvalidCredentials
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			clientError(w, http.StatusUnauthorized)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	err = h.AuthService.StepUp(r.Context(), services.AMRPasskey)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	type response struct {
+		Redirect string `json:"redirect"`
+	}
+	respondJSON(w, http.StatusOK, response{
+		Redirect: h.popRedirect(r, "stepUp"),
+	})
+}
+
 // GET /user/passkey
 func (h *Handler) listPasskeys(w http.ResponseWriter, r *http.Request) {
 	type passkey struct {
@@ -602,7 +693,7 @@ func (h *Handler) verifyPasskeyFinish(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	err = h.AuthService.Login(r.Context(), user.ID)
+	err = h.AuthService.Login(r.Context(), user.ID, []string{services.AMRPasskey}, clientIP(r), r.UserAgent())
 	if err != nil {
 		serverError(w, err)
 		return
@@ -619,13 +710,243 @@ func (h *Handler) verifyPasskeyFinish(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GET /user/appPassword
+func (h *Handler) listAppPasswords(w http.ResponseWriter, r *http.Request) {
+	type appPassword struct {
+		ID   string
+		Name string
+	}
+	type data struct {
+		AppPasswords []appPassword
+	}
+	appPasswords, err := h.UserService.GetAppPasswords(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()))
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	list := make([]appPassword, len(appPasswords))
+	for i, a := range appPasswords {
+		list[i] = appPassword{
+			ID:   a.ID.String(),
+			Name: a.Name,
+		}
+	}
+	h.Renderer.render(w, r, http.StatusOK, "appPasswords", h.newTemplateDataWithData(r, data{
+		AppPasswords: list,
+	}))
+}
+
+// GET /user/appPassword/{appPasswordID}
+func (h *Handler) getAppPassword(w http.ResponseWriter, r *http.Request) {
+	appPasswordID, err := ulid.Parse(chi.URLParam(r, "appPasswordID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	appPassword, err := h.UserService.GetAppPassword(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), appPasswordID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	if password := h.SessionManager.PopString(r.Context(), "appPassword:"+appPasswordID.String()); password != "" {
+		user, err := h.UserService.Find(r.Context(), appPassword.UserID)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		type data struct {
+			Name     string
+			Username string
+			Password string
+		}
+		h.Renderer.render(w, r, http.StatusOK, "appPasswordCreated", h.newTemplateDataWithData(r, data{
+			Name:     appPassword.Name,
+			Username: user.Email,
+			Password: password,
+		}))
+		return
+	}
+
+	type data struct {
+		ID        string
+		Name      string
+		Scopes    string
+		CreatedAt string
+		LastUsed  string
+	}
+	var lastUsed string
+	if !appPassword.LastUsed.IsZero() {
+		lastUsed = appPassword.LastUsed.Format(time.DateTime + " MST")
+	}
+	h.Renderer.render(w, r, http.StatusOK, "appPassword", h.newTemplateDataWithData(r, data{
+		ID:        appPassword.ID.String(),
+		Name:      appPassword.Name,
+		Scopes:    strings.Join(appPassword.Scopes, " "),
+		CreatedAt: appPassword.CreatedAt.Format(time.DateTime + " MST"),
+		LastUsed:  lastUsed,
+	}))
+}
+
+// POST /user/appPassword/create
+func (h *Handler) createAppPassword(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Name     string `form:"name" validate:"required,notblank,min=3,max=32"`
+		Scopes   string `form:"scopes" validate:"required,notblank,max=512"`
+		Password string `form:"password" validate:"required"`
+	}
+	body, ok := decodeAndValidateBody[request](h, w, r, "createAppPassword", nil)
+	if !ok {
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	appPassword, password, err := h.AuthService.CreateAppPassword(r.Context(), userID, body.Password, body.Name, body.Scopes)
+	if err != nil {
+		lang := services.GetLanguageFromAcceptLanguageHeader(strings.Join(r.Header["Accept-Language"], ","))
+		tmplData := h.newTemplateData(r)
+		body.Password = ""
+		tmplData.Form = body
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			tmplData.FieldErrors["Password"] = services.MustTranslate(lang, "wrongPassword")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createAppPassword", tmplData)
+		} else if errors.Is(err, services.ErrInvalidScope) {
+			tmplData.FieldErrors["Scopes"] = services.MustTranslate(lang, "invalidAppPasswordScopes")
+			h.Renderer.render(w, r, http.StatusUnprocessableEntity, "createAppPassword", tmplData)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "appPassword:"+appPassword.ID.String(), password)
+	http.Redirect(w, r, "/user/appPassword/"+appPassword.ID.String(), http.StatusSeeOther)
+}
+
+// POST /user/appPassword/{appPasswordID}/delete
+func (h *Handler) deleteAppPassword(w http.ResponseWriter, r *http.Request) {
+	appPasswordID, err := ulid.Parse(chi.URLParam(r, "appPasswordID"))
+	if err != nil {
+		clientError(w, http.StatusBadRequest)
+		return
+	}
+	appPassword, err := h.UserService.GetAppPassword(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), appPasswordID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	ok := h.verifyConfirmation(w, r, appPassword.Name, false)
+	if !ok {
+		return
+	}
+	err = h.UserService.DeleteAppPassword(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), appPasswordID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/user/appPassword", http.StatusSeeOther)
+}
+
+// GET /user/sessions
+func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
+	type session struct {
+		ID           string
+		Device       string
+		UserAgent    string
+		IP           string
+		LoginTime    string
+		LastActivity string
+		Current      bool
+	}
+	type data struct {
+		Sessions []session
+	}
+	sessions, err := h.AuthService.GetSessions(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()))
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	list := make([]session, len(sessions))
+	for i, s := range sessions {
+		list[i] = session{
+			ID:           s.ID,
+			Device:       s.Device,
+			UserAgent:    s.UserAgent,
+			IP:           s.IP,
+			LoginTime:    s.LoginTime.Format(time.DateTime + " MST"),
+			LastActivity: s.LastActivity.Format(time.DateTime + " MST"),
+			Current:      s.Current,
+		}
+	}
+	h.Renderer.render(w, r, http.StatusOK, "sessions", h.newTemplateDataWithData(r, data{
+		Sessions: list,
+	}))
+}
+
+// POST /user/sessions/{sessionID}/delete
+func (h *Handler) deleteSession(w http.ResponseWriter, r *http.Request) {
+	err := h.AuthService.LogoutSession(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()), chi.URLParam(r, "sessionID"))
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			clientError(w, http.StatusNotFound)
+		} else {
+			serverError(w, err)
+		}
+		return
+	}
+	http.Redirect(w, r, "/user/sessions", http.StatusSeeOther)
+}
+
+// POST /user/sessions/others/delete
+func (h *Handler) deleteOtherSessions(w http.ResponseWriter, r *http.Request) {
+	err := h.AuthService.LogoutOtherSessions(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()))
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	http.Redirect(w, r, "/user/sessions", http.StatusSeeOther)
+}
+
+// GET /user/logout
+func (h *Handler) userLogoutPage(w http.ResponseWriter, r *http.Request) {
+	type data struct {
+		LogoutRequest string
+	}
+	h.Renderer.render(w, r, http.StatusOK, "logout", h.newTemplateDataWithData(r, data{
+		LogoutRequest: r.URL.Query().Get("request"),
+	}))
+}
+
 // POST /user/logout
 func (h *Handler) userLogout(w http.ResponseWriter, r *http.Request) {
+	// set by /oauth/logout and already validated against the post logout redirect URIs of the client
+	logoutRequest := h.SessionManager.PopString(r.Context(), "postLogoutRequest")
+	redirect := h.SessionManager.PopString(r.Context(), "postLogoutRedirect")
+	if logoutRequest == "" || r.PostFormValue("logoutRequest") != logoutRequest {
+		redirect = ""
+	}
 	err := h.AuthService.Logout(r.Context())
 	if err != nil {
 		serverError(w, err)
 		return
 	}
+	if redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
@@ -696,10 +1017,19 @@ func (h *Handler) userInfo(w http.ResponseWriter, r *http.Request) {
 		Name          string    `json:"name,omitempty"`
 		Email         string    `json:"email,omitempty"`
 		EmailVerified bool      `json:"email_verified,omitempty"`
+		Groups        []string  `json:"groups,omitempty"`
 		Picture       string    `json:"picture"`
 	}
 
-	user, err := h.UserService.Find(r.Context(), h.AuthService.AuthenticatedUserID(r.Context()))
+	userID := h.AuthService.AuthenticatedUserID(r.Context())
+	if userID == (ulid.ULID{}) {
+		// tokens of the client credentials grant do not belong to a user
+		w.Header().Set("WWW-Authenticate", "Bearer error=\"invalid_token\"")
+		clientError(w, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.UserService.Find(r.Context(), userID)
 	if err != nil {
 		serverError(w, fmt.Errorf("userinfo endpoint: %w", err))
 		return
@@ -716,6 +1046,8 @@ func (h *Handler) userInfo(w http.ResponseWriter, r *http.Request) {
 		case "email":
 			resp.Email = user.Email
 			resp.EmailVerified = user.EmailConfirmed
+		case "groups":
+			resp.Groups = h.AuthGatewayService.Groups(user.ID)
 		}
 	}
 	respondJSON(w, http.StatusOK, resp)
@@ -797,7 +1129,7 @@ func (h *Handler) userActivateOTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if h.AuthService.AuthenticatedUserID(r.Context()) == (ulid.ULID{}) {
-		err = h.AuthService.Login(r.Context(), userID)
+		err = h.AuthService.Login(r.Context(), userID, []string{services.AMRPassword, services.AMROTP}, clientIP(r), r.UserAgent())
 		if err != nil {
 			serverError(w, err)
 			return
@@ -7,22 +7,75 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
+type ClientType string
+
+var (
+	// ClientTypeConfidential clients can keep a client secret confidential and authenticate with it.
+	ClientTypeConfidential ClientType = "confidential"
+	// ClientTypePublic clients (e.g. SPAs, native apps) cannot keep a secret and have to use PKCE instead.
+	ClientTypePublic ClientType = "public"
+)
+
+type AccessTokenFormat string
+
+var (
+	// AccessTokenFormatOpaque access tokens are random strings that can only be verified by H-ID.
+	AccessTokenFormatOpaque AccessTokenFormat = "opaque"
+	// AccessTokenFormatJWT access tokens are signed JWTs (RFC 9068) that can be verified offline.
+	AccessTokenFormatJWT AccessTokenFormat = "jwt"
+)
+
 type ClientModel struct {
 	BaseModel
-	Name         string
-	Description  string
-	Website      *url.URL
-	RedirectURIs []*url.URL
-	SecretHash   []byte
-	UserID       ulid.ULID
+	Name              string
+	Description       string
+	Website           *url.URL
+	RedirectURIs      []*url.URL
+	SecretHash        []byte
+	UserID            ulid.ULID
+	Type              ClientType
+	AccessTokenFormat AccessTokenFormat
+	// IDTokenSignedResponseAlg is the JWS algorithm used to sign ID tokens for this client.
+	IDTokenSignedResponseAlg string
+	// PostLogoutRedirectURIs are the URIs the client may redirect to after RP-initiated logout.
+	PostLogoutRedirectURIs []*url.URL
+	// BackchannelLogoutURI receives logout tokens when a user logs out of H-ID. It is nil if the client does not support back-channel logout.
+	BackchannelLogoutURI *url.URL
+	// ClientCredentialsScopes are the scopes the client may request with the client credentials grant.
+	// The grant is disabled if the list is empty.
+	ClientCredentialsScopes []string
+	// TokenExchange allows the client to exchange access tokens of users for down-scoped tokens (RFC 8693).
+	TokenExchange bool
+	// PasswordGrant allows the client to exchange the app passwords of users for access tokens.
+	PasswordGrant bool
+}
+
+// ScopeModel is a custom scope registered by the owner of a client, e.g. for an API that is protected by H-ID.
+type ScopeModel struct {
+	BaseModel
+	// ClientID is the client that owns the scope.
+	ClientID ulid.ULID
+	Name     string
+	// Audience is added to the aud claim of access tokens that contain the scope.
+	Audience string
+	// Descriptions maps language codes to the text that is shown on the consent page.
+	Descriptions map[string]string
 }
 
 type ClientRepository interface {
 	Find(ctx context.Context, id ulid.ULID) (*ClientModel, error)
 	FindByUserAndID(ctx context.Context, userID, id ulid.ULID) (*ClientModel, error)
 	FindByUser(ctx context.Context, userID ulid.ULID) ([]*ClientModel, error)
-	Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL, secretHash []byte) (*ClientModel, error)
-	Update(ctx context.Context, userID, id ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) (*ClientModel, error)
+	Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, secretHash []byte, clientType ClientType, accessTokenFormat AccessTokenFormat, idTokenSignedResponseAlg string) (*ClientModel, error)
+	Update(ctx context.Context, userID, id ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, accessTokenFormat AccessTokenFormat, idTokenSignedResponseAlg string) (*ClientModel, error)
 	UpdateSecret(ctx context.Context, userID, id ulid.ULID, newSecretHash []byte) error
 	Delete(ctx context.Context, userID, id ulid.ULID) error
+
+	FindScope(ctx context.Context, clientID, id ulid.ULID) (*ScopeModel, error)
+	FindScopeByName(ctx context.Context, name string) (*ScopeModel, error)
+	FindScopesByClient(ctx context.Context, clientID ulid.ULID) ([]*ScopeModel, error)
+	FindScopesByAudience(ctx context.Context, audience string) ([]*ScopeModel, error)
+	CreateScope(ctx context.Context, clientID ulid.ULID, name, audience string, descriptions map[string]string) (*ScopeModel, error)
+	UpdateScope(ctx context.Context, clientID, id ulid.ULID, audience string, descriptions map[string]string) (*ScopeModel, error)
+	DeleteScope(ctx context.Context, clientID, id ulid.ULID) error
 }
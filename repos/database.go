@@ -13,6 +13,7 @@ type DB interface {
 	NewTokenRepository() TokenRepository
 	NewClientRepository() ClientRepository
 	NewOAuthRepository() OAuthRepository
+	NewGatewayRepository() GatewayRepository
 
 	Close() error
 }
@@ -0,0 +1,109 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// GatewayGroupModel is a group of users that can be granted access to auth gateway domains.
+type GatewayGroupModel struct {
+	BaseModel
+	Name string
+}
+
+type GatewayGroupMemberModel struct {
+	CreatedAt time.Time
+	GroupID   ulid.ULID
+	UserID    ulid.ULID
+}
+
+type GatewayPolicy string
+
+const (
+	GatewayPolicyAllow GatewayPolicy = "allow"
+	GatewayPolicyDeny  GatewayPolicy = "deny"
+	// GatewayPolicyBypass allows access without authentication.
+	GatewayPolicyBypass GatewayPolicy = "bypass"
+)
+
+// GatewayRuleModel applies Policy to requests that match Methods and Path.
+// Allow and deny rules with Groups or Users only apply to these subjects.
+type GatewayRuleModel struct {
+	Policy GatewayPolicy `json:"policy"`
+	// Methods contains upper case HTTP methods. Empty matches all methods.
+	Methods []string `json:"methods,omitempty"`
+	// Path is a glob pattern, in which * matches any sequence of characters, or a regular expression prefixed with "re:".
+	Path   string      `json:"path"`
+	Groups []ulid.ULID `json:"groups,omitempty"`
+	Users  []ulid.ULID `json:"users,omitempty"`
+}
+
+// GatewayHeader is an identity header that /gateway/verify returns to the reverse proxy for allowed requests.
+type GatewayHeader string
+
+const (
+	GatewayHeaderUser   GatewayHeader = "Remote-User"
+	GatewayHeaderName   GatewayHeader = "Remote-Name"
+	GatewayHeaderEmail  GatewayHeader = "Remote-Email"
+	GatewayHeaderGroups GatewayHeader = "Remote-Groups"
+	// GatewayHeaderAssertion contains a short-lived JWT with the identity of the user signed with the keys at /oauth/certs.
+	GatewayHeaderAssertion GatewayHeader = "X-HID-Assertion"
+)
+
+// DefaultGatewayHeaders are the headers of new domains.
+var DefaultGatewayHeaders = []GatewayHeader{GatewayHeaderUser, GatewayHeaderName, GatewayHeaderEmail, GatewayHeaderGroups}
+
+// GatewayDomainModel grants users and groups access to a domain protected by the auth gateway.
+// Domain may start with a wildcard label, e.g. *.example.com.
+// Rules are evaluated in order before Groups and Users, the first matching rule decides.
+type GatewayDomainModel struct {
+	BaseModel
+	Domain string
+	Groups []ulid.ULID
+	Users  []ulid.ULID
+	Rules  []GatewayRuleModel
+	// MaxAuthAge is the maximum time since the last login of the user. Zero disables the check.
+	MaxAuthAge time.Duration
+	// Require2FA requires a passkey or TOTP code to have been used in the session.
+	Require2FA bool
+	Headers    []GatewayHeader
+	// BearerScope is the scope H-ID access tokens need to access the domain. Empty disables access tokens.
+	BearerScope string
+}
+
+// GatewayConfigFileModel is the auth gateway config file that was last applied to the database.
+type GatewayConfigFileModel struct {
+	AppliedAt time.Time
+	// Hash is the SHA-256 hash of Data.
+	Hash []byte
+	Data []byte
+}
+
+type GatewayRepository interface {
+	// Transaction calls fn with a repository whose changes are only committed if fn returns nil.
+	// fn must not start another transaction.
+	Transaction(ctx context.Context, fn func(repo GatewayRepository) error) error
+
+	FindGroups(ctx context.Context) ([]*GatewayGroupModel, error)
+	FindGroup(ctx context.Context, id ulid.ULID) (*GatewayGroupModel, error)
+	CreateGroup(ctx context.Context, name string) (*GatewayGroupModel, error)
+	UpdateGroup(ctx context.Context, id ulid.ULID, name string) (*GatewayGroupModel, error)
+	DeleteGroup(ctx context.Context, id ulid.ULID) error
+
+	FindGroupMembers(ctx context.Context, groupID ulid.ULID) ([]*GatewayGroupMemberModel, error)
+	FindAllGroupMembers(ctx context.Context) ([]*GatewayGroupMemberModel, error)
+	AddGroupMember(ctx context.Context, groupID, userID ulid.ULID) error
+	RemoveGroupMember(ctx context.Context, groupID, userID ulid.ULID) error
+
+	FindDomains(ctx context.Context) ([]*GatewayDomainModel, error)
+	FindDomain(ctx context.Context, id ulid.ULID) (*GatewayDomainModel, error)
+	CreateDomain(ctx context.Context, domain string, groups, users []ulid.ULID, rules []GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []GatewayHeader, bearerScope string) (*GatewayDomainModel, error)
+	UpdateDomain(ctx context.Context, id ulid.ULID, domain string, groups, users []ulid.ULID, rules []GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []GatewayHeader, bearerScope string) (*GatewayDomainModel, error)
+	DeleteDomain(ctx context.Context, id ulid.ULID) error
+
+	// FindConfigFile returns the last applied config file or ErrNoRecord if no config file was ever applied.
+	FindConfigFile(ctx context.Context) (*GatewayConfigFileModel, error)
+	SetConfigFile(ctx context.Context, hash, data []byte) error
+}
@@ -14,6 +14,10 @@ var (
 	OAuthTokenCode    OAuthTokenCategory = "code"
 	OAuthTokenAccess  OAuthTokenCategory = "access"
 	OAuthTokenRefresh OAuthTokenCategory = "refresh"
+	// OAuthTokenDeviceCode is a pending device authorization (RFC 8628) that is polled by the device.
+	OAuthTokenDeviceCode OAuthTokenCategory = "device_code"
+	// OAuthTokenUserCode is the user code of a device authorization. It is updated when the user approves or denies the device.
+	OAuthTokenUserCode OAuthTokenCategory = "user_code"
 )
 
 type OAuthTokenModel struct {
@@ -22,11 +26,14 @@ type OAuthTokenModel struct {
 	TokenHash   []byte
 	RedirectURI *url.URL
 	ClientID    ulid.ULID
-	UserID      ulid.ULID
-	Scopes      []string
-	Data        []byte
-	Expires     time.Time
-	Used        bool
+	// UserID is nil for tokens issued with the client credentials grant.
+	UserID  *ulid.ULID
+	Scopes  []string
+	Data    []byte
+	Expires time.Time
+	Used    bool
+	// GrantID is shared by all tokens that originate from the same authorization grant.
+	GrantID ulid.ULID
 }
 
 type PermissionsModel struct {
@@ -36,14 +43,34 @@ type PermissionsModel struct {
 	Scopes    []string
 }
 
+// BackchannelLogoutModel is a pending back-channel logout notification for a client.
+type BackchannelLogoutModel struct {
+	BaseModel
+	ClientID ulid.ULID
+	UserID   ulid.ULID
+	// SID is empty if all sessions of the user have ended.
+	SID         string
+	Attempt     int
+	NextAttempt time.Time
+}
+
 type OAuthRepository interface {
-	Create(ctx context.Context, clientID, userID ulid.ULID, category OAuthTokenCategory, tokenHash []byte, redirectURI *url.URL, scopes []string, data []byte, lifetime time.Duration) (*OAuthTokenModel, error)
+	Create(ctx context.Context, clientID ulid.ULID, userID *ulid.ULID, grantID ulid.ULID, category OAuthTokenCategory, tokenHash []byte, redirectURI *url.URL, scopes []string, data []byte, lifetime time.Duration) (*OAuthTokenModel, error)
 	Find(ctx context.Context, category OAuthTokenCategory, tokenHash []byte) (*OAuthTokenModel, error)
 	Use(ctx context.Context, clientID ulid.ULID, category OAuthTokenCategory, tokenHash []byte) error
+	Update(ctx context.Context, clientID ulid.ULID, category OAuthTokenCategory, tokenHash []byte, userID *ulid.ULID, data []byte) error
 	Delete(ctx context.Context, clientID ulid.ULID, category OAuthTokenCategory, tokenHash []byte) error
+	DeleteByGrant(ctx context.Context, clientID, grantID ulid.ULID) error
 	DeleteByUser(ctx context.Context, clientID, userID ulid.ULID) error
 
 	SetPermissions(ctx context.Context, clientID, userID ulid.ULID, scopes []string) (*PermissionsModel, error)
 	FindPermissions(ctx context.Context, clientID, userID ulid.ULID) (*PermissionsModel, error)
+	FindPermissionsByUser(ctx context.Context, userID ulid.ULID) ([]*PermissionsModel, error)
 	RevokePermissions(ctx context.Context, clientID, userID ulid.ULID) error
+
+	CreateBackchannelLogout(ctx context.Context, clientID, userID ulid.ULID, sid string) error
+	// FindDueBackchannelLogouts returns the pending back-channel logouts whose next attempt is due.
+	FindDueBackchannelLogouts(ctx context.Context) ([]*BackchannelLogoutModel, error)
+	UpdateBackchannelLogoutAttempt(ctx context.Context, id ulid.ULID, attempt int, nextAttempt time.Time) error
+	DeleteBackchannelLogout(ctx context.Context, id ulid.ULID) error
 }
@@ -2,8 +2,11 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -52,17 +55,40 @@ func repoClient(client db.Client) (*repos.ClientModel, error) {
 	if err != nil {
 		return nil, err
 	}
+	postLogoutRedirectURLs, err := urlsFromJSON(client.PostLogoutRedirectUris)
+	if err != nil {
+		return nil, err
+	}
+	var backchannelLogoutURL *url.URL
+	if client.BackchannelLogoutUri != "" {
+		backchannelLogoutURL, err = url.Parse(client.BackchannelLogoutUri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var clientCredentialsScopes []string
+	if client.ClientCredentialsScopes != "" {
+		clientCredentialsScopes = strings.Split(client.ClientCredentialsScopes, ",")
+	}
 	return &repos.ClientModel{
 		BaseModel: repos.BaseModel{
 			ID:        id,
 			CreatedAt: time.Unix(client.CreatedAt, 0),
 		},
-		Name:         client.Name,
-		Description:  client.Description,
-		Website:      website,
-		RedirectURIs: redirectURLs,
-		SecretHash:   client.SecretHash,
-		UserID:       userID,
+		Name:                     client.Name,
+		Description:              client.Description,
+		Website:                  website,
+		RedirectURIs:             redirectURLs,
+		SecretHash:               client.SecretHash,
+		UserID:                   userID,
+		Type:                     repos.ClientType(client.ClientType),
+		AccessTokenFormat:        repos.AccessTokenFormat(client.AccessTokenFormat),
+		IDTokenSignedResponseAlg: client.IDTokenSignedResponseAlg,
+		PostLogoutRedirectURIs:   postLogoutRedirectURLs,
+		BackchannelLogoutURI:     backchannelLogoutURL,
+		ClientCredentialsScopes:  clientCredentialsScopes,
+		TokenExchange:            client.TokenExchange,
+		PasswordGrant:            client.PasswordGrant,
 	}, nil
 }
 
@@ -96,20 +122,36 @@ func (c *clientRepository) FindByUser(ctx context.Context, userID ulid.ULID) ([]
 	return repoClients(clients)
 }
 
-func (c *clientRepository) Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL, secretHash []byte) (*repos.ClientModel, error) {
+func (c *clientRepository) Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, secretHash []byte, clientType repos.ClientType, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) (*repos.ClientModel, error) {
 	redirectURIsJSON, err := urlsToJSON(redirectURIs)
 	if err != nil {
 		return nil, err
 	}
+	postLogoutRedirectURIsJSON, err := urlsToJSON(postLogoutRedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+	var backchannelLogoutURIStr string
+	if backchannelLogoutURI != nil {
+		backchannelLogoutURIStr = backchannelLogoutURI.String()
+	}
 	client, err := c.db.CreateClient(ctx, db.CreateClientParams{
-		ID:           ulid.Make().String(),
-		CreatedAt:    time.Now().Unix(),
-		Name:         name,
-		Description:  description,
-		Website:      website.String(),
-		RedirectUris: redirectURIsJSON,
-		SecretHash:   secretHash,
-		UserID:       userID.String(),
+		ID:                       ulid.Make().String(),
+		CreatedAt:                time.Now().Unix(),
+		Name:                     name,
+		Description:              description,
+		Website:                  website.String(),
+		RedirectUris:             redirectURIsJSON,
+		SecretHash:               secretHash,
+		UserID:                   userID.String(),
+		ClientType:               string(clientType),
+		AccessTokenFormat:        string(accessTokenFormat),
+		IDTokenSignedResponseAlg: idTokenSignedResponseAlg,
+		PostLogoutRedirectUris:   postLogoutRedirectURIsJSON,
+		BackchannelLogoutUri:     backchannelLogoutURIStr,
+		ClientCredentialsScopes:  strings.Join(clientCredentialsScopes, ","),
+		TokenExchange:            tokenExchange,
+		PasswordGrant:            passwordGrant,
 	})
 	if err != nil {
 		return nil, repoErr("create client: %w", err)
@@ -117,18 +159,33 @@ func (c *clientRepository) Create(ctx context.Context, userID ulid.ULID, name, d
 	return repoClient(client)
 }
 
-func (c *clientRepository) Update(ctx context.Context, userID, id ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) (*repos.ClientModel, error) {
+func (c *clientRepository) Update(ctx context.Context, userID, id ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) (*repos.ClientModel, error) {
 	redirectURIsJSON, err := urlsToJSON(redirectURIs)
 	if err != nil {
 		return nil, err
 	}
+	postLogoutRedirectURIsJSON, err := urlsToJSON(postLogoutRedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+	var backchannelLogoutURIStr string
+	if backchannelLogoutURI != nil {
+		backchannelLogoutURIStr = backchannelLogoutURI.String()
+	}
 	client, err := c.db.UpdateClient(ctx, db.UpdateClientParams{
-		UserID:       userID.String(),
-		ID:           id.String(),
-		Name:         name,
-		Description:  description,
-		Website:      website.String(),
-		RedirectUris: redirectURIsJSON,
+		UserID:                   userID.String(),
+		ID:                       id.String(),
+		Name:                     name,
+		Description:              description,
+		Website:                  website.String(),
+		RedirectUris:             redirectURIsJSON,
+		AccessTokenFormat:        string(accessTokenFormat),
+		IDTokenSignedResponseAlg: idTokenSignedResponseAlg,
+		PostLogoutRedirectUris:   postLogoutRedirectURIsJSON,
+		BackchannelLogoutUri:     backchannelLogoutURIStr,
+		ClientCredentialsScopes:  strings.Join(clientCredentialsScopes, ","),
+		TokenExchange:            tokenExchange,
+		PasswordGrant:            passwordGrant,
 	})
 	if err != nil {
 		return nil, repoErr("update client: %w", err)
@@ -152,3 +209,122 @@ func (c *clientRepository) Delete(ctx context.Context, userID, id ulid.ULID) err
 	})
 	return repoErrResult("delete client: %w", result, err)
 }
+
+func repoScope(scope db.Scope) (*repos.ScopeModel, error) {
+	id, err := ulid.Parse(scope.ID)
+	if err != nil {
+		return nil, err
+	}
+	clientID, err := ulid.Parse(scope.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	var descriptions map[string]string
+	err = json.Unmarshal(scope.Descriptions, &descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("decode scope descriptions: %w", err)
+	}
+	return &repos.ScopeModel{
+		BaseModel: repos.BaseModel{
+			ID:        id,
+			CreatedAt: time.Unix(scope.CreatedAt, 0),
+		},
+		ClientID:     clientID,
+		Name:         scope.Name,
+		Audience:     scope.Audience,
+		Descriptions: descriptions,
+	}, nil
+}
+
+func (c *clientRepository) FindScope(ctx context.Context, clientID, id ulid.ULID) (*repos.ScopeModel, error) {
+	scope, err := c.db.FindScope(ctx, db.FindScopeParams{
+		ClientID: clientID.String(),
+		ID:       id.String(),
+	})
+	if err != nil {
+		return nil, repoErr("find scope: %w", err)
+	}
+	return repoScope(scope)
+}
+
+func (c *clientRepository) FindScopeByName(ctx context.Context, name string) (*repos.ScopeModel, error) {
+	scope, err := c.db.FindScopeByName(ctx, name)
+	if err != nil {
+		return nil, repoErr("find scope by name: %w", err)
+	}
+	return repoScope(scope)
+}
+
+func (c *clientRepository) FindScopesByClient(ctx context.Context, clientID ulid.ULID) ([]*repos.ScopeModel, error) {
+	scopes, err := c.db.FindScopesByClient(ctx, clientID.String())
+	if err != nil {
+		return nil, repoErr("find scopes by client: %w", err)
+	}
+	repoScopes := make([]*repos.ScopeModel, len(scopes))
+	for i, s := range scopes {
+		repoScopes[i], err = repoScope(s)
+		if err != nil {
+			return nil, fmt.Errorf("find scopes by client: %w", err)
+		}
+	}
+	return repoScopes, nil
+}
+
+func (c *clientRepository) FindScopesByAudience(ctx context.Context, audience string) ([]*repos.ScopeModel, error) {
+	scopes, err := c.db.FindScopesByAudience(ctx, audience)
+	if err != nil {
+		return nil, repoErr("find scopes by audience: %w", err)
+	}
+	repoScopes := make([]*repos.ScopeModel, len(scopes))
+	for i, s := range scopes {
+		repoScopes[i], err = repoScope(s)
+		if err != nil {
+			return nil, fmt.Errorf("find scopes by audience: %w", err)
+		}
+	}
+	return repoScopes, nil
+}
+
+func (c *clientRepository) CreateScope(ctx context.Context, clientID ulid.ULID, name, audience string, descriptions map[string]string) (*repos.ScopeModel, error) {
+	descriptionsJSON, err := json.Marshal(descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("create scope: encode descriptions: %w", err)
+	}
+	scope, err := c.db.CreateScope(ctx, db.CreateScopeParams{
+		ID:           ulid.Make().String(),
+		CreatedAt:    time.Now().Unix(),
+		ClientID:     clientID.String(),
+		Name:         name,
+		Audience:     audience,
+		Descriptions: descriptionsJSON,
+	})
+	if err != nil {
+		return nil, repoErr("create scope: %w", err)
+	}
+	return repoScope(scope)
+}
+
+func (c *clientRepository) UpdateScope(ctx context.Context, clientID, id ulid.ULID, audience string, descriptions map[string]string) (*repos.ScopeModel, error) {
+	descriptionsJSON, err := json.Marshal(descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("update scope: encode descriptions: %w", err)
+	}
+	scope, err := c.db.UpdateScope(ctx, db.UpdateScopeParams{
+		Audience:     audience,
+		Descriptions: descriptionsJSON,
+		ClientID:     clientID.String(),
+		ID:           id.String(),
+	})
+	if err != nil {
+		return nil, repoErr("update scope: %w", err)
+	}
+	return repoScope(scope)
+}
+
+func (c *clientRepository) DeleteScope(ctx context.Context, clientID, id ulid.ULID) error {
+	result, err := c.db.DeleteScope(ctx, db.DeleteScopeParams{
+		ClientID: clientID.String(),
+		ID:       id.String(),
+	})
+	return repoErrResult("delete scope: %w", result, err)
+}
@@ -13,21 +13,29 @@ import (
 
 const createClient = `-- name: CreateClient :one
 INSERT INTO clients (
-  id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+) RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 `
 
 type CreateClientParams struct {
-	ID           string
-	CreatedAt    int64
-	Name         string
-	Description  string
-	Website      string
-	RedirectUris []byte
-	SecretHash   []byte
-	UserID       string
+	ID                       string
+	CreatedAt                int64
+	Name                     string
+	Description              string
+	Website                  string
+	RedirectUris             []byte
+	SecretHash               []byte
+	UserID                   string
+	ClientType               string
+	AccessTokenFormat        string
+	IDTokenSignedResponseAlg string
+	PostLogoutRedirectUris   []byte
+	BackchannelLogoutUri     string
+	ClientCredentialsScopes  string
+	TokenExchange            bool
+	PasswordGrant            bool
 }
 
 func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Client, error) {
@@ -40,6 +48,14 @@ func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Cli
 		arg.RedirectUris,
 		arg.SecretHash,
 		arg.UserID,
+		arg.ClientType,
+		arg.AccessTokenFormat,
+		arg.IDTokenSignedResponseAlg,
+		arg.PostLogoutRedirectUris,
+		arg.BackchannelLogoutUri,
+		arg.ClientCredentialsScopes,
+		arg.TokenExchange,
+		arg.PasswordGrant,
 	)
 	var i Client
 	err := row.Scan(
@@ -51,6 +67,14 @@ func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Cli
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
@@ -69,7 +93,7 @@ func (q *Queries) DeleteClient(ctx context.Context, arg DeleteClientParams) (pgc
 }
 
 const findClient = `-- name: FindClient :one
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE id = $1
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE id = $1
 `
 
 func (q *Queries) FindClient(ctx context.Context, id string) (Client, error) {
@@ -84,12 +108,20 @@ func (q *Queries) FindClient(ctx context.Context, id string) (Client, error) {
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
 
 const findClientByUser = `-- name: FindClientByUser :many
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE user_id = $1
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE user_id = $1
 `
 
 func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client, error) {
@@ -110,6 +142,14 @@ func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client
 			&i.RedirectUris,
 			&i.SecretHash,
 			&i.UserID,
+			&i.ClientType,
+			&i.AccessTokenFormat,
+			&i.IDTokenSignedResponseAlg,
+			&i.PostLogoutRedirectUris,
+			&i.BackchannelLogoutUri,
+			&i.ClientCredentialsScopes,
+			&i.TokenExchange,
+			&i.PasswordGrant,
 		); err != nil {
 			return nil, err
 		}
@@ -122,7 +162,7 @@ func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client
 }
 
 const findClientByUserAndID = `-- name: FindClientByUserAndID :one
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE user_id = $1 AND id = $2
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE user_id = $1 AND id = $2
 `
 
 type FindClientByUserAndIDParams struct {
@@ -142,24 +182,39 @@ func (q *Queries) FindClientByUserAndID(ctx context.Context, arg FindClientByUse
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
 
 const updateClient = `-- name: UpdateClient :one
 UPDATE clients SET
-  name = $1, description = $2, website = $3, redirect_uris = $4
-WHERE user_id = $5 AND id = $6
-RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  name = $1, description = $2, website = $3, redirect_uris = $4, access_token_format = $5, id_token_signed_response_alg = $6, post_logout_redirect_uris = $7, backchannel_logout_uri = $8, client_credentials_scopes = $9, token_exchange = $10, password_grant = $11
+WHERE user_id = $12 AND id = $13
+RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 `
 
 type UpdateClientParams struct {
-	Name         string
-	Description  string
-	Website      string
-	RedirectUris []byte
-	UserID       string
-	ID           string
+	Name                     string
+	Description              string
+	Website                  string
+	RedirectUris             []byte
+	AccessTokenFormat        string
+	IDTokenSignedResponseAlg string
+	PostLogoutRedirectUris   []byte
+	BackchannelLogoutUri     string
+	ClientCredentialsScopes  string
+	TokenExchange            bool
+	PasswordGrant            bool
+	UserID                   string
+	ID                       string
 }
 
 func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Client, error) {
@@ -168,6 +223,13 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Cli
 		arg.Description,
 		arg.Website,
 		arg.RedirectUris,
+		arg.AccessTokenFormat,
+		arg.IDTokenSignedResponseAlg,
+		arg.PostLogoutRedirectUris,
+		arg.BackchannelLogoutUri,
+		arg.ClientCredentialsScopes,
+		arg.TokenExchange,
+		arg.PasswordGrant,
 		arg.UserID,
 		arg.ID,
 	)
@@ -181,6 +243,14 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Cli
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
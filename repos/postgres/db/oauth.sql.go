@@ -9,14 +9,15 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createOAuthToken = `-- name: CreateOAuthToken :one
 INSERT INTO oauth (
-  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used
+  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used, grant_id
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10
-) RETURNING created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11
+) RETURNING created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id
 `
 
 type CreateOAuthTokenParams struct {
@@ -25,11 +26,12 @@ type CreateOAuthTokenParams struct {
 	TokenHash   []byte
 	RedirectUri string
 	ClientID    string
-	UserID      string
+	UserID      pgtype.Text
 	Scopes      string
 	Data        []byte
 	Expires     int64
 	Used        bool
+	GrantID     string
 }
 
 func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenParams) (Oauth, error) {
@@ -44,6 +46,7 @@ func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenPara
 		arg.Data,
 		arg.Expires,
 		arg.Used,
+		arg.GrantID,
 	)
 	var i Oauth
 	err := row.Scan(
@@ -57,6 +60,7 @@ func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenPara
 		&i.Data,
 		&i.Expires,
 		&i.Used,
+		&i.GrantID,
 	)
 	return i, err
 }
@@ -87,7 +91,7 @@ DELETE FROM oauth WHERE (client_id = $1 AND user_id = $2) OR expires < $3
 
 type DeleteOAuthTokenByUserParams struct {
 	ClientID string
-	UserID   string
+	UserID   pgtype.Text
 	Now      int64
 }
 
@@ -96,6 +100,21 @@ func (q *Queries) DeleteOAuthTokenByUser(ctx context.Context, arg DeleteOAuthTok
 	return err
 }
 
+const deleteOAuthTokensByGrant = `-- name: DeleteOAuthTokensByGrant :exec
+DELETE FROM oauth WHERE (client_id = $1 AND grant_id = $2) OR expires < $3
+`
+
+type DeleteOAuthTokensByGrantParams struct {
+	ClientID string
+	GrantID  string
+	Now      int64
+}
+
+func (q *Queries) DeleteOAuthTokensByGrant(ctx context.Context, arg DeleteOAuthTokensByGrantParams) error {
+	_, err := q.db.Exec(ctx, deleteOAuthTokensByGrant, arg.ClientID, arg.GrantID, arg.Now)
+	return err
+}
+
 const findOAuthPermissions = `-- name: FindOAuthPermissions :one
 SELECT created_at, client_id, user_id, scopes FROM permissions WHERE client_id = $1 AND user_id = $2
 `
@@ -117,8 +136,37 @@ func (q *Queries) FindOAuthPermissions(ctx context.Context, arg FindOAuthPermiss
 	return i, err
 }
 
+const findOAuthPermissionsByUser = `-- name: FindOAuthPermissionsByUser :many
+SELECT created_at, client_id, user_id, scopes FROM permissions WHERE user_id = $1
+`
+
+func (q *Queries) FindOAuthPermissionsByUser(ctx context.Context, userID string) ([]Permission, error) {
+	rows, err := q.db.Query(ctx, findOAuthPermissionsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Permission
+	for rows.Next() {
+		var i Permission
+		if err := rows.Scan(
+			&i.CreatedAt,
+			&i.ClientID,
+			&i.UserID,
+			&i.Scopes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findOAuthToken = `-- name: FindOAuthToken :one
-SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used FROM oauth WHERE category = $1 AND token_hash = $2 AND expires > $3
+SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id FROM oauth WHERE category = $1 AND token_hash = $2 AND expires > $3
 `
 
 type FindOAuthTokenParams struct {
@@ -141,6 +189,7 @@ func (q *Queries) FindOAuthToken(ctx context.Context, arg FindOAuthTokenParams)
 		&i.Data,
 		&i.Expires,
 		&i.Used,
+		&i.GrantID,
 	)
 	return i, err
 }
@@ -190,8 +239,30 @@ func (q *Queries) SetOAuthPermissions(ctx context.Context, arg SetOAuthPermissio
 	return i, err
 }
 
+const updateOAuthToken = `-- name: UpdateOAuthToken :execresult
+UPDATE oauth SET user_id = $1, data = $2 WHERE client_id = $3 AND category = $4 AND token_hash = $5
+`
+
+type UpdateOAuthTokenParams struct {
+	UserID    pgtype.Text
+	Data      []byte
+	ClientID  string
+	Category  string
+	TokenHash []byte
+}
+
+func (q *Queries) UpdateOAuthToken(ctx context.Context, arg UpdateOAuthTokenParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, updateOAuthToken,
+		arg.UserID,
+		arg.Data,
+		arg.ClientID,
+		arg.Category,
+		arg.TokenHash,
+	)
+}
+
 const useOAuthToken = `-- name: UseOAuthToken :execresult
-UPDATE oauth SET used = TRUE WHERE client_id = $1 AND category = $2 AND token_hash = $3
+UPDATE oauth SET used = TRUE WHERE client_id = $1 AND category = $2 AND token_hash = $3 AND used = FALSE
 `
 
 type UseOAuthTokenParams struct {
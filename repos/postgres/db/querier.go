@@ -11,35 +11,66 @@ import (
 )
 
 type Querier interface {
+	AddGatewayGroupMember(ctx context.Context, arg AddGatewayGroupMemberParams) (pgconn.CommandTag, error)
 	CheckRemember2FAToken(ctx context.Context, arg CheckRemember2FATokenParams) (bool, error)
 	CommitSession(ctx context.Context, arg CommitSessionParams) error
 	CountRecoveryCodes(ctx context.Context, userID string) (int64, error)
+	CreateAppPassword(ctx context.Context, arg CreateAppPasswordParams) (AppPassword, error)
+	CreateBackchannelLogout(ctx context.Context, arg CreateBackchannelLogoutParams) error
 	CreateChangeEmailRequest(ctx context.Context, arg CreateChangeEmailRequestParams) (pgconn.CommandTag, error)
 	CreateClient(ctx context.Context, arg CreateClientParams) (Client, error)
+	CreateGatewayDomain(ctx context.Context, arg CreateGatewayDomainParams) (GatewayDomain, error)
+	CreateGatewayGroup(ctx context.Context, arg CreateGatewayGroupParams) (GatewayGroup, error)
+	CreateJWTKey(ctx context.Context, arg CreateJWTKeyParams) (JwtKey, error)
 	CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenParams) (Oauth, error)
 	CreatePasskey(ctx context.Context, arg CreatePasskeyParams) (pgconn.CommandTag, error)
 	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
 	CreateRemember2FAToken(ctx context.Context, arg CreateRemember2FATokenParams) error
+	CreateScope(ctx context.Context, arg CreateScopeParams) (Scope, error)
 	CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteAppPassword(ctx context.Context, arg DeleteAppPasswordParams) (pgconn.CommandTag, error)
+	DeleteBackchannelLogout(ctx context.Context, id string) (pgconn.CommandTag, error)
 	DeleteClient(ctx context.Context, arg DeleteClientParams) (pgconn.CommandTag, error)
+	DeleteGatewayDomain(ctx context.Context, id string) (pgconn.CommandTag, error)
+	DeleteGatewayGroup(ctx context.Context, id string) (pgconn.CommandTag, error)
 	DeleteOAuthToken(ctx context.Context, arg DeleteOAuthTokenParams) (pgconn.CommandTag, error)
 	DeleteOAuthTokenByUser(ctx context.Context, arg DeleteOAuthTokenByUserParams) error
+	DeleteOAuthTokensByGrant(ctx context.Context, arg DeleteOAuthTokensByGrantParams) error
 	DeletePasskey(ctx context.Context, arg DeletePasskeyParams) (pgconn.CommandTag, error)
 	DeleteRecoveryCode(ctx context.Context, arg DeleteRecoveryCodeParams) (pgconn.CommandTag, error)
 	DeleteRecoveryCodes(ctx context.Context, userID string) (pgconn.CommandTag, error)
 	DeleteRemember2FAToken(ctx context.Context, arg DeleteRemember2FATokenParams) (pgconn.CommandTag, error)
 	DeleteRemember2FATokens(ctx context.Context, arg DeleteRemember2FATokensParams) (pgconn.CommandTag, error)
+	DeleteRetiredJWTKeys(ctx context.Context, retiredAt int64) error
+	DeleteScope(ctx context.Context, arg DeleteScopeParams) (pgconn.CommandTag, error)
 	DeleteSession(ctx context.Context, token string) error
 	DeleteToken(ctx context.Context, arg DeleteTokenParams) (pgconn.CommandTag, error)
 	DeleteUser(ctx context.Context, id string) (pgconn.CommandTag, error)
+	FindAllGatewayGroupMembers(ctx context.Context) ([]GatewayGroupMember, error)
+	FindAppPassword(ctx context.Context, arg FindAppPasswordParams) (AppPassword, error)
+	FindAppPasswordByHash(ctx context.Context, arg FindAppPasswordByHashParams) (AppPassword, error)
+	FindAppPasswords(ctx context.Context, userID string) ([]AppPassword, error)
 	FindClient(ctx context.Context, id string) (Client, error)
 	FindClientByUser(ctx context.Context, userID string) ([]Client, error)
 	FindClientByUserAndID(ctx context.Context, arg FindClientByUserAndIDParams) (Client, error)
+	FindDueBackchannelLogouts(ctx context.Context, nextAttempt int64) ([]BackchannelLogout, error)
+	FindGatewayConfigFile(ctx context.Context) (GatewayConfigFile, error)
+	FindGatewayDomain(ctx context.Context, id string) (GatewayDomain, error)
+	FindGatewayDomains(ctx context.Context) ([]GatewayDomain, error)
+	FindGatewayGroup(ctx context.Context, id string) (GatewayGroup, error)
+	FindGatewayGroupMembers(ctx context.Context, groupID string) ([]GatewayGroupMember, error)
+	FindGatewayGroups(ctx context.Context) ([]GatewayGroup, error)
+	FindJWTKeys(ctx context.Context, retiredAt int64) ([]JwtKey, error)
 	FindOAuthPermissions(ctx context.Context, arg FindOAuthPermissionsParams) (Permission, error)
+	FindOAuthPermissionsByUser(ctx context.Context, userID string) ([]Permission, error)
 	FindOAuthToken(ctx context.Context, arg FindOAuthTokenParams) (Oauth, error)
 	FindPasskey(ctx context.Context, arg FindPasskeyParams) (Passkey, error)
 	FindPasskeys(ctx context.Context, userID string) ([]Passkey, error)
+	FindScope(ctx context.Context, arg FindScopeParams) (Scope, error)
+	FindScopeByName(ctx context.Context, name string) (Scope, error)
+	FindScopesByAudience(ctx context.Context, audience string) ([]Scope, error)
+	FindScopesByClient(ctx context.Context, clientID string) ([]Scope, error)
 	FindSession(ctx context.Context, arg FindSessionParams) ([]byte, error)
 	FindSessions(ctx context.Context, now int64) ([]FindSessionsRow, error)
 	FindToken(ctx context.Context, arg FindTokenParams) (Token, error)
@@ -47,23 +78,32 @@ type Querier interface {
 	FindUser(ctx context.Context, id string) (User, error)
 	FindUserByChangeEmailToken(ctx context.Context, arg FindUserByChangeEmailTokenParams) (User, error)
 	FindUserByEmail(ctx context.Context, email string) (User, error)
+	FindUserSessions(ctx context.Context, arg FindUserSessionsParams) ([]FindUserSessionsRow, error)
 	FindUsers(ctx context.Context) ([]User, error)
 	GetJWTKeys(ctx context.Context) (RsaKey, error)
 	GetOTP(ctx context.Context, id string) (GetOTPRow, error)
 	GetUserPasswordHash(ctx context.Context, id string) ([]byte, error)
-	InsertJWTKeys(ctx context.Context, arg InsertJWTKeysParams) error
+	RemoveGatewayGroupMember(ctx context.Context, arg RemoveGatewayGroupMemberParams) (pgconn.CommandTag, error)
+	RetireJWTKeys(ctx context.Context, arg RetireJWTKeysParams) error
 	RevokeOAuthPermissions(ctx context.Context, arg RevokeOAuthPermissionsParams) (pgconn.CommandTag, error)
+	SetGatewayConfigFile(ctx context.Context, arg SetGatewayConfigFileParams) error
 	SetOAuthPermissions(ctx context.Context, arg SetOAuthPermissionsParams) (Permission, error)
 	SetOTPActive(ctx context.Context, arg SetOTPActiveParams) (pgconn.CommandTag, error)
 	UpdateAdminStatus(ctx context.Context, arg UpdateAdminStatusParams) (pgconn.CommandTag, error)
+	UpdateAppPasswordLastUsed(ctx context.Context, arg UpdateAppPasswordLastUsedParams) (pgconn.CommandTag, error)
+	UpdateBackchannelLogoutAttempt(ctx context.Context, arg UpdateBackchannelLogoutAttemptParams) (pgconn.CommandTag, error)
 	UpdateClient(ctx context.Context, arg UpdateClientParams) (Client, error)
 	UpdateClientSecret(ctx context.Context, arg UpdateClientSecretParams) (pgconn.CommandTag, error)
 	UpdateEmail(ctx context.Context, arg UpdateEmailParams) (string, error)
 	UpdateEmailConfirmed(ctx context.Context, arg UpdateEmailConfirmedParams) (pgconn.CommandTag, error)
+	UpdateGatewayDomain(ctx context.Context, arg UpdateGatewayDomainParams) (GatewayDomain, error)
+	UpdateGatewayGroup(ctx context.Context, arg UpdateGatewayGroupParams) (GatewayGroup, error)
+	UpdateOAuthToken(ctx context.Context, arg UpdateOAuthTokenParams) (pgconn.CommandTag, error)
 	UpdateOTP(ctx context.Context, arg UpdateOTPParams) (pgconn.CommandTag, error)
 	UpdatePasskey(ctx context.Context, arg UpdatePasskeyParams) (pgconn.CommandTag, error)
 	UpdatePasskeyCredential(ctx context.Context, arg UpdatePasskeyCredentialParams) (pgconn.CommandTag, error)
 	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (pgconn.CommandTag, error)
+	UpdateScope(ctx context.Context, arg UpdateScopeParams) (Scope, error)
 	UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (pgconn.CommandTag, error)
 	UseOAuthToken(ctx context.Context, arg UseOAuthTokenParams) (pgconn.CommandTag, error)
 }
@@ -11,21 +11,22 @@ import (
 
 const commitSession = `-- name: CommitSession :exec
 INSERT INTO sessions (
-  token, data, expires
+  token, data, expires, user_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
-ON CONFLICT(token) DO UPDATE SET token = $1, data = $2, expires = $3
+ON CONFLICT(token) DO UPDATE SET token = $1, data = $2, expires = $3, user_id = $4
 `
 
 type CommitSessionParams struct {
 	Token   string
 	Data    []byte
 	Expires int64
+	UserID  string
 }
 
 func (q *Queries) CommitSession(ctx context.Context, arg CommitSessionParams) error {
-	_, err := q.db.Exec(ctx, commitSession, arg.Token, arg.Data, arg.Expires)
+	_, err := q.db.Exec(ctx, commitSession, arg.Token, arg.Data, arg.Expires, arg.UserID)
 	return err
 }
 
@@ -82,3 +83,38 @@ func (q *Queries) FindSessions(ctx context.Context, now int64) ([]FindSessionsRo
 	}
 	return items, nil
 }
+
+const findUserSessions = `-- name: FindUserSessions :many
+SELECT token,data,expires FROM sessions WHERE user_id = $1 AND expires > $2
+`
+
+type FindUserSessionsParams struct {
+	UserID string
+	Now    int64
+}
+
+type FindUserSessionsRow struct {
+	Token   string
+	Data    []byte
+	Expires int64
+}
+
+func (q *Queries) FindUserSessions(ctx context.Context, arg FindUserSessionsParams) ([]FindUserSessionsRow, error) {
+	rows, err := q.db.Query(ctx, findUserSessions, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindUserSessionsRow
+	for rows.Next() {
+		var i FindUserSessionsRow
+		if err := rows.Scan(&i.Token, &i.Data, &i.Expires); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
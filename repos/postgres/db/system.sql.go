@@ -9,6 +9,86 @@ import (
 	"context"
 )
 
+const createJWTKey = `-- name: CreateJWTKey :one
+INSERT INTO jwt_keys (
+  id,created_at,algorithm,private,public,active_at,retired_at
+) VALUES (
+  $1,$2,$3,$4,$5,$6,0
+) RETURNING id, created_at, algorithm, private, public, active_at, retired_at
+`
+
+type CreateJWTKeyParams struct {
+	ID        string
+	CreatedAt int64
+	Algorithm string
+	Private   []byte
+	Public    []byte
+	ActiveAt  int64
+}
+
+func (q *Queries) CreateJWTKey(ctx context.Context, arg CreateJWTKeyParams) (JwtKey, error) {
+	row := q.db.QueryRow(ctx, createJWTKey,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Algorithm,
+		arg.Private,
+		arg.Public,
+		arg.ActiveAt,
+	)
+	var i JwtKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Algorithm,
+		&i.Private,
+		&i.Public,
+		&i.ActiveAt,
+		&i.RetiredAt,
+	)
+	return i, err
+}
+
+const deleteRetiredJWTKeys = `-- name: DeleteRetiredJWTKeys :exec
+DELETE FROM jwt_keys WHERE retired_at != 0 AND retired_at < $1
+`
+
+func (q *Queries) DeleteRetiredJWTKeys(ctx context.Context, retiredAt int64) error {
+	_, err := q.db.Exec(ctx, deleteRetiredJWTKeys, retiredAt)
+	return err
+}
+
+const findJWTKeys = `-- name: FindJWTKeys :many
+SELECT id, created_at, algorithm, private, public, active_at, retired_at FROM jwt_keys WHERE retired_at = 0 OR retired_at > $1 ORDER BY active_at
+`
+
+func (q *Queries) FindJWTKeys(ctx context.Context, retiredAt int64) ([]JwtKey, error) {
+	rows, err := q.db.Query(ctx, findJWTKeys, retiredAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JwtKey
+	for rows.Next() {
+		var i JwtKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Algorithm,
+			&i.Private,
+			&i.Public,
+			&i.ActiveAt,
+			&i.RetiredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getJWTKeys = `-- name: GetJWTKeys :one
 SELECT name, created_at, private, public FROM rsa_keys WHERE name = 'jwt_secret'
 `
@@ -25,21 +105,17 @@ func (q *Queries) GetJWTKeys(ctx context.Context) (RsaKey, error) {
 	return i, err
 }
 
-const insertJWTKeys = `-- name: InsertJWTKeys :exec
-INSERT INTO rsa_keys (
-  name,created_at,private,public
-) VALUES (
-  'jwt_secret',$1,$2,$3
-)
+const retireJWTKeys = `-- name: RetireJWTKeys :exec
+UPDATE jwt_keys SET retired_at = $1 WHERE algorithm = $2 AND id != $3 AND retired_at = 0
 `
 
-type InsertJWTKeysParams struct {
-	CreatedAt int64
-	Private   []byte
-	Public    []byte
+type RetireJWTKeysParams struct {
+	RetiredAt int64
+	Algorithm string
+	ID        string
 }
 
-func (q *Queries) InsertJWTKeys(ctx context.Context, arg InsertJWTKeysParams) error {
-	_, err := q.db.Exec(ctx, insertJWTKeys, arg.CreatedAt, arg.Private, arg.Public)
+func (q *Queries) RetireJWTKeys(ctx context.Context, arg RetireJWTKeysParams) error {
+	_, err := q.db.Exec(ctx, retireJWTKeys, arg.RetiredAt, arg.Algorithm, arg.ID)
 	return err
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/juho05/h-id/repos"
 	"github.com/juho05/h-id/repos/postgres/db"
+	"github.com/oklog/ulid/v2"
 )
 
 type sessionRepository struct {
@@ -55,10 +56,19 @@ func (s *sessionRepository) FindCtx(ctx context.Context, token string) ([]byte,
 }
 
 func (s *sessionRepository) CommitCtx(ctx context.Context, token string, data []byte, expires time.Time) error {
+	return s.CommitUserCtx(ctx, token, data, expires, nil)
+}
+
+func (s *sessionRepository) CommitUserCtx(ctx context.Context, token string, data []byte, expires time.Time, userID *ulid.ULID) error {
+	var user string
+	if userID != nil {
+		user = userID.String()
+	}
 	return s.db.CommitSession(ctx, db.CommitSessionParams{
 		Token:   token,
 		Data:    data,
 		Expires: expires.Unix(),
+		UserID:  user,
 	})
 }
 
@@ -73,3 +83,30 @@ func (s *sessionRepository) AllCtx(ctx context.Context) (map[string][]byte, erro
 	}
 	return sessions, nil
 }
+
+func (s *sessionRepository) FindByUserCtx(ctx context.Context, userID ulid.ULID) ([]*repos.SessionModel, error) {
+	return s.findByUser(ctx, userID.String())
+}
+
+func (s *sessionRepository) FindUnindexedCtx(ctx context.Context) ([]*repos.SessionModel, error) {
+	return s.findByUser(ctx, repos.UnindexedSessionUserID)
+}
+
+func (s *sessionRepository) findByUser(ctx context.Context, userID string) ([]*repos.SessionModel, error) {
+	sessionRows, err := s.db.FindUserSessions(ctx, db.FindUserSessionsParams{
+		UserID: userID,
+		Now:    time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*repos.SessionModel, len(sessionRows))
+	for i, row := range sessionRows {
+		sessions[i] = &repos.SessionModel{
+			Token:   row.Token,
+			Data:    row.Data,
+			Expires: time.Unix(row.Expires, 0),
+		}
+	}
+	return sessions, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-webauthn/webauthn/webauthn"
@@ -93,6 +94,35 @@ func repoPasskey(passkey db.Passkey) (*repos.Passkey, error) {
 	}, nil
 }
 
+func repoAppPassword(appPassword db.AppPassword) (*repos.AppPassword, error) {
+	id, err := ulid.Parse(appPassword.ID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := ulid.Parse(appPassword.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var scopes []string
+	if appPassword.Scopes != "" {
+		scopes = strings.Split(appPassword.Scopes, ",")
+	}
+	var lastUsed time.Time
+	if appPassword.LastUsed != 0 {
+		lastUsed = time.Unix(appPassword.LastUsed, 0)
+	}
+	return &repos.AppPassword{
+		BaseModel: repos.BaseModel{
+			ID:        id,
+			CreatedAt: time.Unix(appPassword.CreatedAt, 0),
+		},
+		Name:     appPassword.Name,
+		UserID:   userID,
+		Scopes:   scopes,
+		LastUsed: lastUsed,
+	}, nil
+}
+
 func (u *userRepository) Find(ctx context.Context, id ulid.ULID) (*repos.UserModel, error) {
 	user, err := u.db.FindUser(ctx, id.String())
 	if err != nil {
@@ -399,6 +429,76 @@ func (u *userRepository) DeletePasskey(ctx context.Context, userID, id ulid.ULID
 	return repoErrResult("delete passkey: %w", res, err)
 }
 
+func (u *userRepository) CreateAppPassword(ctx context.Context, userID ulid.ULID, name string, scopes []string, passwordHash []byte) (*repos.AppPassword, error) {
+	appPassword, err := u.db.CreateAppPassword(ctx, db.CreateAppPasswordParams{
+		ID:           ulid.Make().String(),
+		CreatedAt:    time.Now().Unix(),
+		UserID:       userID.String(),
+		Name:         name,
+		Scopes:       strings.Join(scopes, ","),
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return nil, repoErr("create app password: %w", err)
+	}
+	return repoAppPassword(appPassword)
+}
+
+func (u *userRepository) GetAppPasswords(ctx context.Context, userID ulid.ULID) ([]*repos.AppPassword, error) {
+	appPasswords, err := u.db.FindAppPasswords(ctx, userID.String())
+	if err != nil {
+		return nil, repoErr("get app passwords: %w", err)
+	}
+	repoAppPasswords := make([]*repos.AppPassword, len(appPasswords))
+	for i, a := range appPasswords {
+		ra, err := repoAppPassword(a)
+		if err != nil {
+			return nil, fmt.Errorf("get app passwords: %w", err)
+		}
+		repoAppPasswords[i] = ra
+	}
+	return repoAppPasswords, nil
+}
+
+func (u *userRepository) GetAppPassword(ctx context.Context, userID, id ulid.ULID) (*repos.AppPassword, error) {
+	appPassword, err := u.db.FindAppPassword(ctx, db.FindAppPasswordParams{
+		UserID: userID.String(),
+		ID:     id.String(),
+	})
+	if err != nil {
+		return nil, repoErr("get app password: %w", err)
+	}
+	return repoAppPassword(appPassword)
+}
+
+func (u *userRepository) FindAppPasswordByHash(ctx context.Context, userID ulid.ULID, passwordHash []byte) (*repos.AppPassword, error) {
+	appPassword, err := u.db.FindAppPasswordByHash(ctx, db.FindAppPasswordByHashParams{
+		UserID:       userID.String(),
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return nil, repoErr("find app password by hash: %w", err)
+	}
+	return repoAppPassword(appPassword)
+}
+
+func (u *userRepository) UpdateAppPasswordLastUsed(ctx context.Context, userID, id ulid.ULID) error {
+	res, err := u.db.UpdateAppPasswordLastUsed(ctx, db.UpdateAppPasswordLastUsedParams{
+		LastUsed: time.Now().Unix(),
+		UserID:   userID.String(),
+		ID:       id.String(),
+	})
+	return repoErrResult("update app password last used: %w", res, err)
+}
+
+func (u *userRepository) DeleteAppPassword(ctx context.Context, userID, id ulid.ULID) error {
+	res, err := u.db.DeleteAppPassword(ctx, db.DeleteAppPasswordParams{
+		UserID: userID.String(),
+		ID:     id.String(),
+	})
+	return repoErrResult("delete app password: %w", res, err)
+}
+
 func (u *userRepository) UpdateAdminStatus(ctx context.Context, userID ulid.ULID, isAdmin bool) error {
 	res, err := u.db.UpdateAdminStatus(ctx, db.UpdateAdminStatusParams{
 		ID:    userID.String(),
@@ -1,11 +1,16 @@
 package repos
 
 import (
+	"context"
 	"time"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/oklog/ulid/v2"
 )
 
+// UnindexedSessionUserID is the user ID of sessions that were stored before sessions were indexed by user.
+const UnindexedSessionUserID = "unindexed"
+
 type SessionModel struct {
 	Token   string
 	Data    []byte
@@ -17,4 +22,12 @@ type SessionRepository interface {
 	scs.CtxStore
 	scs.IterableStore
 	scs.IterableCtxStore
+
+	// CommitUserCtx is like CommitCtx but additionally indexes the session by the ID of the authenticated user.
+	// userID is nil for sessions without an authenticated user.
+	CommitUserCtx(ctx context.Context, token string, data []byte, expires time.Time, userID *ulid.ULID) error
+	// FindByUserCtx returns all unexpired sessions of a user.
+	FindByUserCtx(ctx context.Context, userID ulid.ULID) ([]*SessionModel, error)
+	// FindUnindexedCtx returns all unexpired sessions that were stored before sessions were indexed by user.
+	FindUnindexedCtx(ctx context.Context) ([]*SessionModel, error)
 }
@@ -12,21 +12,29 @@ import (
 
 const createClient = `-- name: CreateClient :one
 INSERT INTO clients (
-  id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?  
-) RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 `
 
 type CreateClientParams struct {
-	ID           string
-	CreatedAt    int64
-	Name         string
-	Description  string
-	Website      string
-	RedirectUris []byte
-	SecretHash   []byte
-	UserID       string
+	ID                       string
+	CreatedAt                int64
+	Name                     string
+	Description              string
+	Website                  string
+	RedirectUris             []byte
+	SecretHash               []byte
+	UserID                   string
+	ClientType               string
+	AccessTokenFormat        string
+	IDTokenSignedResponseAlg string
+	PostLogoutRedirectUris   []byte
+	BackchannelLogoutUri     string
+	ClientCredentialsScopes  string
+	TokenExchange            bool
+	PasswordGrant            bool
 }
 
 func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Client, error) {
@@ -39,6 +47,14 @@ func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Cli
 		arg.RedirectUris,
 		arg.SecretHash,
 		arg.UserID,
+		arg.ClientType,
+		arg.AccessTokenFormat,
+		arg.IDTokenSignedResponseAlg,
+		arg.PostLogoutRedirectUris,
+		arg.BackchannelLogoutUri,
+		arg.ClientCredentialsScopes,
+		arg.TokenExchange,
+		arg.PasswordGrant,
 	)
 	var i Client
 	err := row.Scan(
@@ -50,6 +66,14 @@ func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (Cli
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
@@ -68,7 +92,7 @@ func (q *Queries) DeleteClient(ctx context.Context, arg DeleteClientParams) (sql
 }
 
 const findClient = `-- name: FindClient :one
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE id = ?
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE id = ?
 `
 
 func (q *Queries) FindClient(ctx context.Context, id string) (Client, error) {
@@ -83,12 +107,20 @@ func (q *Queries) FindClient(ctx context.Context, id string) (Client, error) {
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
 
 const findClientByUser = `-- name: FindClientByUser :many
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE user_id = ?
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE user_id = ?
 `
 
 func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client, error) {
@@ -109,6 +141,14 @@ func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client
 			&i.RedirectUris,
 			&i.SecretHash,
 			&i.UserID,
+			&i.ClientType,
+			&i.AccessTokenFormat,
+			&i.IDTokenSignedResponseAlg,
+			&i.PostLogoutRedirectUris,
+			&i.BackchannelLogoutUri,
+			&i.ClientCredentialsScopes,
+			&i.TokenExchange,
+			&i.PasswordGrant,
 		); err != nil {
 			return nil, err
 		}
@@ -124,7 +164,7 @@ func (q *Queries) FindClientByUser(ctx context.Context, userID string) ([]Client
 }
 
 const findClientByUserAndID = `-- name: FindClientByUserAndID :one
-SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id FROM clients WHERE user_id = ? AND id = ?
+SELECT id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant FROM clients WHERE user_id = ? AND id = ?
 `
 
 type FindClientByUserAndIDParams struct {
@@ -144,24 +184,39 @@ func (q *Queries) FindClientByUserAndID(ctx context.Context, arg FindClientByUse
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
 
 const updateClient = `-- name: UpdateClient :one
 UPDATE clients SET
-  name = ?, description = ?, website = ?, redirect_uris = ?
+  name = ?, description = ?, website = ?, redirect_uris = ?, access_token_format = ?, id_token_signed_response_alg = ?, post_logout_redirect_uris = ?, backchannel_logout_uri = ?, client_credentials_scopes = ?, token_exchange = ?, password_grant = ?
 WHERE user_id = ? AND id = ?
-RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id
+RETURNING id, created_at, name, description, website, redirect_uris, secret_hash, user_id, client_type, access_token_format, id_token_signed_response_alg, post_logout_redirect_uris, backchannel_logout_uri, client_credentials_scopes, token_exchange, password_grant
 `
 
 type UpdateClientParams struct {
-	Name         string
-	Description  string
-	Website      string
-	RedirectUris []byte
-	UserID       string
-	ID           string
+	Name                     string
+	Description              string
+	Website                  string
+	RedirectUris             []byte
+	AccessTokenFormat        string
+	IDTokenSignedResponseAlg string
+	PostLogoutRedirectUris   []byte
+	BackchannelLogoutUri     string
+	ClientCredentialsScopes  string
+	TokenExchange            bool
+	PasswordGrant            bool
+	UserID                   string
+	ID                       string
 }
 
 func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Client, error) {
@@ -170,6 +225,13 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Cli
 		arg.Description,
 		arg.Website,
 		arg.RedirectUris,
+		arg.AccessTokenFormat,
+		arg.IDTokenSignedResponseAlg,
+		arg.PostLogoutRedirectUris,
+		arg.BackchannelLogoutUri,
+		arg.ClientCredentialsScopes,
+		arg.TokenExchange,
+		arg.PasswordGrant,
 		arg.UserID,
 		arg.ID,
 	)
@@ -183,6 +245,14 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Cli
 		&i.RedirectUris,
 		&i.SecretHash,
 		&i.UserID,
+		&i.ClientType,
+		&i.AccessTokenFormat,
+		&i.IDTokenSignedResponseAlg,
+		&i.PostLogoutRedirectUris,
+		&i.BackchannelLogoutUri,
+		&i.ClientCredentialsScopes,
+		&i.TokenExchange,
+		&i.PasswordGrant,
 	)
 	return i, err
 }
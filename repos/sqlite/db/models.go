@@ -8,15 +8,85 @@ import (
 	"database/sql"
 )
 
-type Client struct {
+type AppPassword struct {
 	ID           string
 	CreatedAt    int64
-	Name         string
-	Description  string
-	Website      string
-	RedirectUris []byte
-	SecretHash   []byte
 	UserID       string
+	Name         string
+	Scopes       string
+	PasswordHash []byte
+	LastUsed     int64
+}
+
+type BackchannelLogout struct {
+	ID          string
+	CreatedAt   int64
+	ClientID    string
+	UserID      string
+	Sid         string
+	Attempt     int64
+	NextAttempt int64
+}
+
+type Client struct {
+	ID                       string
+	CreatedAt                int64
+	Name                     string
+	Description              string
+	Website                  string
+	RedirectUris             []byte
+	SecretHash               []byte
+	UserID                   string
+	ClientType               string
+	AccessTokenFormat        string
+	IDTokenSignedResponseAlg string
+	PostLogoutRedirectUris   []byte
+	BackchannelLogoutUri     string
+	ClientCredentialsScopes  string
+	TokenExchange            bool
+	PasswordGrant            bool
+}
+
+type GatewayConfigFile struct {
+	ID        int64
+	AppliedAt int64
+	Hash      []byte
+	Data      []byte
+}
+
+type GatewayDomain struct {
+	ID          string
+	CreatedAt   int64
+	Domain      string
+	GroupIds    string
+	UserIds     string
+	Rules       []byte
+	MaxAuthAge  int64
+	Require2fa  bool
+	Headers     string
+	BearerScope string
+}
+
+type GatewayGroup struct {
+	ID        string
+	CreatedAt int64
+	Name      string
+}
+
+type GatewayGroupMember struct {
+	GroupID   string
+	UserID    string
+	CreatedAt int64
+}
+
+type JwtKey struct {
+	ID        string
+	CreatedAt int64
+	Algorithm string
+	Private   []byte
+	Public    []byte
+	ActiveAt  int64
+	RetiredAt int64
 }
 
 type Oauth struct {
@@ -25,11 +95,12 @@ type Oauth struct {
 	Category    string
 	TokenHash   []byte
 	RedirectUri string
-	UserID      string
+	UserID      sql.NullString
 	Scopes      string
 	Data        []byte
 	Expires     int64
 	Used        bool
+	GrantID     string
 }
 
 type Passkey struct {
@@ -68,10 +139,20 @@ type RsaKey struct {
 	Public    []byte
 }
 
+type Scope struct {
+	ID           string
+	CreatedAt    int64
+	ClientID     string
+	Name         string
+	Audience     string
+	Descriptions []byte
+}
+
 type Session struct {
 	Token   string
 	Data    []byte
 	Expires int64
+	UserID  string
 }
 
 type Token struct {
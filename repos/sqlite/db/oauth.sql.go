@@ -12,10 +12,10 @@ import (
 
 const createOAuthToken = `-- name: CreateOAuthToken :one
 INSERT INTO oauth (
-  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used
+  created_at, category, token_hash, redirect_uri, client_id, user_id, scopes, data, expires, used, grant_id
 ) VALUES (
-  ?,?,?,?,?,?,?,?,?,?
-) RETURNING created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used
+  ?,?,?,?,?,?,?,?,?,?,?
+) RETURNING created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id
 `
 
 type CreateOAuthTokenParams struct {
@@ -24,11 +24,12 @@ type CreateOAuthTokenParams struct {
 	TokenHash   []byte
 	RedirectUri string
 	ClientID    string
-	UserID      string
+	UserID      sql.NullString
 	Scopes      string
 	Data        []byte
 	Expires     int64
 	Used        bool
+	GrantID     string
 }
 
 func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenParams) (Oauth, error) {
@@ -43,6 +44,7 @@ func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenPara
 		arg.Data,
 		arg.Expires,
 		arg.Used,
+		arg.GrantID,
 	)
 	var i Oauth
 	err := row.Scan(
@@ -56,6 +58,7 @@ func (q *Queries) CreateOAuthToken(ctx context.Context, arg CreateOAuthTokenPara
 		&i.Data,
 		&i.Expires,
 		&i.Used,
+		&i.GrantID,
 	)
 	return i, err
 }
@@ -86,7 +89,7 @@ DELETE FROM oauth WHERE (client_id = ? AND user_id = ?) OR expires < ?3
 
 type DeleteOAuthTokenByUserParams struct {
 	ClientID string
-	UserID   string
+	UserID   sql.NullString
 	Now      int64
 }
 
@@ -95,6 +98,21 @@ func (q *Queries) DeleteOAuthTokenByUser(ctx context.Context, arg DeleteOAuthTok
 	return err
 }
 
+const deleteOAuthTokensByGrant = `-- name: DeleteOAuthTokensByGrant :exec
+DELETE FROM oauth WHERE (client_id = ? AND grant_id = ?) OR expires < ?3
+`
+
+type DeleteOAuthTokensByGrantParams struct {
+	ClientID string
+	GrantID  string
+	Now      int64
+}
+
+func (q *Queries) DeleteOAuthTokensByGrant(ctx context.Context, arg DeleteOAuthTokensByGrantParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuthTokensByGrant, arg.ClientID, arg.GrantID, arg.Now)
+	return err
+}
+
 const findOAuthPermissions = `-- name: FindOAuthPermissions :one
 SELECT created_at, client_id, user_id, scopes FROM permissions WHERE client_id = ? AND user_id = ?
 `
@@ -116,8 +134,40 @@ func (q *Queries) FindOAuthPermissions(ctx context.Context, arg FindOAuthPermiss
 	return i, err
 }
 
+const findOAuthPermissionsByUser = `-- name: FindOAuthPermissionsByUser :many
+SELECT created_at, client_id, user_id, scopes FROM permissions WHERE user_id = ?
+`
+
+func (q *Queries) FindOAuthPermissionsByUser(ctx context.Context, userID string) ([]Permission, error) {
+	rows, err := q.db.QueryContext(ctx, findOAuthPermissionsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Permission
+	for rows.Next() {
+		var i Permission
+		if err := rows.Scan(
+			&i.CreatedAt,
+			&i.ClientID,
+			&i.UserID,
+			&i.Scopes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findOAuthToken = `-- name: FindOAuthToken :one
-SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used FROM oauth WHERE category = ? AND token_hash = ? AND expires > ?3
+SELECT created_at, client_id, category, token_hash, redirect_uri, user_id, scopes, data, expires, used, grant_id FROM oauth WHERE category = ? AND token_hash = ? AND expires > ?3
 `
 
 type FindOAuthTokenParams struct {
@@ -140,6 +190,7 @@ func (q *Queries) FindOAuthToken(ctx context.Context, arg FindOAuthTokenParams)
 		&i.Data,
 		&i.Expires,
 		&i.Used,
+		&i.GrantID,
 	)
 	return i, err
 }
@@ -188,8 +239,30 @@ func (q *Queries) SetOAuthPermissions(ctx context.Context, arg SetOAuthPermissio
 	return i, err
 }
 
+const updateOAuthToken = `-- name: UpdateOAuthToken :execresult
+UPDATE oauth SET user_id = ?, data = ? WHERE client_id = ? AND category = ? AND token_hash = ?
+`
+
+type UpdateOAuthTokenParams struct {
+	UserID    sql.NullString
+	Data      []byte
+	ClientID  string
+	Category  string
+	TokenHash []byte
+}
+
+func (q *Queries) UpdateOAuthToken(ctx context.Context, arg UpdateOAuthTokenParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateOAuthToken,
+		arg.UserID,
+		arg.Data,
+		arg.ClientID,
+		arg.Category,
+		arg.TokenHash,
+	)
+}
+
 const useOAuthToken = `-- name: UseOAuthToken :execresult
-UPDATE oauth SET used = TRUE WHERE client_id = ? AND category = ? AND token_hash = ?
+UPDATE oauth SET used = TRUE WHERE client_id = ? AND category = ? AND token_hash = ? AND used = FALSE
 `
 
 type UseOAuthTokenParams struct {
@@ -11,9 +11,9 @@ import (
 
 const commitSession = `-- name: CommitSession :exec
 REPLACE INTO sessions (
-  token, data, expires
+  token, data, expires, user_id
 ) VALUES (
-  ?, ?, ?
+  ?, ?, ?, ?
 )
 `
 
@@ -21,10 +21,11 @@ type CommitSessionParams struct {
 	Token   string
 	Data    []byte
 	Expires int64
+	UserID  string
 }
 
 func (q *Queries) CommitSession(ctx context.Context, arg CommitSessionParams) error {
-	_, err := q.db.ExecContext(ctx, commitSession, arg.Token, arg.Data, arg.Expires)
+	_, err := q.db.ExecContext(ctx, commitSession, arg.Token, arg.Data, arg.Expires, arg.UserID)
 	return err
 }
 
@@ -84,3 +85,41 @@ func (q *Queries) FindSessions(ctx context.Context, now int64) ([]FindSessionsRo
 	}
 	return items, nil
 }
+
+const findUserSessions = `-- name: FindUserSessions :many
+SELECT token,data,expires FROM sessions WHERE user_id = ? AND expires > ?2
+`
+
+type FindUserSessionsParams struct {
+	UserID string
+	Now    int64
+}
+
+type FindUserSessionsRow struct {
+	Token   string
+	Data    []byte
+	Expires int64
+}
+
+func (q *Queries) FindUserSessions(ctx context.Context, arg FindUserSessionsParams) ([]FindUserSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, findUserSessions, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindUserSessionsRow
+	for rows.Next() {
+		var i FindUserSessionsRow
+		if err := rows.Scan(&i.Token, &i.Data, &i.Expires); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,343 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+	"github.com/juho05/h-id/repos/sqlite/db"
+)
+
+type gatewayRepository struct {
+	db    *db.Queries
+	rawDB *sql.DB
+}
+
+func (d *DB) NewGatewayRepository() repos.GatewayRepository {
+	return &gatewayRepository{
+		db:    d.db,
+		rawDB: d.rawDB,
+	}
+}
+
+func (g *gatewayRepository) Transaction(ctx context.Context, fn func(repo repos.GatewayRepository) error) error {
+	sqlTx, err := g.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return repoErr("begin transaction: %w", err)
+	}
+	defer sqlTx.Rollback()
+	err = fn(&gatewayRepository{
+		db:    g.db.WithTx(sqlTx),
+		rawDB: g.rawDB,
+	})
+	if err != nil {
+		return err
+	}
+	return repoErr("commit transaction: %w", sqlTx.Commit())
+}
+
+func repoGatewayGroup(group db.GatewayGroup) (*repos.GatewayGroupModel, error) {
+	id, err := ulid.Parse(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &repos.GatewayGroupModel{
+		BaseModel: repos.BaseModel{
+			ID:        id,
+			CreatedAt: time.Unix(group.CreatedAt, 0),
+		},
+		Name: group.Name,
+	}, nil
+}
+
+func repoGatewayGroupMember(member db.GatewayGroupMember) (*repos.GatewayGroupMemberModel, error) {
+	groupID, err := ulid.Parse(member.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := ulid.Parse(member.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &repos.GatewayGroupMemberModel{
+		CreatedAt: time.Unix(member.CreatedAt, 0),
+		GroupID:   groupID,
+		UserID:    userID,
+	}, nil
+}
+
+func repoGatewayDomain(domain db.GatewayDomain) (*repos.GatewayDomainModel, error) {
+	id, err := ulid.Parse(domain.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := parseULIDs(domain.GroupIds)
+	if err != nil {
+		return nil, fmt.Errorf("parse group IDs: %w", err)
+	}
+	users, err := parseULIDs(domain.UserIds)
+	if err != nil {
+		return nil, fmt.Errorf("parse user IDs: %w", err)
+	}
+	var rules []repos.GatewayRuleModel
+	err = json.Unmarshal(domain.Rules, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("decode rules: %w", err)
+	}
+	return &repos.GatewayDomainModel{
+		BaseModel: repos.BaseModel{
+			ID:        id,
+			CreatedAt: time.Unix(domain.CreatedAt, 0),
+		},
+		Domain:      domain.Domain,
+		Groups:      groups,
+		Users:       users,
+		Rules:       rules,
+		MaxAuthAge:  time.Duration(domain.MaxAuthAge) * time.Second,
+		Require2FA:  domain.Require2fa,
+		Headers:     parseGatewayHeaders(domain.Headers),
+		BearerScope: domain.BearerScope,
+	}, nil
+}
+
+func parseGatewayHeaders(str string) []repos.GatewayHeader {
+	if str == "" {
+		return nil
+	}
+	parts := strings.Split(str, ",")
+	headers := make([]repos.GatewayHeader, len(parts))
+	for i, p := range parts {
+		headers[i] = repos.GatewayHeader(p)
+	}
+	return headers
+}
+
+func joinGatewayHeaders(headers []repos.GatewayHeader) string {
+	parts := make([]string, len(headers))
+	for i, h := range headers {
+		parts[i] = string(h)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseULIDs(str string) ([]ulid.ULID, error) {
+	if str == "" {
+		return nil, nil
+	}
+	parts := strings.Split(str, ",")
+	ids := make([]ulid.ULID, len(parts))
+	for i, p := range parts {
+		id, err := ulid.Parse(p)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func joinULIDs(ids []ulid.ULID) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+func (g *gatewayRepository) FindGroups(ctx context.Context) ([]*repos.GatewayGroupModel, error) {
+	groups, err := g.db.FindGatewayGroups(ctx)
+	if err != nil {
+		return nil, repoErr("find gateway groups: %w", err)
+	}
+	repoGroups := make([]*repos.GatewayGroupModel, len(groups))
+	for i, gr := range groups {
+		repoGroups[i], err = repoGatewayGroup(gr)
+		if err != nil {
+			return nil, fmt.Errorf("find gateway groups: %w", err)
+		}
+	}
+	return repoGroups, nil
+}
+
+func (g *gatewayRepository) FindGroup(ctx context.Context, id ulid.ULID) (*repos.GatewayGroupModel, error) {
+	group, err := g.db.FindGatewayGroup(ctx, id.String())
+	if err != nil {
+		return nil, repoErr("find gateway group: %w", err)
+	}
+	return repoGatewayGroup(group)
+}
+
+func (g *gatewayRepository) CreateGroup(ctx context.Context, name string) (*repos.GatewayGroupModel, error) {
+	group, err := g.db.CreateGatewayGroup(ctx, db.CreateGatewayGroupParams{
+		ID:        ulid.Make().String(),
+		CreatedAt: time.Now().Unix(),
+		Name:      name,
+	})
+	if err != nil {
+		return nil, repoErr("create gateway group: %w", err)
+	}
+	return repoGatewayGroup(group)
+}
+
+func (g *gatewayRepository) UpdateGroup(ctx context.Context, id ulid.ULID, name string) (*repos.GatewayGroupModel, error) {
+	group, err := g.db.UpdateGatewayGroup(ctx, db.UpdateGatewayGroupParams{
+		Name: name,
+		ID:   id.String(),
+	})
+	if err != nil {
+		return nil, repoErr("update gateway group: %w", err)
+	}
+	return repoGatewayGroup(group)
+}
+
+func (g *gatewayRepository) DeleteGroup(ctx context.Context, id ulid.ULID) error {
+	result, err := g.db.DeleteGatewayGroup(ctx, id.String())
+	return repoErrResult("delete gateway group: %w", result, err)
+}
+
+func (g *gatewayRepository) FindGroupMembers(ctx context.Context, groupID ulid.ULID) ([]*repos.GatewayGroupMemberModel, error) {
+	members, err := g.db.FindGatewayGroupMembers(ctx, groupID.String())
+	if err != nil {
+		return nil, repoErr("find gateway group members: %w", err)
+	}
+	repoMembers := make([]*repos.GatewayGroupMemberModel, len(members))
+	for i, m := range members {
+		repoMembers[i], err = repoGatewayGroupMember(m)
+		if err != nil {
+			return nil, fmt.Errorf("find gateway group members: %w", err)
+		}
+	}
+	return repoMembers, nil
+}
+
+func (g *gatewayRepository) FindAllGroupMembers(ctx context.Context) ([]*repos.GatewayGroupMemberModel, error) {
+	members, err := g.db.FindAllGatewayGroupMembers(ctx)
+	if err != nil {
+		return nil, repoErr("find all gateway group members: %w", err)
+	}
+	repoMembers := make([]*repos.GatewayGroupMemberModel, len(members))
+	for i, m := range members {
+		repoMembers[i], err = repoGatewayGroupMember(m)
+		if err != nil {
+			return nil, fmt.Errorf("find all gateway group members: %w", err)
+		}
+	}
+	return repoMembers, nil
+}
+
+func (g *gatewayRepository) AddGroupMember(ctx context.Context, groupID, userID ulid.ULID) error {
+	_, err := g.db.AddGatewayGroupMember(ctx, db.AddGatewayGroupMemberParams{
+		GroupID:   groupID.String(),
+		UserID:    userID.String(),
+		CreatedAt: time.Now().Unix(),
+	})
+	return repoErr("add gateway group member: %w", err)
+}
+
+func (g *gatewayRepository) RemoveGroupMember(ctx context.Context, groupID, userID ulid.ULID) error {
+	result, err := g.db.RemoveGatewayGroupMember(ctx, db.RemoveGatewayGroupMemberParams{
+		GroupID: groupID.String(),
+		UserID:  userID.String(),
+	})
+	return repoErrResult("remove gateway group member: %w", result, err)
+}
+
+func (g *gatewayRepository) FindDomains(ctx context.Context) ([]*repos.GatewayDomainModel, error) {
+	domains, err := g.db.FindGatewayDomains(ctx)
+	if err != nil {
+		return nil, repoErr("find gateway domains: %w", err)
+	}
+	repoDomains := make([]*repos.GatewayDomainModel, len(domains))
+	for i, d := range domains {
+		repoDomains[i], err = repoGatewayDomain(d)
+		if err != nil {
+			return nil, fmt.Errorf("find gateway domains: %w", err)
+		}
+	}
+	return repoDomains, nil
+}
+
+func (g *gatewayRepository) FindDomain(ctx context.Context, id ulid.ULID) (*repos.GatewayDomainModel, error) {
+	domain, err := g.db.FindGatewayDomain(ctx, id.String())
+	if err != nil {
+		return nil, repoErr("find gateway domain: %w", err)
+	}
+	return repoGatewayDomain(domain)
+}
+
+func (g *gatewayRepository) CreateDomain(ctx context.Context, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error) {
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("create gateway domain: encode rules: %w", err)
+	}
+	d, err := g.db.CreateGatewayDomain(ctx, db.CreateGatewayDomainParams{
+		ID:          ulid.Make().String(),
+		CreatedAt:   time.Now().Unix(),
+		Domain:      domain,
+		GroupIds:    joinULIDs(groups),
+		UserIds:     joinULIDs(users),
+		Rules:       rulesJSON,
+		MaxAuthAge:  int64(maxAuthAge.Seconds()),
+		Require2fa:  require2FA,
+		Headers:     joinGatewayHeaders(headers),
+		BearerScope: bearerScope,
+	})
+	if err != nil {
+		return nil, repoErr("create gateway domain: %w", err)
+	}
+	return repoGatewayDomain(d)
+}
+
+func (g *gatewayRepository) UpdateDomain(ctx context.Context, id ulid.ULID, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error) {
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("update gateway domain: encode rules: %w", err)
+	}
+	d, err := g.db.UpdateGatewayDomain(ctx, db.UpdateGatewayDomainParams{
+		Domain:      domain,
+		GroupIds:    joinULIDs(groups),
+		UserIds:     joinULIDs(users),
+		Rules:       rulesJSON,
+		MaxAuthAge:  int64(maxAuthAge.Seconds()),
+		Require2fa:  require2FA,
+		Headers:     joinGatewayHeaders(headers),
+		BearerScope: bearerScope,
+		ID:          id.String(),
+	})
+	if err != nil {
+		return nil, repoErr("update gateway domain: %w", err)
+	}
+	return repoGatewayDomain(d)
+}
+
+func (g *gatewayRepository) DeleteDomain(ctx context.Context, id ulid.ULID) error {
+	result, err := g.db.DeleteGatewayDomain(ctx, id.String())
+	return repoErrResult("delete gateway domain: %w", result, err)
+}
+
+func (g *gatewayRepository) FindConfigFile(ctx context.Context) (*repos.GatewayConfigFileModel, error) {
+	file, err := g.db.FindGatewayConfigFile(ctx)
+	if err != nil {
+		return nil, repoErr("find gateway config file: %w", err)
+	}
+	return &repos.GatewayConfigFileModel{
+		AppliedAt: time.Unix(file.AppliedAt, 0),
+		Hash:      file.Hash,
+		Data:      file.Data,
+	}, nil
+}
+
+func (g *gatewayRepository) SetConfigFile(ctx context.Context, hash, data []byte) error {
+	err := g.db.SetGatewayConfigFile(ctx, db.SetGatewayConfigFileParams{
+		AppliedAt: time.Now().Unix(),
+		Hash:      hash,
+		Data:      data,
+	})
+	return repoErr("set gateway config file: %w", err)
+}
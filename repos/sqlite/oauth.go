@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/url"
 	"strings"
 	"time"
@@ -31,9 +33,20 @@ func repoOAuthToken(token db.Oauth) (*repos.OAuthTokenModel, error) {
 	if err != nil {
 		return nil, err
 	}
-	userID, err := ulid.Parse(token.UserID)
-	if err != nil {
-		return nil, err
+	var userID *ulid.ULID
+	if token.UserID.Valid {
+		id, err := ulid.Parse(token.UserID.String)
+		if err != nil {
+			return nil, err
+		}
+		userID = &id
+	}
+	var grantID ulid.ULID
+	if token.GrantID != "" {
+		grantID, err = ulid.Parse(token.GrantID)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &repos.OAuthTokenModel{
 		CreatedAt:   time.Unix(token.CreatedAt, 0),
@@ -46,6 +59,7 @@ func repoOAuthToken(token db.Oauth) (*repos.OAuthTokenModel, error) {
 		Data:        token.Data,
 		Expires:     time.Unix(token.Expires, 0),
 		Used:        token.Used,
+		GrantID:     grantID,
 	}, nil
 }
 
@@ -66,11 +80,44 @@ func repoOAuthPermissions(perms db.Permission) (*repos.PermissionsModel, error)
 	}, nil
 }
 
-func (a *oauthRepository) Create(ctx context.Context, clientID, userID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte, redirectURI *url.URL, scopes []string, data []byte, lifetime time.Duration) (*repos.OAuthTokenModel, error) {
+func repoBackchannelLogout(logout db.BackchannelLogout) (*repos.BackchannelLogoutModel, error) {
+	id, err := ulid.Parse(logout.ID)
+	if err != nil {
+		return nil, err
+	}
+	clientID, err := ulid.Parse(logout.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := ulid.Parse(logout.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &repos.BackchannelLogoutModel{
+		BaseModel: repos.BaseModel{
+			ID:        id,
+			CreatedAt: time.Unix(logout.CreatedAt, 0),
+		},
+		ClientID:    clientID,
+		UserID:      userID,
+		SID:         logout.Sid,
+		Attempt:     int(logout.Attempt),
+		NextAttempt: time.Unix(logout.NextAttempt, 0),
+	}, nil
+}
+
+func (a *oauthRepository) Create(ctx context.Context, clientID ulid.ULID, userID *ulid.ULID, grantID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte, redirectURI *url.URL, scopes []string, data []byte, lifetime time.Duration) (*repos.OAuthTokenModel, error) {
 	var redirectURIStr string
 	if redirectURI != nil {
 		redirectURIStr = redirectURI.String()
 	}
+	var userIDStr sql.NullString
+	if userID != nil {
+		userIDStr = sql.NullString{
+			String: userID.String(),
+			Valid:  true,
+		}
+	}
 	token, err := a.db.CreateOAuthToken(ctx, db.CreateOAuthTokenParams{
 		CreatedAt:   time.Now().Unix(),
 		Category:    string(category),
@@ -79,9 +126,10 @@ func (a *oauthRepository) Create(ctx context.Context, clientID, userID ulid.ULID
 		Scopes:      strings.Join(scopes, ","),
 		Data:        data,
 		ClientID:    clientID.String(),
-		UserID:      userID.String(),
+		UserID:      userIDStr,
 		Expires:     time.Now().Add(lifetime).Unix(),
 		Used:        false,
+		GrantID:     grantID.String(),
 	})
 	if err != nil {
 		return nil, repoErr("create oauth token: %w", err)
@@ -110,6 +158,24 @@ func (a *oauthRepository) Use(ctx context.Context, clientID ulid.ULID, category
 	return repoErrResult("use oauth token: %w", result, err)
 }
 
+func (a *oauthRepository) Update(ctx context.Context, clientID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte, userID *ulid.ULID, data []byte) error {
+	var userIDStr sql.NullString
+	if userID != nil {
+		userIDStr = sql.NullString{
+			String: userID.String(),
+			Valid:  true,
+		}
+	}
+	result, err := a.db.UpdateOAuthToken(ctx, db.UpdateOAuthTokenParams{
+		UserID:    userIDStr,
+		Data:      data,
+		ClientID:  clientID.String(),
+		Category:  string(category),
+		TokenHash: tokenHash,
+	})
+	return repoErrResult("update oauth token: %w", result, err)
+}
+
 func (a *oauthRepository) Delete(ctx context.Context, clientID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte) error {
 	result, err := a.db.DeleteOAuthToken(ctx, db.DeleteOAuthTokenParams{
 		ClientID:  clientID.String(),
@@ -119,11 +185,23 @@ func (a *oauthRepository) Delete(ctx context.Context, clientID ulid.ULID, catego
 	return repoErrResult("delete oauth token: %w", result, err)
 }
 
+func (a *oauthRepository) DeleteByGrant(ctx context.Context, clientID, grantID ulid.ULID) error {
+	err := a.db.DeleteOAuthTokensByGrant(ctx, db.DeleteOAuthTokensByGrantParams{
+		ClientID: clientID.String(),
+		GrantID:  grantID.String(),
+		Now:      time.Now().Unix(),
+	})
+	return repoErr("delete oauth tokens by grant: %w", err)
+}
+
 func (a *oauthRepository) DeleteByUser(ctx context.Context, clientID, userID ulid.ULID) error {
 	err := a.db.DeleteOAuthTokenByUser(ctx, db.DeleteOAuthTokenByUserParams{
 		ClientID: clientID.String(),
-		UserID:   userID.String(),
-		Now:      time.Now().Unix(),
+		UserID: sql.NullString{
+			String: userID.String(),
+			Valid:  true,
+		},
+		Now: time.Now().Unix(),
 	})
 	return repoErr("delete oauth token by user: %w", err)
 }
@@ -152,6 +230,24 @@ func (a *oauthRepository) FindPermissions(ctx context.Context, clientID, userID
 	return repoOAuthPermissions(perms)
 }
 
+func (a *oauthRepository) FindPermissionsByUser(ctx context.Context, userID ulid.ULID) ([]*repos.PermissionsModel, error) {
+	perms, err := a.db.FindOAuthPermissionsByUser(ctx, userID.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return make([]*repos.PermissionsModel, 0), nil
+		}
+		return nil, repoErr("find oauth permissions by user: %w", err)
+	}
+	permissions := make([]*repos.PermissionsModel, len(perms))
+	for i, p := range perms {
+		permissions[i], err = repoOAuthPermissions(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return permissions, nil
+}
+
 func (a *oauthRepository) RevokePermissions(ctx context.Context, clientID, userID ulid.ULID) error {
 	result, err := a.db.RevokeOAuthPermissions(ctx, db.RevokeOAuthPermissionsParams{
 		ClientID: clientID.String(),
@@ -159,3 +255,46 @@ func (a *oauthRepository) RevokePermissions(ctx context.Context, clientID, userI
 	})
 	return repoErrResult("revoke oauth permissions: %w", result, err)
 }
+
+func (a *oauthRepository) CreateBackchannelLogout(ctx context.Context, clientID, userID ulid.ULID, sid string) error {
+	now := time.Now().Unix()
+	err := a.db.CreateBackchannelLogout(ctx, db.CreateBackchannelLogoutParams{
+		ID:          ulid.Make().String(),
+		CreatedAt:   now,
+		ClientID:    clientID.String(),
+		UserID:      userID.String(),
+		Sid:         sid,
+		Attempt:     0,
+		NextAttempt: now,
+	})
+	return repoErr("create back-channel logout: %w", err)
+}
+
+func (a *oauthRepository) FindDueBackchannelLogouts(ctx context.Context) ([]*repos.BackchannelLogoutModel, error) {
+	dbLogouts, err := a.db.FindDueBackchannelLogouts(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, repoErr("find due back-channel logouts: %w", err)
+	}
+	logouts := make([]*repos.BackchannelLogoutModel, len(dbLogouts))
+	for i, l := range dbLogouts {
+		logouts[i], err = repoBackchannelLogout(l)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return logouts, nil
+}
+
+func (a *oauthRepository) UpdateBackchannelLogoutAttempt(ctx context.Context, id ulid.ULID, attempt int, nextAttempt time.Time) error {
+	result, err := a.db.UpdateBackchannelLogoutAttempt(ctx, db.UpdateBackchannelLogoutAttemptParams{
+		Attempt:     int64(attempt),
+		NextAttempt: nextAttempt.Unix(),
+		ID:          id.String(),
+	})
+	return repoErrResult("update back-channel logout attempt: %w", result, err)
+}
+
+func (a *oauthRepository) DeleteBackchannelLogout(ctx context.Context, id ulid.ULID) error {
+	result, err := a.db.DeleteBackchannelLogout(ctx, id.String())
+	return repoErrResult("delete back-channel logout: %w", result, err)
+}
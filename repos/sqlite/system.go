@@ -2,9 +2,12 @@ package sqlite
 
 import (
 	"context"
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/juho05/h-id/repos"
@@ -39,20 +42,96 @@ func (r *systemRepository) GetJWTKeys(ctx context.Context) (*rsa.PrivateKey, *rs
 	return priv, pub, nil
 }
 
-func (r *systemRepository) InsertJWTKeys(ctx context.Context, priv *rsa.PrivateKey, pub *rsa.PublicKey) error {
-	keyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(priv),
-	})
-	pubPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PUBLIC KEY",
-		Bytes: x509.MarshalPKCS1PublicKey(pub),
-	})
+func repoJWTKey(key db.JwtKey) (*repos.JWTKeyModel, error) {
+	privBlock, _ := pem.Decode(key.Private)
+	if privBlock == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(privBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key is not a signer")
+	}
+	pubBlock, _ := pem.Decode(key.Public)
+	if pubBlock == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	pub, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	var retiredAt time.Time
+	if key.RetiredAt != 0 {
+		retiredAt = time.Unix(key.RetiredAt, 0)
+	}
+	return &repos.JWTKeyModel{
+		ID:         key.ID,
+		CreatedAt:  time.Unix(key.CreatedAt, 0),
+		Algorithm:  key.Algorithm,
+		PrivateKey: signer,
+		PublicKey:  pub,
+		ActiveAt:   time.Unix(key.ActiveAt, 0),
+		RetiredAt:  retiredAt,
+	}, nil
+}
 
-	err := r.db.InsertJWTKeys(ctx, db.InsertJWTKeysParams{
+func (r *systemRepository) FindJWTKeys(ctx context.Context, retiredAfter time.Time) ([]*repos.JWTKeyModel, error) {
+	keys, err := r.db.FindJWTKeys(ctx, retiredAfter.Unix())
+	if err != nil {
+		return nil, repoErr("find JWT keys: %w", err)
+	}
+	repoKeys := make([]*repos.JWTKeyModel, len(keys))
+	for i, k := range keys {
+		repoKeys[i], err = repoJWTKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("find JWT keys: %w", err)
+		}
+	}
+	return repoKeys, nil
+}
+
+func (r *systemRepository) CreateJWTKey(ctx context.Context, id, algorithm string, privateKey crypto.Signer, activeAt time.Time) (*repos.JWTKeyModel, error) {
+	privDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("create JWT key: marshal private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("create JWT key: marshal public key: %w", err)
+	}
+	key, err := r.db.CreateJWTKey(ctx, db.CreateJWTKeyParams{
+		ID:        id,
 		CreatedAt: time.Now().Unix(),
-		Private:   keyPEM,
-		Public:    pubPEM,
+		Algorithm: algorithm,
+		Private: pem.EncodeToMemory(&pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privDER,
+		}),
+		Public: pem.EncodeToMemory(&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: pubDER,
+		}),
+		ActiveAt: activeAt.Unix(),
 	})
-	return repoErr("insert JWT keys: %w", err)
+	if err != nil {
+		return nil, repoErr("create JWT key: %w", err)
+	}
+	return repoJWTKey(key)
+}
+
+func (r *systemRepository) RetireJWTKeys(ctx context.Context, algorithm, exceptID string, retiredAt time.Time) error {
+	err := r.db.RetireJWTKeys(ctx, db.RetireJWTKeysParams{
+		RetiredAt: retiredAt.Unix(),
+		Algorithm: algorithm,
+		ID:        exceptID,
+	})
+	return repoErr("retire JWT keys: %w", err)
+}
+
+func (r *systemRepository) DeleteRetiredJWTKeys(ctx context.Context, retiredBefore time.Time) error {
+	err := r.db.DeleteRetiredJWTKeys(ctx, retiredBefore.Unix())
+	return repoErr("delete retired JWT keys: %w", err)
 }
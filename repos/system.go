@@ -2,10 +2,31 @@ package repos
 
 import (
 	"context"
+	"crypto"
 	"crypto/rsa"
+	"time"
 )
 
+type JWTKeyModel struct {
+	ID         string
+	CreatedAt  time.Time
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	// ActiveAt is the time at which the key starts to be used for signing new tokens.
+	ActiveAt time.Time
+	// RetiredAt is the time at which the key stops being used for signing new tokens. Zero if not yet retired.
+	RetiredAt time.Time
+}
+
 type SystemRepository interface {
+	// GetJWTKeys returns the legacy single RSA key pair.
 	GetJWTKeys(ctx context.Context) (*rsa.PrivateKey, *rsa.PublicKey, error)
-	InsertJWTKeys(ctx context.Context, priv *rsa.PrivateKey, pub *rsa.PublicKey) error
+
+	// FindJWTKeys returns all keys that are not retired or were retired after retiredAfter ordered by ActiveAt.
+	FindJWTKeys(ctx context.Context, retiredAfter time.Time) ([]*JWTKeyModel, error)
+	CreateJWTKey(ctx context.Context, id, algorithm string, privateKey crypto.Signer, activeAt time.Time) (*JWTKeyModel, error)
+	// RetireJWTKeys retires all keys of algorithm except exceptID at retiredAt.
+	RetireJWTKeys(ctx context.Context, algorithm, exceptID string, retiredAt time.Time) error
+	DeleteRetiredJWTKeys(ctx context.Context, retiredBefore time.Time) error
 }
@@ -27,6 +27,17 @@ type Passkey struct {
 	Credential webauthn.Credential
 }
 
+// AppPassword is a password for a single application, e.g. a mail or WebDAV client that cannot handle passkeys or 2FA.
+// Only the hash of the password is stored.
+type AppPassword struct {
+	BaseModel
+	Name   string
+	UserID ulid.ULID
+	Scopes []string
+	// LastUsed is zero if the password has never been used.
+	LastUsed time.Time
+}
+
 type UserRepository interface {
 	Find(ctx context.Context, id ulid.ULID) (*UserModel, error)
 	FindAll(ctx context.Context) ([]*UserModel, error)
@@ -55,6 +66,12 @@ type UserRepository interface {
 	UpdatePasskeyCredential(ctx context.Context, userID ulid.ULID, credential webauthn.Credential) error
 	UpdatePasskey(ctx context.Context, userID, id ulid.ULID, name string) error
 	DeletePasskey(ctx context.Context, userID, id ulid.ULID) error
+	CreateAppPassword(ctx context.Context, userID ulid.ULID, name string, scopes []string, passwordHash []byte) (*AppPassword, error)
+	GetAppPasswords(ctx context.Context, userID ulid.ULID) ([]*AppPassword, error)
+	GetAppPassword(ctx context.Context, userID, id ulid.ULID) (*AppPassword, error)
+	FindAppPasswordByHash(ctx context.Context, userID ulid.ULID, passwordHash []byte) (*AppPassword, error)
+	UpdateAppPasswordLastUsed(ctx context.Context, userID, id ulid.ULID) error
+	DeleteAppPassword(ctx context.Context, userID, id ulid.ULID) error
 	UpdateAdminStatus(ctx context.Context, userID ulid.ULID, isAdmin bool) error
 	Delete(ctx context.Context, id ulid.ULID) error
 }
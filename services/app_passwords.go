@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/log"
+
+	"github.com/juho05/h-id/repos"
+)
+
+// appPasswordLastUsedInterval limits how often the last use of an app password is written to the database.
+const appPasswordLastUsedInterval = time.Minute
+
+// CreateAppPassword creates an app password with the given scopes for a user, who must confirm the action with their account password.
+// The app password is returned only once and cannot be retrieved later.
+func (a *authService) CreateAppPassword(ctx context.Context, userID ulid.ULID, password, name, scope string) (*repos.AppPassword, string, error) {
+	err := a.VerifyPasswordByID(ctx, userID, password)
+	if err != nil {
+		return nil, "", fmt.Errorf("create app password: %w", err)
+	}
+	scope = strings.Join(strings.Fields(scope), " ")
+	if scope == "" {
+		return nil, "", fmt.Errorf("create app password: %w", ErrInvalidScope)
+	}
+	scopes, err := a.parseUserScopes(ctx, scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("create app password: %w", err)
+	}
+	appPassword := GenerateToken(32)
+	model, err := a.userRepo.CreateAppPassword(ctx, userID, name, scopes, hashAppPassword(appPassword))
+	if err != nil {
+		return nil, "", fmt.Errorf("create app password: %w", err)
+	}
+	return model, appPassword, nil
+}
+
+// VerifyAppPassword checks an app password of the user with the given email address and records its use.
+// ErrInsufficientScope is returned if the app password does not have all of requiredScopes.
+func (a *authService) VerifyAppPassword(ctx context.Context, email, password string, requiredScopes []string) (*repos.AppPassword, error) {
+	user, err := a.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("verify app password: %w", err)
+	}
+	appPassword, err := a.userRepo.FindAppPasswordByHash(ctx, user.ID, hashAppPassword(password))
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("verify app password: %w", err)
+	}
+	for _, s := range requiredScopes {
+		if !slices.Contains(appPassword.Scopes, s) {
+			return nil, ErrInsufficientScope
+		}
+	}
+	if time.Since(appPassword.LastUsed) > appPasswordLastUsedInterval {
+		err = a.userRepo.UpdateAppPasswordLastUsed(ctx, user.ID, appPassword.ID)
+		if err != nil {
+			log.Errorf("Failed to update last use of app password %s: %s", appPassword.ID, err)
+		}
+	}
+	return appPassword, nil
+}
+
+// oauthPassword issues an access token for an app password of a user (RFC 6749, section 4.3).
+// The client must be allowed to use the password grant and the token is restricted to the scopes of the app password.
+// No refresh token is issued because the client can use the app password again.
+func (a *authService) oauthPassword(ctx context.Context, client *repos.ClientModel, username, password, scope string) (string, error) {
+	if client.Type != repos.ClientTypeConfidential || !client.PasswordGrant {
+		return "", ErrUnauthorizedClient
+	}
+
+	appPassword, err := a.VerifyAppPassword(ctx, username, password, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return "", fmt.Errorf("password grant: %w: invalid app password", ErrInvalidGrant)
+		}
+		return "", fmt.Errorf("password grant: %w", err)
+	}
+
+	scopes := appPassword.Scopes
+	if scope != "" {
+		scopes = strings.Fields(scope)
+		for _, s := range scopes {
+			if !slices.Contains(appPassword.Scopes, s) {
+				return "", fmt.Errorf("password grant: %w: %s", ErrInvalidScope, s)
+			}
+		}
+	}
+
+	var access string
+	if client.AccessTokenFormat == repos.AccessTokenFormatJWT {
+		var audiences []string
+		audiences, err = a.accessTokenAudiences(ctx, scopes, nil)
+		if err != nil {
+			return "", fmt.Errorf("password grant: %w", err)
+		}
+		access, err = a.createJWTAccessToken(client.ID, appPassword.UserID.String(), audiences, scopes, nil, signedTokenLifetime)
+		if err != nil {
+			return "", fmt.Errorf("password grant: %w", err)
+		}
+	} else {
+		access = GenerateToken(64)
+	}
+
+	_, err = a.oauthRepo.Create(ctx, client.ID, &appPassword.UserID, ulid.Make(), repos.OAuthTokenAccess, hashTokenWeak(access), nil, scopes, nil, signedTokenLifetime)
+	if err != nil {
+		return "", fmt.Errorf("password grant: %w", err)
+	}
+	return access, nil
+}
+
+// hashAppPassword hashes an app password for storage. App passwords are long random tokens that are checked on every
+// gateway request with Basic auth, so a single SHA-256 is used instead of a slow key derivation function.
+func hashAppPassword(password string) []byte {
+	hash := sha256.Sum256([]byte(password))
+	return hash[:]
+}
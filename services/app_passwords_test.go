@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestVerifyAppPassword(t *testing.T) {
+	alice := &repos.UserModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Email: "alice@example.com"}
+	bob := &repos.UserModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Email: "bob@example.com"}
+	userRepo := &fakeUserRepo{
+		users: []*repos.UserModel{alice, bob},
+		appPasswords: map[string]*repos.AppPassword{
+			string(hashAppPassword("mail")): {BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: alice.ID, Scopes: []string{"openid", "email"}},
+		},
+	}
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		required []string
+		want     error
+	}{
+		{name: "valid", email: alice.Email, password: "mail"},
+		{name: "required scopes", email: alice.Email, password: "mail", required: []string{"email"}},
+		{name: "missing scope", email: alice.Email, password: "mail", required: []string{"email", "profile"}, want: ErrInsufficientScope},
+		{name: "wrong password", email: alice.Email, password: "mail2", want: ErrInvalidCredentials},
+		{name: "password of another user", email: bob.Email, password: "mail", want: ErrInvalidCredentials},
+		{name: "unknown user", email: "eve@example.com", password: "mail", want: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &authService{userRepo: userRepo}
+			appPassword, err := a.VerifyAppPassword(context.Background(), tt.email, tt.password, tt.required)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyAppPassword() = %v, want %v", err, tt.want)
+			}
+			if err == nil && time.Since(appPassword.LastUsed) > time.Second {
+				t.Errorf("last use was not recorded")
+			}
+		})
+	}
+}
+
+func TestOAuthPassword(t *testing.T) {
+	user := &repos.UserModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Email: "alice@example.com"}
+	client := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Type: repos.ClientTypeConfidential, AccessTokenFormat: repos.AccessTokenFormatOpaque, PasswordGrant: true}
+	public := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Type: repos.ClientTypePublic, PasswordGrant: true}
+	disabled := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Type: repos.ClientTypeConfidential}
+	userRepo := &fakeUserRepo{
+		users: []*repos.UserModel{user},
+		appPasswords: map[string]*repos.AppPassword{
+			string(hashAppPassword("mail")): {BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: user.ID, Scopes: []string{"openid", "email"}},
+		},
+	}
+	tests := []struct {
+		name     string
+		client   *repos.ClientModel
+		password string
+		scope    string
+		want     error
+		scopes   []string
+	}{
+		{name: "scopes of the app password", client: client, password: "mail", scopes: []string{"openid", "email"}},
+		{name: "narrower scope", client: client, password: "mail", scope: "email", scopes: []string{"email"}},
+		{name: "wider scope", client: client, password: "mail", scope: "email profile", want: ErrInvalidScope},
+		{name: "wrong app password", client: client, password: "account password", want: ErrInvalidGrant},
+		{name: "public client", client: public, password: "mail", want: ErrUnauthorizedClient},
+		{name: "grant disabled", client: disabled, password: "mail", want: ErrUnauthorizedClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauthRepo := &fakeOAuthRepo{}
+			a := &authService{
+				userRepo:  userRepo,
+				oauthRepo: oauthRepo,
+			}
+			access, err := a.oauthPassword(context.Background(), tt.client, user.Email, tt.password, tt.scope)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("oauthPassword() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(oauthRepo.tokens) != 0 {
+					t.Errorf("token issued despite error")
+				}
+				return
+			}
+			issued, err := oauthRepo.Find(context.Background(), repos.OAuthTokenAccess, hashTokenWeak(access))
+			if err != nil {
+				t.Fatal("issued token not stored")
+			}
+			if issued.ClientID != tt.client.ID || issued.UserID == nil || *issued.UserID != user.ID {
+				t.Errorf("issued token belongs to client %s and user %v", issued.ClientID, issued.UserID)
+			}
+			if !slices.Equal(issued.Scopes, tt.scopes) {
+				t.Errorf("scopes = %v, want %v", issued.Scopes, tt.scopes)
+			}
+		})
+	}
+}
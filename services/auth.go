@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
 	"encoding/gob"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,7 +17,9 @@ import (
 	"net/http"
 	"net/url"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -37,15 +41,36 @@ import (
 )
 
 type AuthService interface {
-	PublicJWTKey() *rsa.PublicKey
-
-	Login(ctx context.Context, userID ulid.ULID) error
+	PublicJWTKeys() []JWTPublicKey
+	ReloadJWTKeys(ctx context.Context) error
+	RotateJWTKeys(ctx context.Context, immediate bool) error
+	RunJWTKeyRotation(ctx context.Context, rotationInterval time.Duration)
+	RunBackchannelLogout(ctx context.Context)
+	BackchannelLogoutUser(ctx context.Context, userID ulid.ULID) error
+
+	// Login authenticates the session as the user and records the IP address and user agent of the client for the session list.
+	Login(ctx context.Context, userID ulid.ULID, amr []string, ip, userAgent string) error
+	// StepUp records that the user of the session verified their second factor again.
+	StepUp(ctx context.Context, amr string) error
 	VerifyUsernamePassword(ctx context.Context, email, password string) (*repos.UserModel, error)
 	Logout(ctx context.Context) error
+	// IndexSessions indexes the sessions that were stored before sessions were indexed by user.
+	IndexSessions(ctx context.Context) error
+	// RecordSessionActivity updates the last activity, IP address and user agent of the authenticated session.
+	// Empty values keep the stored IP address and user agent.
+	RecordSessionActivity(ctx context.Context, ip, userAgent string)
+	// GetSessions returns the active sessions of the user, starting with the current session.
+	GetSessions(ctx context.Context, userID ulid.ULID) ([]*UserSession, error)
+	// LogoutSession ends the session of the user with the given ID.
+	LogoutSession(ctx context.Context, userID ulid.ULID, sessionID string) error
+	// LogoutOtherSessions ends all sessions of the user except the current session.
+	LogoutOtherSessions(ctx context.Context, userID ulid.ULID) error
 	HashPassword(password string) ([]byte, error)
 	VerifyPassword(user *repos.UserModel, password string) error
 	VerifyPasswordByID(ctx context.Context, id ulid.ULID, password string) error
 	AuthenticatedUserID(ctx context.Context) ulid.ULID
+	AuthTime(ctx context.Context) time.Time
+	AMR(ctx context.Context) []string
 	AuthorizedScopes(ctx context.Context) []string
 	IsEmailConfirmed(ctx context.Context, id ulid.ULID) (bool, error)
 	SendConfirmEmail(r *http.Request, ctx context.Context, user *repos.UserModel) error
@@ -60,7 +85,7 @@ type AuthService interface {
 
 	GenerateOTPKey(ctx context.Context, user *repos.UserModel) (*otp.Key, error)
 	ActivateOTPKey(ctx context.Context, userID ulid.ULID, code string) error
-	VerifyOTPCode(ctx context.Context, userID ulid.ULID, code string) error
+	VerifyOTPCode(ctx context.Context, userID ulid.ULID, code string) (recoveryCode bool, err error)
 	IsOTPActive(ctx context.Context, id ulid.ULID) (bool, error)
 	DisableOTP(ctx context.Context, id ulid.ULID, password string) error
 
@@ -77,21 +102,34 @@ type AuthService interface {
 	PasskeyBeginLogin(ctx context.Context) (*protocol.CredentialAssertion, error)
 	PasskeyFinishLogin(ctx context.Context, req *http.Request) (*repos.UserModel, error)
 
-	StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID, redirectURI *url.URL, responseType, scope, state, nonce string) error
+	CreateAppPassword(ctx context.Context, userID ulid.ULID, password, name, scope string) (*repos.AppPassword, string, error)
+	VerifyAppPassword(ctx context.Context, email, password string, requiredScopes []string) (*repos.AppPassword, error)
+
+	StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID, redirectURI *url.URL, responseType, scope, state, nonce, codeChallenge, codeChallengeMethod, prompt, maxAge, idTokenHint string) error
 	GetAuthRequest(ctx context.Context) (AuthRequest, error)
 	OAuthConsent(ctx context.Context) (string, error)
-	OAuthGenerateTokens(ctx context.Context, clientID ulid.ULID, clientSecret string, redirectURI *url.URL, grantType, grant string) (access string, refresh string, id string, err error)
-	VerifyClientCredentials(ctx context.Context, clientID ulid.ULID, clientSecret string) error
+	OAuthDeviceAuthorization(ctx context.Context, clientID ulid.ULID, clientSecret, scope string) (*DeviceAuthorization, error)
+	StartOAuthDeviceFlow(ctx context.Context, userCode string) error
+	OAuthDeviceConsent(ctx context.Context, accept bool) error
+	EndSession(ctx context.Context, idTokenHint string, clientID ulid.ULID, postLogoutRedirectURI, state string) (redirect *url.URL, confirmed bool, err error)
+	OAuthGenerateTokens(ctx context.Context, clientID ulid.ULID, clientSecret string, redirectURI *url.URL, grantType, grant, codeVerifier, scope, audience, username string) (access string, refresh string, id string, err error)
+	VerifyClientCredentials(ctx context.Context, clientID ulid.ULID, clientSecret string) (*repos.ClientModel, error)
 	RevokeOAuthTokens(ctx context.Context, clientID, userID ulid.ULID) error
+	RevokeOAuthToken(ctx context.Context, clientID ulid.ULID, clientSecret, token, tokenTypeHint string) error
+	IntrospectOAuthToken(ctx context.Context, clientID ulid.ULID, clientSecret, token, tokenTypeHint string) (*TokenInfo, error)
+
+	VerifyAccessToken(ctx context.Context, token string, requiredScopes []string) (*repos.OAuthTokenModel, error)
+	AuthenticatedClientID(ctx context.Context) ulid.ULID
 
-	VerifyAccessToken(ctx context.Context, token string, requiredScopes []string) (userID ulid.ULID, scopes []string, err error)
+	DescribeScopes(ctx context.Context, lang string, scopes []string) []string
 
-	DescribeScopes(lang string, scopes []string) []string
+	CreateGatewayAssertion(ctx context.Context, userID ulid.ULID, audience string, source GatewayAuthSource) (string, error)
 }
 
 type (
-	AuthUserIDCtxKey struct{}
-	AuthScopesCtxKey struct{}
+	AuthUserIDCtxKey   struct{}
+	AuthClientIDCtxKey struct{}
+	AuthScopesCtxKey   struct{}
 )
 
 func init() {
@@ -113,24 +151,77 @@ type authService struct {
 	tokenRepo      repos.TokenRepository
 	oauthRepo      repos.OAuthRepository
 	systemRepo     repos.SystemRepository
+	sessionRepo    repos.SessionRepository
 	sessionManager *scs.SessionManager
 	emailService   EmailService
+	gatewayService AuthGatewayService
 	webAuthn       *webauthn.WebAuthn
 
-	jwtKeyPriv *rsa.PrivateKey
-	jwtKeyPub  *rsa.PublicKey
+	jwtKeys                 atomic.Pointer[jwtKeySet]
+	backchannelLogoutSignal chan struct{}
 }
 
 type AuthRequest struct {
-	ClientID     ulid.ULID
-	RedirectURI  *url.URL
-	Scopes       []string
-	State        string
-	Nonce        string
-	NeedsConsent bool
+	ClientID            ulid.ULID
+	RedirectURI         *url.URL
+	Scopes              []string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	NeedsConsent        bool
+	AuthTime            time.Time
+	AMR                 []string
+	SID                 string
+	// UserCodeHash is set if the request belongs to a device authorization instead of the authorization code flow.
+	UserCodeHash []byte
+}
+
+// oauthTokenData is stored JSON encoded in the data column of OAuth token rows.
+type oauthTokenData struct {
+	Nonce               string   `json:"nonce,omitempty"`
+	CodeChallenge       string   `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string   `json:"code_challenge_method,omitempty"`
+	AuthTime            int64    `json:"auth_time,omitempty"`
+	AMR                 []string `json:"amr,omitempty"`
+	SID                 string   `json:"sid,omitempty"`
+
+	// device authorization (RFC 8628)
+	DeviceStatus string `json:"device_status,omitempty"`
+	UserCodeHash []byte `json:"user_code_hash,omitempty"`
+	Interval     int64  `json:"interval,omitempty"`
+	LastPoll     int64  `json:"last_poll,omitempty"`
+
+	// token exchange (RFC 8693)
+	Audience string `json:"aud,omitempty"`
+	Act      *Actor `json:"act,omitempty"`
 }
 
-func NewAuthService(userRepository repos.UserRepository, tokenRepository repos.TokenRepository, oauthRepository repos.OAuthRepository, clientRepository repos.ClientRepository, systemRepository repos.SystemRepository, sessionManager *scs.SessionManager, emailService EmailService) (AuthService, error) {
+// userScopes are the scopes a user can grant to a client in the authorization code flow.
+// Additionally, users can grant the custom scopes that are registered by client owners.
+var userScopes = []string{"openid", "profile", "email", "groups"}
+
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+// Authentication method references (RFC 8176) recorded at login.
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRPasskey  = "hwk"
+	// AMRRecoveryCode is not registered in RFC 8176 but lets relying parties distinguish recovery codes from TOTP.
+	AMRRecoveryCode = "recovery_code"
+)
+
+// Authentication context class references included in ID tokens.
+const (
+	ACRPassword    = "pwd"
+	ACRMultiFactor = "mfa"
+)
+
+func NewAuthService(userRepository repos.UserRepository, tokenRepository repos.TokenRepository, oauthRepository repos.OAuthRepository, clientRepository repos.ClientRepository, systemRepository repos.SystemRepository, sessionRepository repos.SessionRepository, sessionManager *scs.SessionManager, emailService EmailService, gatewayService AuthGatewayService) (AuthService, error) {
 	webAuthn, err := webauthn.New(&webauthn.Config{
 		RPDisplayName: "H-ID",
 		RPID:          config.Domain(),
@@ -157,9 +248,13 @@ func NewAuthService(userRepository repos.UserRepository, tokenRepository repos.T
 		oauthRepo:      oauthRepository,
 		clientRepo:     clientRepository,
 		systemRepo:     systemRepository,
+		sessionRepo:    sessionRepository,
 		sessionManager: sessionManager,
 		emailService:   emailService,
+		gatewayService: gatewayService,
 		webAuthn:       webAuthn,
+
+		backchannelLogoutSignal: make(chan struct{}, 1),
 	}
 	err = a.initKeys(context.Background())
 	if err != nil {
@@ -168,34 +263,11 @@ func NewAuthService(userRepository repos.UserRepository, tokenRepository repos.T
 	return a, nil
 }
 
-func (a *authService) initKeys(ctx context.Context) error {
-	if priv, pub, err := a.systemRepo.GetJWTKeys(ctx); err == nil {
-		a.jwtKeyPriv = priv
-		a.jwtKeyPub = pub
-		log.Info("Using existing JWT keys...")
-	} else if errors.Is(err, repos.ErrNoRecord) {
-		log.Info("Generating new JWT keys...")
-		key, err := rsa.GenerateKey(rand.Reader, 2048)
-		if err != nil {
-			return fmt.Errorf("generate JWT RSA keys: %w", err)
-		}
-		err = a.systemRepo.InsertJWTKeys(ctx, key, &key.PublicKey)
-		if err != nil {
-			return fmt.Errorf("init keys: %w", err)
-		}
-		a.jwtKeyPriv = key
-		a.jwtKeyPub = &key.PublicKey
-	} else {
-		return fmt.Errorf("init keys: %w", err)
-	}
-	return nil
-}
-
-func (a *authService) PublicJWTKey() *rsa.PublicKey {
-	return a.jwtKeyPub
-}
-
-func (a *authService) StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID, redirectURI *url.URL, responseType, scope, state, nonce string) error {
+// StartOAuthCodeFlow validates an authorization request and stores it in the session.
+// It returns ErrLoginRequired if the user is not logged in or has to re-authenticate because of prompt=login, maxAge or idTokenHint.
+// maxAge is the raw max_age parameter in seconds. If it is empty, the login may be arbitrarily old.
+// If prompt is none and the user would have to interact with H-ID, ErrLoginRequired or ErrConsentRequired is returned.
+func (a *authService) StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID, redirectURI *url.URL, responseType, scope, state, nonce, codeChallenge, codeChallengeMethod, prompt, maxAge, idTokenHint string) error {
 	client, err := a.clientRepo.Find(ctx, clientID)
 	if err != nil {
 		return fmt.Errorf("start OAuth code flow: %w", err)
@@ -217,15 +289,75 @@ func (a *authService) StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID
 		return ErrUnsupportedResponseType
 	}
 
-	scopes := strings.Split(scope, " ")
-	for _, s := range scopes {
-		if s != "openid" && s != "profile" && s != "email" {
-			return fmt.Errorf("%w: %s", ErrInvalidScope, s)
+	scopes, err := a.parseUserScopes(ctx, scope)
+	if err != nil {
+		return err
+	}
+
+	if codeChallenge != "" {
+		if codeChallengeMethod == "" {
+			codeChallengeMethod = CodeChallengeMethodPlain
 		}
+		if codeChallengeMethod != CodeChallengeMethodPlain && codeChallengeMethod != CodeChallengeMethodS256 {
+			return fmt.Errorf("%w: unsupported code challenge method: %s", ErrInvalidRequest, codeChallengeMethod)
+		}
+		if !isValidPKCEString(codeChallenge) {
+			return fmt.Errorf("%w: invalid code challenge", ErrInvalidRequest)
+		}
+	} else if codeChallengeMethod != "" {
+		return fmt.Errorf("%w: code challenge method without code challenge", ErrInvalidRequest)
+	} else if client.Type == repos.ClientTypePublic {
+		return fmt.Errorf("%w: public clients must use PKCE", ErrInvalidRequest)
 	}
 
-	needsConsent := false
-	permissions, err := a.oauthRepo.FindPermissions(ctx, clientID, a.AuthenticatedUserID(ctx))
+	prompts := strings.Fields(prompt)
+	for _, p := range prompts {
+		if p != "none" && p != "login" && p != "consent" {
+			return fmt.Errorf("%w: unsupported prompt value: %s", ErrInvalidRequest, p)
+		}
+	}
+	if slices.Contains(prompts, "none") && len(prompts) > 1 {
+		return fmt.Errorf("%w: prompt=none must not be combined with other values", ErrInvalidRequest)
+	}
+	maxAgeSeconds := -1
+	if maxAge != "" {
+		maxAgeSeconds, err = strconv.Atoi(maxAge)
+		if err != nil || maxAgeSeconds < 0 {
+			return fmt.Errorf("%w: invalid max_age: %s", ErrInvalidRequest, maxAge)
+		}
+	}
+
+	userID := a.AuthenticatedUserID(ctx)
+	if userID == (ulid.ULID{}) {
+		return ErrLoginRequired
+	}
+	if idTokenHint != "" {
+		claims, err := a.parseIDTokenHint(idTokenHint, clientID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid id_token_hint: %w", ErrInvalidRequest, err)
+		}
+		// the session belongs to another user, which the client has to handle itself
+		if claims.Subject != userID.String() {
+			return ErrLoginRequired
+		}
+	}
+	authTime := a.AuthTime(ctx)
+	if slices.Contains(prompts, "login") || (maxAgeSeconds >= 0 && (authTime.IsZero() || time.Since(authTime) > time.Duration(maxAgeSeconds)*time.Second)) {
+		if !slices.Contains(prompts, "none") {
+			// the user returns without prompt=login and max_age after re-authenticating, which must not be skipped
+			a.sessionManager.Put(ctx, "reauthenticateAfter", time.Now().Unix())
+		}
+		return ErrReauthenticationRequired
+	}
+	if reauthenticateAfter := a.sessionManager.GetInt64(ctx, "reauthenticateAfter"); reauthenticateAfter != 0 {
+		if authTime.Unix() < reauthenticateAfter {
+			return ErrReauthenticationRequired
+		}
+		a.sessionManager.Remove(ctx, "reauthenticateAfter")
+	}
+
+	needsConsent := slices.Contains(prompts, "consent")
+	permissions, err := a.oauthRepo.FindPermissions(ctx, clientID, userID)
 	if err == nil {
 		for _, s := range scopes {
 			if !slices.Contains(permissions.Scopes, s) {
@@ -236,19 +368,78 @@ func (a *authService) StartOAuthCodeFlow(ctx context.Context, clientID ulid.ULID
 	} else {
 		needsConsent = true
 	}
+	if needsConsent && slices.Contains(prompts, "none") {
+		return ErrConsentRequired
+	}
 
 	a.sessionManager.Put(ctx, "authRequest", AuthRequest{
-		ClientID:     clientID,
-		RedirectURI:  redirectURI,
-		Scopes:       scopes,
-		State:        state,
-		Nonce:        nonce,
-		NeedsConsent: needsConsent,
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		Scopes:              scopes,
+		State:               state,
+		Nonce:               nonce,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		NeedsConsent:        needsConsent,
+		AuthTime:            a.AuthTime(ctx),
+		AMR:                 a.AMR(ctx),
+		SID:                 a.sessionManager.GetString(ctx, "sid"),
 	})
 
 	return nil
 }
 
+// parseUserScopes parses the space separated scope parameter of a request that is authorized by a user.
+func (a *authService) parseUserScopes(ctx context.Context, scope string) ([]string, error) {
+	scopes := strings.Split(scope, " ")
+	for _, s := range scopes {
+		if slices.Contains(userScopes, s) {
+			continue
+		}
+		_, err := a.clientRepo.FindScopeByName(ctx, s)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				err = ErrInvalidScope
+			}
+			return nil, fmt.Errorf("%w: %s", err, s)
+		}
+	}
+	return scopes, nil
+}
+
+// accessTokenAudiences returns the aud claim of an access token with the given scopes: H-ID itself and the audiences of all custom scopes.
+// If owner is not nil, only custom scopes of clients owned by this user are considered. This is used for the client credentials grant,
+// where the scopes are configured by the client owner instead of being granted by a user.
+func (a *authService) accessTokenAudiences(ctx context.Context, scopes []string, owner *ulid.ULID) ([]string, error) {
+	audiences := []string{config.BaseURL()}
+	for _, s := range scopes {
+		if slices.Contains(userScopes, s) {
+			continue
+		}
+		scope, err := a.clientRepo.FindScopeByName(ctx, s)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			return nil, fmt.Errorf("access token audiences: %w", err)
+		}
+		if slices.Contains(audiences, scope.Audience) {
+			continue
+		}
+		if owner != nil {
+			client, err := a.clientRepo.Find(ctx, scope.ClientID)
+			if err != nil {
+				return nil, fmt.Errorf("access token audiences: %w", err)
+			}
+			if client.UserID != *owner {
+				continue
+			}
+		}
+		audiences = append(audiences, scope.Audience)
+	}
+	return audiences, nil
+}
+
 func (a *authService) GetAuthRequest(ctx context.Context) (AuthRequest, error) {
 	req, ok := a.sessionManager.Get(ctx, "authRequest").(AuthRequest)
 	if !ok {
@@ -271,15 +462,38 @@ func (a *authService) OAuthConsent(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("OAuth consent: %w", err)
 	}
 
-	_, err = a.oauthRepo.Create(ctx, req.ClientID, userID, repos.OAuthTokenCode, codeHash, req.RedirectURI, req.Scopes, []byte(req.Nonce), 1*time.Minute)
+	data, err := json.Marshal(oauthTokenData{
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            unixTime(req.AuthTime),
+		AMR:                 req.AMR,
+		SID:                 req.SID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("OAuth consent: %w", err)
+	}
+
+	a.rememberSessionClient(ctx, req.ClientID)
+
+	_, err = a.oauthRepo.Create(ctx, req.ClientID, &userID, ulid.Make(), repos.OAuthTokenCode, codeHash, req.RedirectURI, req.Scopes, data, 1*time.Minute)
 	if err != nil {
 		return "", fmt.Errorf("OAuth consent: %w", err)
 	}
 	return code, nil
 }
 
-func (a *authService) OAuthGenerateTokens(ctx context.Context, clientID ulid.ULID, clientSecret string, redirectURI *url.URL, grantType, grant string) (string, string, string, error) {
-	if err := a.VerifyClientCredentials(ctx, clientID, clientSecret); err != nil {
+// rememberSessionClient remembers the clients of the session, so they can be notified on logout.
+func (a *authService) rememberSessionClient(ctx context.Context, clientID ulid.ULID) {
+	clients, _ := a.sessionManager.Get(ctx, "oauthClients").([]string)
+	if !slices.Contains(clients, clientID.String()) {
+		a.sessionManager.Put(ctx, "oauthClients", append(clients, clientID.String()))
+	}
+}
+
+func (a *authService) OAuthGenerateTokens(ctx context.Context, clientID ulid.ULID, clientSecret string, redirectURI *url.URL, grantType, grant, codeVerifier, scope, audience, username string) (string, string, string, error) {
+	client, err := a.VerifyClientCredentials(ctx, clientID, clientSecret)
+	if err != nil {
 		return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
 	}
 
@@ -292,6 +506,27 @@ func (a *authService) OAuthGenerateTokens(ctx context.Context, clientID ulid.ULI
 	case "refresh_token":
 		hash = hashTokenWeak(grant)
 		tokenType = repos.OAuthTokenRefresh
+	case "client_credentials":
+		access, err := a.oauthClientCredentials(ctx, client, scope)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+		return access, "", "", nil
+	case "password":
+		access, err := a.oauthPassword(ctx, client, username, grant, scope)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+		return access, "", "", nil
+	case GrantTypeDeviceCode:
+		hash = hashTokenWeak(grant)
+		tokenType = repos.OAuthTokenDeviceCode
+	case GrantTypeTokenExchange:
+		access, err := a.oauthTokenExchange(ctx, client, grant, scope, audience)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+		return access, "", "", nil
 	default:
 		return "", "", "", ErrUnsupportedGrantType
 	}
@@ -306,46 +541,102 @@ func (a *authService) OAuthGenerateTokens(ctx context.Context, clientID ulid.ULI
 	if token.ClientID != clientID {
 		return "", "", "", fmt.Errorf("oauth generate tokens: %w", ErrInvalidGrant)
 	}
+	if tokenType == repos.OAuthTokenDeviceCode && !token.Used {
+		// the device code becomes a grant once the user approved the device
+		approval, err := a.checkDeviceAuthorization(ctx, token)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+		token.UserID = approval.UserID
+		token.Data = approval.Data
+	}
+	if token.UserID == nil {
+		return "", "", "", fmt.Errorf("oauth generate tokens: %w", ErrInvalidGrant)
+	}
 	if token.Used {
-		err = a.RevokeOAuthTokens(ctx, clientID, token.UserID)
+		err = a.RevokeOAuthTokens(ctx, clientID, *token.UserID)
 		if err != nil {
 			log.Errorf("%s\n%s", fmt.Sprintf("oauth generate tokens: %s", err), debug.Stack())
 		}
 		return "", "", "", ErrReusedToken
 	}
 
-	if grantType != "refresh_token" && token.RedirectURI.String() != redirectURI.String() {
-		return "", "", "", fmt.Errorf("oauth generate tokens: %w", ErrInvalidRedirectURI)
-	}
-
+	// the code is used up before it is verified so that a failed attempt cannot be retried (RFC 7636, section 4.6)
+	// and only one of multiple concurrent requests can issue tokens
 	err = a.oauthRepo.Use(ctx, clientID, tokenType, token.TokenHash)
 	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			err = ErrInvalidGrant
+		}
 		return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
 	}
 
-	access := GenerateToken(64)
+	if grantType == "authorization_code" && token.RedirectURI.String() != redirectURI.String() {
+		return "", "", "", fmt.Errorf("oauth generate tokens: %w", ErrInvalidRedirectURI)
+	}
+
+	var data oauthTokenData
+	if len(token.Data) > 0 {
+		err = json.Unmarshal(token.Data, &data)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: decode token data: %w", err)
+		}
+	}
+	if grantType == "authorization_code" {
+		if client.Type == repos.ClientTypePublic && data.CodeChallenge == "" {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w: public clients must use PKCE", ErrInvalidGrant)
+		}
+		if !verifyCodeChallenge(data.CodeChallenge, data.CodeChallengeMethod, codeVerifier) {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", ErrInvalidCodeVerifier)
+		}
+	}
+
+	grantID := token.GrantID
+	if grantID == (ulid.ULID{}) {
+		grantID = ulid.Make()
+	}
+
+	var access string
+	if client.AccessTokenFormat == repos.AccessTokenFormatJWT {
+		audiences, err := a.accessTokenAudiences(ctx, token.Scopes, nil)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+		access, err = a.createJWTAccessToken(token.ClientID, token.UserID.String(), audiences, token.Scopes, nil, signedTokenLifetime)
+		if err != nil {
+			return "", "", "", fmt.Errorf("oauth generate tokens: %w", err)
+		}
+	} else {
+		access = GenerateToken(64)
+	}
 	accessHash := hashTokenWeak(access)
 	refresh := GenerateToken(128)
 	refreshHash := hashTokenWeak(refresh)
 
-	_, err = a.oauthRepo.Create(ctx, token.ClientID, token.UserID, repos.OAuthTokenAccess, accessHash, nil, token.Scopes, nil, 30*time.Minute)
+	_, err = a.oauthRepo.Create(ctx, token.ClientID, token.UserID, grantID, repos.OAuthTokenAccess, accessHash, nil, token.Scopes, nil, signedTokenLifetime)
 	if err != nil {
 		return "", "", "", fmt.Errorf("oauth tokens by code: %w", err)
 	}
 
-	_, err = a.oauthRepo.Create(ctx, token.ClientID, token.UserID, repos.OAuthTokenRefresh, refreshHash, nil, token.Scopes, nil, 12*7*24*time.Hour)
+	// the refresh token keeps the authentication information, so ID tokens issued on refresh still contain it
+	refreshData, err := json.Marshal(oauthTokenData{
+		Nonce:    data.Nonce,
+		AuthTime: data.AuthTime,
+		AMR:      data.AMR,
+		SID:      data.SID,
+	})
 	if err != nil {
 		return "", "", "", fmt.Errorf("oauth tokens by code: %w", err)
 	}
 
-	var nonce string
-	if grantType == "authorization_code" {
-		nonce = string(token.Data)
+	_, err = a.oauthRepo.Create(ctx, token.ClientID, token.UserID, grantID, repos.OAuthTokenRefresh, refreshHash, nil, token.Scopes, refreshData, 12*7*24*time.Hour)
+	if err != nil {
+		return "", "", "", fmt.Errorf("oauth tokens by code: %w", err)
 	}
 
 	var id string
 	if slices.Contains(token.Scopes, "openid") {
-		id, err = a.createIDToken(token.ClientID, token.UserID, nonce)
+		id, err = a.createIDToken(ctx, client, *token.UserID, token.Scopes, access, data)
 		if err != nil {
 			return "", "", "", fmt.Errorf("oauth tokens by code: %w", err)
 		}
@@ -354,36 +645,319 @@ func (a *authService) OAuthGenerateTokens(ctx context.Context, clientID ulid.ULI
 	return access, refresh, id, nil
 }
 
-func (a *authService) createIDToken(clientID, userID ulid.ULID, nonce string) (string, error) {
+// oauthClientCredentials issues an access token to a confidential client acting on its own behalf (RFC 6749, section 4.4).
+// The token has no user and is restricted to the client credentials scopes of the client. No refresh token is issued.
+func (a *authService) oauthClientCredentials(ctx context.Context, client *repos.ClientModel, scope string) (string, error) {
+	if client.Type != repos.ClientTypeConfidential || len(client.ClientCredentialsScopes) == 0 {
+		return "", ErrUnauthorizedClient
+	}
+
+	scopes := client.ClientCredentialsScopes
+	if scope != "" {
+		scopes = strings.Fields(scope)
+		for _, s := range scopes {
+			if !slices.Contains(client.ClientCredentialsScopes, s) {
+				return "", fmt.Errorf("client credentials: %w: %s", ErrInvalidScope, s)
+			}
+		}
+	}
+	// scopes may have been deleted or moved to another owner since they were assigned
+	err := checkClientCredentialsScopes(ctx, a.clientRepo, client.UserID, scopes)
+	if err != nil {
+		return "", fmt.Errorf("client credentials: %w", err)
+	}
+
+	var access string
+	if client.AccessTokenFormat == repos.AccessTokenFormatJWT {
+		var audiences []string
+		audiences, err = a.accessTokenAudiences(ctx, scopes, &client.UserID)
+		if err != nil {
+			return "", fmt.Errorf("client credentials: %w", err)
+		}
+		access, err = a.createJWTAccessToken(client.ID, client.ID.String(), audiences, scopes, nil, signedTokenLifetime)
+		if err != nil {
+			return "", fmt.Errorf("client credentials: %w", err)
+		}
+	} else {
+		access = GenerateToken(64)
+	}
+
+	_, err = a.oauthRepo.Create(ctx, client.ID, nil, ulid.Make(), repos.OAuthTokenAccess, hashTokenWeak(access), nil, scopes, nil, signedTokenLifetime)
+	if err != nil {
+		return "", fmt.Errorf("client credentials: %w", err)
+	}
+	return access, nil
+}
+
+// verifyCodeChallenge checks the PKCE code verifier against the challenge of the authorization request (RFC 7636).
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if challenge == "" {
+		return verifier == ""
+	}
+	if !isValidPKCEString(verifier) {
+		return false
+	}
+	switch method {
+	case CodeChallengeMethodPlain:
+		return subtle.ConstantTimeCompare([]byte(verifier), []byte(challenge)) == 1
+	case CodeChallengeMethodS256:
+		hash := sha256.Sum256([]byte(verifier))
+		return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(hash[:])), []byte(challenge)) == 1
+	default:
+		return false
+	}
+}
+
+// isValidPKCEString reports whether s is a valid code verifier or code challenge (43-128 unreserved characters).
+func isValidPKCEString(s string) bool {
+	if len(s) < 43 || len(s) > 128 {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '.' && c != '_' && c != '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// createJWTAccessToken creates a JWT access token as specified in RFC 9068.
+// The subject is the user ID or, for the client credentials grant, the client ID.
+// act is only set for tokens issued by token exchange.
+func (a *authService) createJWTAccessToken(clientID ulid.ULID, subject string, audiences []string, scopes []string, act *Actor, lifetime time.Duration) (string, error) {
 	type claims struct {
 		jwt.RegisteredClaims
-		Nonce string `json:"nonce,omitempty"`
+		ClientID string `json:"client_id"`
+		Scope    string `json:"scope"`
+		Act      *Actor `json:"act,omitempty"`
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    config.BaseURL(),
-			Subject:   userID.String(),
-			Audience:  jwt.ClaimStrings{clientID.String()},
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * time.Minute)),
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings(audiences),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(lifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        ulid.Make().String(),
 		},
-		Nonce: nonce,
+		ClientID: clientID.String(),
+		Scope:    strings.Join(scopes, " "),
+		Act:      act,
 	})
-	return token.SignedString(a.jwtKeyPriv)
+	token.Header["typ"] = "at+jwt"
+	return a.signJWT(token)
+}
+
+// verifyJWTAccessToken verifies the signature and claims of a JWT access token.
+func (a *authService) verifyJWTAccessToken(tokenStr string) error {
+	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
+		if typ, _ := t.Header["typ"].(string); !strings.EqualFold(typ, "at+jwt") && !strings.EqualFold(typ, "application/at+jwt") {
+			return nil, fmt.Errorf("unexpected token type: %v", t.Header["typ"])
+		}
+		return a.jwtKeyFunc(t)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	if err != nil {
+		return err
+	}
+	claims := token.Claims.(*jwt.RegisteredClaims)
+	if !claims.VerifyIssuer(config.BaseURL(), true) {
+		return errors.New("invalid issuer")
+	}
+	if !claims.VerifyAudience(config.BaseURL(), true) {
+		return errors.New("invalid audience")
+	}
+	return nil
+}
+
+// EndSession validates an RP-initiated logout request (OpenID Connect RP-Initiated Logout 1.0).
+// The returned redirect URI is nil if the client did not request a redirect.
+// confirmed is true if the client proved with idTokenHint that it knows the currently logged in user,
+// so the session can be ended without asking the user.
+func (a *authService) EndSession(ctx context.Context, idTokenHint string, clientID ulid.ULID, postLogoutRedirectURI, state string) (*url.URL, bool, error) {
+	var subject string
+	if idTokenHint != "" {
+		claims, err := a.parseIDTokenHint(idTokenHint, clientID)
+		if err != nil {
+			return nil, false, fmt.Errorf("end session: %w: invalid id_token_hint: %w", ErrInvalidRequest, err)
+		}
+		if clientID == (ulid.ULID{}) && len(claims.Audience) > 0 {
+			clientID, err = ulid.Parse(claims.Audience[0])
+			if err != nil {
+				return nil, false, fmt.Errorf("end session: %w: invalid id_token_hint audience", ErrInvalidRequest)
+			}
+		}
+		subject = claims.Subject
+	}
+	confirmed := subject != "" && subject == a.AuthenticatedUserID(ctx).String()
+
+	if postLogoutRedirectURI == "" {
+		return nil, confirmed, nil
+	}
+	if clientID == (ulid.ULID{}) {
+		return nil, false, fmt.Errorf("end session: %w: post_logout_redirect_uri requires id_token_hint or client_id", ErrInvalidRequest)
+	}
+	client, err := a.clientRepo.Find(ctx, clientID)
+	if err != nil {
+		return nil, false, fmt.Errorf("end session: %w", err)
+	}
+	var redirectURI *url.URL
+	for _, u := range client.PostLogoutRedirectURIs {
+		if u.String() == postLogoutRedirectURI {
+			redirectURI = &url.URL{}
+			*redirectURI = *u
+			break
+		}
+	}
+	if redirectURI == nil {
+		return nil, false, fmt.Errorf("end session: %w", ErrInvalidRedirectURI)
+	}
+	if state != "" {
+		q := redirectURI.Query()
+		q.Set("state", state)
+		redirectURI.RawQuery = q.Encode()
+	}
+	return redirectURI, confirmed, nil
+}
+
+// parseIDTokenHint verifies the signature and issuer of an ID token previously issued to clientID.
+// Expired tokens are accepted.
+func (a *authService) parseIDTokenHint(tokenStr string, clientID ulid.ULID) (*jwt.RegisteredClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(IDTokenSigningAlgorithms), jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, a.jwtKeyFunc)
+	if err != nil {
+		return nil, err
+	}
+	claims := token.Claims.(*jwt.RegisteredClaims)
+	if !claims.VerifyIssuer(config.BaseURL(), true) {
+		return nil, errors.New("invalid issuer")
+	}
+	if clientID != (ulid.ULID{}) && !claims.VerifyAudience(clientID.String(), true) {
+		return nil, errors.New("invalid audience")
+	}
+	return claims, nil
+}
+
+// createIDToken creates an OpenID Connect ID token containing the authentication information of data
+// and the name and email claims if the corresponding scopes were granted.
+func (a *authService) createIDToken(ctx context.Context, client *repos.ClientModel, userID ulid.ULID, scopes []string, accessToken string, data oauthTokenData) (string, error) {
+	type claims struct {
+		jwt.RegisteredClaims
+		Nonce         string           `json:"nonce,omitempty"`
+		AuthTime      *jwt.NumericDate `json:"auth_time,omitempty"`
+		AMR           []string         `json:"amr,omitempty"`
+		ACR           string           `json:"acr,omitempty"`
+		AZP           string           `json:"azp"`
+		SID           string           `json:"sid,omitempty"`
+		AtHash        string           `json:"at_hash,omitempty"`
+		Name          string           `json:"name,omitempty"`
+		Email         string           `json:"email,omitempty"`
+		EmailVerified *bool            `json:"email_verified,omitempty"`
+		Groups        []string         `json:"groups,omitempty"`
+	}
+	method := jwt.GetSigningMethod(client.IDTokenSignedResponseAlg)
+	if method == nil {
+		return "", fmt.Errorf("create ID token: unsupported signing algorithm: %s", client.IDTokenSignedResponseAlg)
+	}
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.BaseURL(),
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{client.ID.String()},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(signedTokenLifetime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Nonce: data.Nonce,
+		AMR:   data.AMR,
+		ACR:   acrFromAMR(data.AMR),
+		AZP:   client.ID.String(),
+		SID:   data.SID,
+	}
+	if data.AuthTime > 0 {
+		c.AuthTime = jwt.NewNumericDate(time.Unix(data.AuthTime, 0))
+	}
+	if accessToken != "" {
+		atHash, err := idTokenHash(method, accessToken)
+		if err != nil {
+			return "", fmt.Errorf("create ID token: %w", err)
+		}
+		c.AtHash = atHash
+	}
+	if slices.Contains(scopes, "profile") || slices.Contains(scopes, "email") {
+		user, err := a.userRepo.Find(ctx, userID)
+		if err != nil {
+			return "", fmt.Errorf("create ID token: %w", err)
+		}
+		if slices.Contains(scopes, "profile") {
+			c.Name = user.Name
+		}
+		if slices.Contains(scopes, "email") {
+			c.Email = user.Email
+			c.EmailVerified = &user.EmailConfirmed
+		}
+	}
+	if slices.Contains(scopes, "groups") {
+		c.Groups = a.gatewayService.Groups(userID)
+	}
+	return a.signJWT(jwt.NewWithClaims(method, c))
+}
+
+// idTokenHash computes the at_hash value of token: the base64url encoded left half of the token hash
+// using the hash function of the ID token signing algorithm.
+func idTokenHash(method jwt.SigningMethod, token string) (string, error) {
+	var hash []byte
+	switch method.Alg() {
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg():
+		h := sha256.Sum256([]byte(token))
+		hash = h[:]
+	case jwt.SigningMethodEdDSA.Alg():
+		// Ed25519 uses SHA-512 internally
+		h := sha512.Sum512([]byte(token))
+		hash = h[:]
+	default:
+		return "", fmt.Errorf("token hash: unsupported algorithm: %s", method.Alg())
+	}
+	return base64.RawURLEncoding.EncodeToString(hash[:len(hash)/2]), nil
+}
+
+// acrFromAMR returns ACRMultiFactor if the user authenticated with a second factor or a passkey and ACRPassword otherwise.
+func acrFromAMR(amr []string) string {
+	if len(amr) == 0 {
+		return ""
+	}
+	for _, m := range amr {
+		if m == AMROTP || m == AMRRecoveryCode || m == AMRPasskey {
+			return ACRMultiFactor
+		}
+	}
+	return ACRPassword
 }
 
-func (a *authService) VerifyClientCredentials(ctx context.Context, clientID ulid.ULID, clientSecret string) error {
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// VerifyClientCredentials authenticates a client. Public clients are identified by their ID only and must not send a secret.
+func (a *authService) VerifyClientCredentials(ctx context.Context, clientID ulid.ULID, clientSecret string) (*repos.ClientModel, error) {
 	client, err := a.clientRepo.Find(ctx, clientID)
 	if err != nil {
 		if errors.Is(err, repos.ErrNoRecord) {
 			err = ErrInvalidCredentials
 		}
-		return fmt.Errorf("verify client credentials: %w", err)
+		return nil, fmt.Errorf("verify client credentials: %w", err)
+	}
+	if client.Type == repos.ClientTypePublic {
+		if clientSecret != "" {
+			return nil, ErrInvalidCredentials
+		}
+		return client, nil
 	}
 	if string(hashToken(clientSecret)) != string(client.SecretHash) {
-		return ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
-	return nil
+	return client, nil
 }
 
 func (a *authService) RevokeOAuthTokens(ctx context.Context, clientID, userID ulid.ULID) error {
@@ -394,6 +968,121 @@ func (a *authService) RevokeOAuthTokens(ctx context.Context, clientID, userID ul
 	return nil
 }
 
+// RevokeOAuthToken revokes an access or refresh token (RFC 7009).
+// Revoking a refresh token also revokes all other tokens that were issued from the same grant.
+// Unknown tokens and tokens of other clients are silently ignored.
+func (a *authService) RevokeOAuthToken(ctx context.Context, clientID ulid.ULID, clientSecret, token, tokenTypeHint string) error {
+	_, err := a.VerifyClientCredentials(ctx, clientID, clientSecret)
+	if err != nil {
+		return fmt.Errorf("revoke OAuth token: %w", err)
+	}
+
+	categories := []repos.OAuthTokenCategory{repos.OAuthTokenAccess, repos.OAuthTokenRefresh}
+	if tokenTypeHint == "refresh_token" {
+		slices.Reverse(categories)
+	}
+
+	hash := hashTokenWeak(token)
+	for _, category := range categories {
+		t, err := a.oauthRepo.Find(ctx, category, hash)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			return fmt.Errorf("revoke OAuth token: %w", err)
+		}
+		if t.ClientID != clientID {
+			return nil
+		}
+		err = a.oauthRepo.Delete(ctx, clientID, category, hash)
+		if err != nil && !errors.Is(err, repos.ErrNoRecord) {
+			return fmt.Errorf("revoke OAuth token: %w", err)
+		}
+		if category == repos.OAuthTokenRefresh && t.GrantID != (ulid.ULID{}) {
+			err = a.oauthRepo.DeleteByGrant(ctx, clientID, t.GrantID)
+			if err != nil {
+				return fmt.Errorf("revoke OAuth token: %w", err)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// TokenInfo is an OAuth token together with the metadata that is relevant for resource servers.
+type TokenInfo struct {
+	*repos.OAuthTokenModel
+	// Audience contains H-ID and the audiences of the custom scopes of the token.
+	// Tokens that were bound to another client by token exchange only have the ID of that client as their audience.
+	Audience []string
+	// Act is the client that acts on behalf of the user if the token was issued by token exchange.
+	Act *Actor
+}
+
+// IntrospectOAuthToken looks up an access or refresh token for a resource server (RFC 7662).
+// Only confidential clients may introspect tokens. Refresh tokens can only be introspected by the client they were issued to.
+// If the token is not active, nil is returned.
+func (a *authService) IntrospectOAuthToken(ctx context.Context, clientID ulid.ULID, clientSecret, token, tokenTypeHint string) (*TokenInfo, error) {
+	client, err := a.VerifyClientCredentials(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("introspect OAuth token: %w", err)
+	}
+	if client.Type != repos.ClientTypeConfidential {
+		return nil, fmt.Errorf("introspect OAuth token: %w", ErrInvalidCredentials)
+	}
+
+	categories := []repos.OAuthTokenCategory{repos.OAuthTokenAccess, repos.OAuthTokenRefresh}
+	if tokenTypeHint == "refresh_token" {
+		slices.Reverse(categories)
+	}
+
+	hash := hashTokenWeak(token)
+	for _, category := range categories {
+		t, err := a.oauthRepo.Find(ctx, category, hash)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			return nil, fmt.Errorf("introspect OAuth token: %w", err)
+		}
+		if category == repos.OAuthTokenRefresh && (t.ClientID != clientID || t.Used) {
+			return nil, nil
+		}
+		info := &TokenInfo{
+			OAuthTokenModel: t,
+		}
+		if category != repos.OAuthTokenAccess {
+			return info, nil
+		}
+		var data oauthTokenData
+		if len(t.Data) > 0 {
+			err = json.Unmarshal(t.Data, &data)
+			if err != nil {
+				return nil, fmt.Errorf("introspect OAuth token: decode token data: %w", err)
+			}
+			info.Act = data.Act
+		}
+		if data.Audience != "" {
+			info.Audience = []string{data.Audience}
+			return info, nil
+		}
+		var owner *ulid.ULID
+		if t.UserID == nil {
+			tokenClient, err := a.clientRepo.Find(ctx, t.ClientID)
+			if err != nil {
+				return nil, fmt.Errorf("introspect OAuth token: %w", err)
+			}
+			owner = &tokenClient.UserID
+		}
+		info.Audience, err = a.accessTokenAudiences(ctx, t.Scopes, owner)
+		if err != nil {
+			return nil, fmt.Errorf("introspect OAuth token: %w", err)
+		}
+		return info, nil
+	}
+	return nil, nil
+}
+
 func (a *authService) SendConfirmEmail(r *http.Request, ctx context.Context, user *repos.UserModel) error {
 	if token, err := a.tokenRepo.Find(ctx, repos.TokenConfirmEmail, user.ID.String()); err == nil && time.Since(token.CreatedAt) < 2*time.Minute {
 		return ErrTimeout
@@ -584,6 +1273,28 @@ func (a *authService) AuthenticatedUserID(ctx context.Context) ulid.ULID {
 	return value
 }
 
+// AuthTime returns the time the user of the session logged in or the zero time if it is unknown.
+func (a *authService) AuthTime(ctx context.Context) time.Time {
+	authTime := a.sessionManager.GetInt64(ctx, "authTime")
+	if authTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(authTime, 0)
+}
+
+// AMR returns the authentication methods the user of the session used to log in.
+func (a *authService) AMR(ctx context.Context) []string {
+	amr, _ := a.sessionManager.Get(ctx, "amr").([]string)
+	return amr
+}
+
+// AuthenticatedClientID returns the client the access token of the request was issued to.
+// If the request was made by a client on its own behalf (client credentials grant), AuthenticatedUserID returns the zero ID.
+func (a *authService) AuthenticatedClientID(ctx context.Context) ulid.ULID {
+	value, _ := ctx.Value(AuthClientIDCtxKey{}).(ulid.ULID)
+	return value
+}
+
 func (a *authService) AuthorizedScopes(ctx context.Context) []string {
 	value, _ := ctx.Value(AuthScopesCtxKey{}).([]string)
 	return value
@@ -677,7 +1388,7 @@ func (a *authService) GenerateOTPKey(ctx context.Context, user *repos.UserModel)
 }
 
 func (a *authService) ActivateOTPKey(ctx context.Context, userID ulid.ULID, code string) error {
-	err := a.VerifyOTPCode(ctx, userID, code)
+	_, err := a.VerifyOTPCode(ctx, userID, code)
 	if err != nil {
 		return fmt.Errorf("activate OTP: %w", err)
 	}
@@ -693,28 +1404,29 @@ func (a *authService) ActivateOTPKey(ctx context.Context, userID ulid.ULID, code
 	return nil
 }
 
-func (a *authService) VerifyOTPCode(ctx context.Context, userID ulid.ULID, code string) error {
+// VerifyOTPCode verifies a TOTP code or, if that fails, a recovery code. Used recovery codes are deleted.
+func (a *authService) VerifyOTPCode(ctx context.Context, userID ulid.ULID, code string) (bool, error) {
 	_, key, err := a.userRepo.GetOTP(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repos.ErrNoRecord) {
-			return ErrInvalidCredentials
+			return false, ErrInvalidCredentials
 		}
-		return fmt.Errorf("verify otp code: get otp: %w", err)
+		return false, fmt.Errorf("verify otp code: get otp: %w", err)
 	}
 	if key == nil {
-		return fmt.Errorf("verify otp code: %w", ErrInvalidCredentials)
+		return false, fmt.Errorf("verify otp code: %w", ErrInvalidCredentials)
 	}
 	if !totp.Validate(code, key.Secret()) {
 		err := a.userRepo.DeleteRecoveryCode(ctx, userID, hashToken(code))
 		if err == nil {
-			return nil
+			return true, nil
 		}
 		if !errors.Is(err, repos.ErrNoRecord) {
-			return fmt.Errorf("verify otp code: verify recovery code: %w", err)
+			return false, fmt.Errorf("verify otp code: verify recovery code: %w", err)
 		}
-		return ErrInvalidCredentials
+		return false, ErrInvalidCredentials
 	}
-	return nil
+	return false, nil
 }
 
 func (a *authService) HasRecoveryCodes(ctx context.Context, userID ulid.ULID) (bool, error) {
@@ -912,21 +1624,51 @@ func (a *authService) VerifyUsernamePassword(ctx context.Context, email, passwor
 	return user, nil
 }
 
-func (a *authService) Login(ctx context.Context, userID ulid.ULID) error {
+// Login authenticates the session as userID and records the login time and the authentication methods (amr) used.
+func (a *authService) Login(ctx context.Context, userID ulid.ULID, amr []string, ip, userAgent string) error {
 	err := a.sessionManager.RenewToken(ctx)
 	if err != nil {
 		return fmt.Errorf("login: %w", err)
 	}
+	now := time.Now().Unix()
 	a.sessionManager.Put(ctx, "authUserID", userID)
+	a.sessionManager.Put(ctx, "authTime", now)
+	a.sessionManager.Put(ctx, "loginTime", now)
+	a.sessionManager.Put(ctx, "lastActivity", now)
+	a.sessionManager.Put(ctx, "ip", ip)
+	a.sessionManager.Put(ctx, "userAgent", userAgent)
+	a.sessionManager.Put(ctx, "sid", GenerateToken(32))
+	a.sessionManager.Put(ctx, "amr", amr)
 	a.sessionManager.Remove(ctx, "validPassword")
 	return nil
 }
 
+func (a *authService) StepUp(ctx context.Context, amr string) error {
+	err := a.sessionManager.RenewToken(ctx)
+	if err != nil {
+		return fmt.Errorf("step up: %w", err)
+	}
+	a.sessionManager.Put(ctx, "authTime", time.Now().Unix())
+	methods := a.AMR(ctx)
+	if !slices.Contains(methods, amr) {
+		a.sessionManager.Put(ctx, "amr", append(slices.Clone(methods), amr))
+	}
+	return nil
+}
+
+// Logout destroys the session and notifies the clients that were used in the session via back-channel logout.
 func (a *authService) Logout(ctx context.Context) error {
+	userID := a.AuthenticatedUserID(ctx)
+	sid := a.sessionManager.GetString(ctx, "sid")
+	clients, _ := a.sessionManager.Get(ctx, "oauthClients").([]string)
 	err := a.sessionManager.Destroy(ctx)
 	if err != nil {
 		return fmt.Errorf("logout: %w", err)
 	}
+	if userID == (ulid.ULID{}) {
+		return nil
+	}
+	a.backchannelLogoutSession(ctx, userID, sid, clients)
 	return nil
 }
 
@@ -954,17 +1696,37 @@ func (a *authService) VerifyPasswordByID(ctx context.Context, id ulid.ULID, pass
 	return err
 }
 
-func (a *authService) VerifyAccessToken(ctx context.Context, token string, requiredScopes []string) (ulid.ULID, []string, error) {
+// VerifyAccessToken accepts both opaque and JWT access tokens.
+// JWT access tokens are additionally looked up in the database, so revoked tokens are rejected.
+// The UserID of the returned token is nil if the token was issued with the client credentials grant.
+func (a *authService) VerifyAccessToken(ctx context.Context, token string, requiredScopes []string) (*repos.OAuthTokenModel, error) {
+	if strings.Count(token, ".") == 2 {
+		err := a.verifyJWTAccessToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("verify access token: %w: %w", ErrInvalidCredentials, err)
+		}
+	}
 	access, err := a.oauthRepo.Find(ctx, repos.OAuthTokenAccess, hashTokenWeak(token))
 	if err != nil {
-		return ulid.ULID{}, nil, fmt.Errorf("verify access token: %w", ErrInvalidCredentials)
+		return nil, fmt.Errorf("verify access token: %w", ErrInvalidCredentials)
+	}
+	if len(access.Data) > 0 {
+		var data oauthTokenData
+		err = json.Unmarshal(access.Data, &data)
+		if err != nil {
+			return nil, fmt.Errorf("verify access token: decode token data: %w", err)
+		}
+		// tokens that were exchanged for another audience are not valid for H-ID
+		if data.Audience != "" && data.Audience != config.BaseURL() {
+			return nil, fmt.Errorf("verify access token: %w: token is intended for another audience", ErrInvalidCredentials)
+		}
 	}
 	for _, s := range requiredScopes {
 		if !slices.Contains(access.Scopes, s) {
-			return ulid.ULID{}, nil, ErrInsufficientScope
+			return nil, ErrInsufficientScope
 		}
 	}
-	return access.UserID, access.Scopes, nil
+	return access, nil
 }
 
 func generateCode(length int) string {
@@ -1003,7 +1765,7 @@ func hashTokenWeak(token string) []byte {
 	return pbkdf2.Key([]byte(token), []byte("salt"), 5000, 256, sha256.New)
 }
 
-func (a *authService) DescribeScopes(lang string, scopes []string) []string {
+func (a *authService) DescribeScopes(ctx context.Context, lang string, scopes []string) []string {
 	descriptions := make([]string, 0, len(scopes))
 	for _, s := range scopes {
 		// [...] requests permission to:
@@ -1015,9 +1777,31 @@ func (a *authService) DescribeScopes(lang string, scopes []string) []string {
 		case "email":
 			d, _ := Translate(lang, "scopesEmail")
 			descriptions = append(descriptions, d)
+		case "groups":
+			d, _ := Translate(lang, "scopesGroups")
+			descriptions = append(descriptions, d)
 		default:
-			descriptions = append(descriptions, s)
+			descriptions = append(descriptions, a.describeCustomScope(ctx, lang, s))
 		}
 	}
 	return descriptions
 }
+
+// describeCustomScope returns the description of a custom scope in lang.
+// It falls back to the English description and the name of the scope.
+func (a *authService) describeCustomScope(ctx context.Context, lang, name string) string {
+	scope, err := a.clientRepo.FindScopeByName(ctx, name)
+	if err != nil {
+		if !errors.Is(err, repos.ErrNoRecord) {
+			log.Errorf("Failed to load description of scope %s: %s", name, err)
+		}
+		return name
+	}
+	if d, ok := scope.Descriptions[lang]; ok {
+		return d
+	}
+	if d, ok := scope.Descriptions["en"]; ok {
+		return d
+	}
+	return name
+}
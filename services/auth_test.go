@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	verifier := strings.Repeat("a1-._~", 8)
+	hash := sha256.Sum256([]byte(verifier))
+	s256 := base64.RawURLEncoding.EncodeToString(hash[:])
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+		verifier  string
+		want      bool
+	}{
+		{"no PKCE", "", "", "", true},
+		{"verifier without challenge", "", "", verifier, false},
+		{"plain", verifier, CodeChallengeMethodPlain, verifier, true},
+		{"plain mismatch", verifier, CodeChallengeMethodPlain, verifier + "x", false},
+		{"S256", s256, CodeChallengeMethodS256, verifier, true},
+		{"S256 with the challenge as verifier", s256, CodeChallengeMethodS256, s256, false},
+		{"S256 mismatch", s256, CodeChallengeMethodS256, strings.Repeat("b", 43), false},
+		{"missing verifier", s256, CodeChallengeMethodS256, "", false},
+		{"short verifier", "abc", CodeChallengeMethodPlain, "abc", false},
+		{"invalid characters", verifier[:42] + "+", CodeChallengeMethodPlain, verifier[:42] + "+", false},
+		{"unknown method", verifier, "S512", verifier, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.method, tt.verifier); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidPKCEString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{"minimum length", strings.Repeat("a", 43), true},
+		{"maximum length", strings.Repeat("Z", 128), true},
+		{"unreserved characters", strings.Repeat("aZ09-._~", 6), true},
+		{"too short", strings.Repeat("a", 42), false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"space", strings.Repeat("a", 42) + " ", false},
+		{"padding", strings.Repeat("a", 42) + "=", false},
+		{"non-ASCII", strings.Repeat("a", 42) + "ä", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidPKCEString(tt.s); got != tt.want {
+				t.Errorf("isValidPKCEString(%q) = %t, want %t", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOAuthCodeFlowPromptAndMaxAge(t *testing.T) {
+	userID := ulid.Make()
+	redirectURI, _ := url.Parse("https://app.example.com/callback")
+	evilURI, _ := url.Parse("https://evil.example.com")
+	confidential := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, RedirectURIs: []*url.URL{redirectURI}, Type: repos.ClientTypeConfidential}
+	public := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, RedirectURIs: []*url.URL{redirectURI}, Type: repos.ClientTypePublic}
+	consented := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, RedirectURIs: []*url.URL{redirectURI}, Type: repos.ClientTypeConfidential}
+	tests := []struct {
+		name        string
+		client      *repos.ClientModel
+		redirectURI *url.URL
+		loggedIn    bool
+		loginAge    time.Duration
+		prompt      string
+		maxAge      string
+		want        error
+	}{
+		{"consent required", confidential, redirectURI, true, time.Minute, "", "", nil},
+		{"consent given", consented, redirectURI, true, time.Minute, "", "", nil},
+		{"not logged in", consented, redirectURI, false, 0, "", "", ErrLoginRequired},
+		{"not logged in without interaction", consented, redirectURI, false, 0, "none", "", ErrLoginRequired},
+		{"consent without interaction", confidential, redirectURI, true, time.Minute, "none", "", ErrConsentRequired},
+		{"no interaction needed", consented, redirectURI, true, time.Minute, "none", "", nil},
+		{"forced consent", consented, redirectURI, true, time.Minute, "consent", "", nil},
+		{"forced login", consented, redirectURI, true, time.Minute, "login", "", ErrReauthenticationRequired},
+		{"forced login and consent", consented, redirectURI, true, time.Minute, "login consent", "", ErrReauthenticationRequired},
+		{"none with other values", consented, redirectURI, true, time.Minute, "none login", "", ErrInvalidRequest},
+		{"unsupported prompt", consented, redirectURI, true, time.Minute, "select_account", "", ErrInvalidRequest},
+		{"recent login", consented, redirectURI, true, time.Minute, "", "600", nil},
+		{"old login", consented, redirectURI, true, 20 * time.Minute, "", "600", ErrReauthenticationRequired},
+		{"old login without interaction", consented, redirectURI, true, 20 * time.Minute, "none", "600", ErrReauthenticationRequired},
+		{"zero max age", consented, redirectURI, true, time.Minute, "", "0", ErrReauthenticationRequired},
+		{"invalid max age", consented, redirectURI, true, time.Minute, "", "x", ErrInvalidRequest},
+		{"negative max age", consented, redirectURI, true, time.Minute, "", "-1", ErrInvalidRequest},
+		{"invalid max age with unregistered redirect URI", consented, evilURI, true, time.Minute, "", "x", ErrInvalidRedirectURI},
+		{"public client without PKCE", public, redirectURI, true, time.Minute, "", "", ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionManager := scs.New()
+			a := &authService{
+				clientRepo: &fakeClientRepo{clients: []*repos.ClientModel{confidential, public, consented}},
+				oauthRepo: &fakeOAuthRepo{permissions: []*repos.PermissionsModel{
+					{ClientID: consented.ID, UserID: userID, Scopes: []string{"openid", "profile"}},
+				}},
+				sessionManager: sessionManager,
+			}
+			ctx, err := sessionManager.Load(context.Background(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.loggedIn {
+				sessionManager.Put(ctx, "authUserID", userID)
+				sessionManager.Put(ctx, "authTime", time.Now().Add(-tt.loginAge).Unix())
+			}
+			err = a.StartOAuthCodeFlow(ctx, tt.client.ID, tt.redirectURI, "code", "openid", "state", "", "", "", tt.prompt, tt.maxAge, "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("StartOAuthCodeFlow() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOAuthCodeFlowReauthenticateAfterPromptLogin(t *testing.T) {
+	userID := ulid.Make()
+	redirectURI, _ := url.Parse("https://app.example.com/callback")
+	client := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, RedirectURIs: []*url.URL{redirectURI}, Type: repos.ClientTypeConfidential}
+	sessionManager := scs.New()
+	a := &authService{
+		clientRepo:     &fakeClientRepo{clients: []*repos.ClientModel{client}},
+		oauthRepo:      &fakeOAuthRepo{},
+		sessionManager: sessionManager,
+	}
+	ctx, err := sessionManager.Load(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionManager.Put(ctx, "authUserID", userID)
+	sessionManager.Put(ctx, "authTime", time.Now().Add(-time.Hour).Unix())
+
+	err = a.StartOAuthCodeFlow(ctx, client.ID, redirectURI, "code", "openid", "", "", "", "", "login", "", "")
+	if !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("prompt=login: got %v, want %v", err, ErrReauthenticationRequired)
+	}
+	// the login page redirects back without prompt=login
+	err = a.StartOAuthCodeFlow(ctx, client.ID, redirectURI, "code", "openid", "", "", "", "", "", "", "")
+	if !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("without re-authentication: got %v, want %v", err, ErrReauthenticationRequired)
+	}
+	sessionManager.Put(ctx, "authTime", time.Now().Add(time.Second).Unix())
+	err = a.StartOAuthCodeFlow(ctx, client.ID, redirectURI, "code", "openid", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("after re-authentication: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/log"
+
+	"github.com/juho05/h-id/config"
+	"github.com/juho05/h-id/repos"
+)
+
+const (
+	backchannelLogoutMaxAttempts = 5
+	// backchannelLogoutRetryDelay is doubled after every failed attempt.
+	backchannelLogoutRetryDelay = 5 * time.Second
+	// backchannelLogoutPollInterval is the interval in which the pending notifications are checked for due retries.
+	backchannelLogoutPollInterval = 5 * time.Second
+	backchannelLogoutTimeout      = 10 * time.Second
+	logoutTokenLifetime           = 2 * time.Minute
+)
+
+var errForbiddenDestination = errors.New("forbidden destination")
+
+// RunBackchannelLogout sends the pending back-channel logout notifications (OpenID Connect Back-Channel Logout 1.0) until ctx is canceled.
+// The notifications are stored in the database, so they survive restarts. Failed notifications are retried with exponential backoff.
+func (a *authService) RunBackchannelLogout(ctx context.Context) {
+	client := newBackchannelLogoutClient()
+	ticker := time.NewTicker(backchannelLogoutPollInterval)
+	defer ticker.Stop()
+	for {
+		a.sendDueBackchannelLogouts(ctx, client)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.backchannelLogoutSignal:
+		}
+	}
+}
+
+func (a *authService) sendDueBackchannelLogouts(ctx context.Context, client *http.Client) {
+	logouts, err := a.oauthRepo.FindDueBackchannelLogouts(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Errorf("Failed to load pending back-channel logouts: %s", err)
+		}
+		return
+	}
+	for _, logout := range logouts {
+		if ctx.Err() != nil {
+			return
+		}
+		err = a.sendBackchannelLogout(ctx, client, logout)
+		if err == nil {
+			err = a.oauthRepo.DeleteBackchannelLogout(ctx, logout.ID)
+			if err != nil {
+				log.Errorf("Failed to delete sent back-channel logout %s: %s", logout.ID, err)
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		attempt := logout.Attempt + 1
+		if attempt >= backchannelLogoutMaxAttempts || errors.Is(err, errForbiddenDestination) {
+			log.Errorf("Failed to send back-channel logout to client %s, giving up: %s", logout.ClientID, err)
+			err = a.oauthRepo.DeleteBackchannelLogout(ctx, logout.ID)
+			if err != nil {
+				log.Errorf("Failed to delete back-channel logout %s: %s", logout.ID, err)
+			}
+			continue
+		}
+		delay := backchannelLogoutRetryDelay << (attempt - 1)
+		log.Warnf("Failed to send back-channel logout to client %s, retrying in %s: %s", logout.ClientID, delay, err)
+		err = a.oauthRepo.UpdateBackchannelLogoutAttempt(ctx, logout.ID, attempt, time.Now().Add(delay))
+		if err != nil {
+			log.Errorf("Failed to reschedule back-channel logout %s: %s", logout.ID, err)
+		}
+	}
+}
+
+// BackchannelLogoutUser notifies all clients the user has granted access to that all sessions of the user have ended.
+func (a *authService) BackchannelLogoutUser(ctx context.Context, userID ulid.ULID) error {
+	permissions, err := a.oauthRepo.FindPermissionsByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("back-channel logout user: %w", err)
+	}
+	for _, p := range permissions {
+		a.queueBackchannelLogout(ctx, p.ClientID, userID, "")
+	}
+	return nil
+}
+
+// backchannelLogoutSession notifies the clients that were used in the session with the given sid that the session has ended.
+func (a *authService) backchannelLogoutSession(ctx context.Context, userID ulid.ULID, sid string, clients []string) {
+	for _, c := range clients {
+		clientID, err := ulid.Parse(c)
+		if err != nil {
+			continue
+		}
+		a.queueBackchannelLogout(ctx, clientID, userID, sid)
+	}
+}
+
+// queueBackchannelLogout stores a logout notification for the client and wakes up the sender.
+// sid is empty if all sessions of the user have ended.
+func (a *authService) queueBackchannelLogout(ctx context.Context, clientID, userID ulid.ULID, sid string) {
+	err := a.oauthRepo.CreateBackchannelLogout(ctx, clientID, userID, sid)
+	if err != nil {
+		log.Errorf("Failed to store back-channel logout for client %s, dropping it: %s", clientID, err)
+		return
+	}
+	select {
+	case a.backchannelLogoutSignal <- struct{}{}:
+	default:
+	}
+}
+
+// newBackchannelLogoutClient returns the HTTP client for back-channel logouts.
+// Logout URIs are set by client owners, so the client refuses to connect to loopback, private and link-local addresses
+// unless BACKCHANNEL_LOGOUT_ALLOW_PRIVATE is set. The check runs on the resolved address of every connection.
+func newBackchannelLogoutClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: backchannelLogoutTimeout,
+	}
+	if !config.BackchannelLogoutAllowPrivate() {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+				ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+				return fmt.Errorf("%w: %s", errForbiddenDestination, host)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: backchannelLogoutTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: backchannelLogoutTimeout,
+		},
+	}
+}
+
+func (a *authService) sendBackchannelLogout(ctx context.Context, client *http.Client, logout *repos.BackchannelLogoutModel) error {
+	c, err := a.clientRepo.Find(ctx, logout.ClientID)
+	if err != nil {
+		return fmt.Errorf("send back-channel logout: %w", err)
+	}
+	if c.BackchannelLogoutURI == nil {
+		return nil
+	}
+
+	method := jwt.GetSigningMethod(c.IDTokenSignedResponseAlg)
+	if method == nil {
+		return fmt.Errorf("send back-channel logout: unsupported signing algorithm: %s", c.IDTokenSignedResponseAlg)
+	}
+	type claims struct {
+		jwt.RegisteredClaims
+		SID    string         `json:"sid,omitempty"`
+		Events map[string]any `json:"events"`
+	}
+	token := jwt.NewWithClaims(method, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.BaseURL(),
+			Subject:   logout.UserID.String(),
+			Audience:  jwt.ClaimStrings{c.ID.String()},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(logoutTokenLifetime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        ulid.Make().String(),
+		},
+		SID: logout.SID,
+		Events: map[string]any{
+			"http://schemas.openid.net/event/backchannel-logout": struct{}{},
+		},
+	})
+	token.Header["typ"] = "logout+jwt"
+	logoutToken, err := a.signJWT(token)
+	if err != nil {
+		return fmt.Errorf("send back-channel logout: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BackchannelLogoutURI.String(), strings.NewReader(url.Values{
+		"logout_token": {logoutToken},
+	}.Encode()))
+	if err != nil {
+		return fmt.Errorf("send back-channel logout: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send back-channel logout: %w", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("send back-channel logout: unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -2,11 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/oklog/ulid/v2"
 
+	"github.com/juho05/h-id/config"
 	"github.com/juho05/h-id/repos"
 )
 
@@ -14,19 +19,27 @@ type ClientService interface {
 	Find(ctx context.Context, id ulid.ULID) (*repos.ClientModel, error)
 	FindByUserAndID(ctx context.Context, userID, clientID ulid.ULID) (*repos.ClientModel, error)
 	FindByUser(ctx context.Context, userID ulid.ULID) ([]*repos.ClientModel, error)
-	Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) (*repos.ClientModel, string, error)
-	Update(ctx context.Context, userID, clientID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) error
+	Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, clientType repos.ClientType, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) (*repos.ClientModel, string, error)
+	Update(ctx context.Context, userID, clientID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) error
 	ClientRotateSecret(ctx context.Context, userID, clientID ulid.ULID) (string, error)
 	Delete(ctx context.Context, userID, clientID ulid.ULID) error
+
+	FindScopes(ctx context.Context, userID, clientID ulid.ULID) ([]*repos.ScopeModel, error)
+	FindScope(ctx context.Context, userID, clientID, scopeID ulid.ULID) (*repos.ScopeModel, error)
+	CreateScope(ctx context.Context, userID, clientID ulid.ULID, name, audience string, descriptions map[string]string) (*repos.ScopeModel, error)
+	UpdateScope(ctx context.Context, userID, clientID, scopeID ulid.ULID, audience string, descriptions map[string]string) error
+	DeleteScope(ctx context.Context, userID, clientID, scopeID ulid.ULID) error
 }
 
 type clientService struct {
 	clientRepo repos.ClientRepository
+	userRepo   repos.UserRepository
 }
 
-func NewClientService(clientRepository repos.ClientRepository) ClientService {
+func NewClientService(clientRepository repos.ClientRepository, userRepository repos.UserRepository) ClientService {
 	return &clientService{
 		clientRepo: clientRepository,
+		userRepo:   userRepository,
 	}
 }
 
@@ -42,25 +55,146 @@ func (c *clientService) FindByUser(ctx context.Context, userID ulid.ULID) ([]*re
 	return c.clientRepo.FindByUser(ctx, userID)
 }
 
-func (c *clientService) Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) (*repos.ClientModel, string, error) {
-	secret := GenerateToken(64)
-	secretHash := hashToken(secret)
-	client, err := c.clientRepo.Create(ctx, userID, name, description, website, redirectURIs, secretHash)
+func (c *clientService) Create(ctx context.Context, userID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, clientType repos.ClientType, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) (*repos.ClientModel, string, error) {
+	if clientType != repos.ClientTypeConfidential && clientType != repos.ClientTypePublic {
+		return nil, "", fmt.Errorf("create client: invalid client type: %s", clientType)
+	}
+	if accessTokenFormat != repos.AccessTokenFormatOpaque && accessTokenFormat != repos.AccessTokenFormatJWT {
+		return nil, "", fmt.Errorf("create client: invalid access token format: %s", accessTokenFormat)
+	}
+	if !slices.Contains(IDTokenSigningAlgorithms, idTokenSignedResponseAlg) {
+		return nil, "", fmt.Errorf("create client: unsupported ID token signing algorithm: %s", idTokenSignedResponseAlg)
+	}
+	if len(clientCredentialsScopes) > 0 && clientType != repos.ClientTypeConfidential {
+		return nil, "", fmt.Errorf("create client: %w: public clients cannot use the client credentials grant", ErrInvalidScope)
+	}
+	if tokenExchange && clientType != repos.ClientTypeConfidential {
+		return nil, "", fmt.Errorf("create client: %w: public clients cannot use token exchange", ErrUnauthorizedClient)
+	}
+	err := checkClientCredentialsScopes(ctx, c.clientRepo, userID, clientCredentialsScopes)
+	if err != nil {
+		return nil, "", fmt.Errorf("create client: %w", err)
+	}
+	if passwordGrant {
+		err = c.checkPasswordGrant(ctx, userID, clientType)
+		if err != nil {
+			return nil, "", fmt.Errorf("create client: %w", err)
+		}
+	}
+	// public clients cannot keep a secret, so they don't get one
+	var secret string
+	secretHash := []byte{}
+	if clientType == repos.ClientTypeConfidential {
+		secret = GenerateToken(64)
+		secretHash = hashToken(secret)
+	}
+	client, err := c.clientRepo.Create(ctx, userID, name, description, website, redirectURIs, postLogoutRedirectURIs, backchannelLogoutURI, clientCredentialsScopes, tokenExchange, passwordGrant, secretHash, clientType, accessTokenFormat, idTokenSignedResponseAlg)
 	if err != nil {
 		return nil, "", fmt.Errorf("create client: %w", err)
 	}
 	return client, secret, nil
 }
 
-func (c *clientService) Update(ctx context.Context, userID, clientID ulid.ULID, name, description string, website *url.URL, redirectURIs []*url.URL) error {
-	_, err := c.clientRepo.Update(ctx, userID, clientID, name, description, website, redirectURIs)
+func (c *clientService) Update(ctx context.Context, userID, clientID ulid.ULID, name, description string, website *url.URL, redirectURIs, postLogoutRedirectURIs []*url.URL, backchannelLogoutURI *url.URL, clientCredentialsScopes []string, tokenExchange, passwordGrant bool, accessTokenFormat repos.AccessTokenFormat, idTokenSignedResponseAlg string) error {
+	if accessTokenFormat != repos.AccessTokenFormatOpaque && accessTokenFormat != repos.AccessTokenFormatJWT {
+		return fmt.Errorf("update client: invalid access token format: %s", accessTokenFormat)
+	}
+	if !slices.Contains(IDTokenSigningAlgorithms, idTokenSignedResponseAlg) {
+		return fmt.Errorf("update client: unsupported ID token signing algorithm: %s", idTokenSignedResponseAlg)
+	}
+	err := checkClientCredentialsScopes(ctx, c.clientRepo, userID, clientCredentialsScopes)
+	if err != nil {
+		return fmt.Errorf("update client: %w", err)
+	}
+	if len(clientCredentialsScopes) > 0 || tokenExchange || passwordGrant {
+		client, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+		if err != nil {
+			return fmt.Errorf("update client: %w", err)
+		}
+		if len(clientCredentialsScopes) > 0 && client.Type != repos.ClientTypeConfidential {
+			return fmt.Errorf("update client: %w: public clients cannot use the client credentials grant", ErrInvalidScope)
+		}
+		if tokenExchange && client.Type != repos.ClientTypeConfidential {
+			return fmt.Errorf("update client: %w: public clients cannot use token exchange", ErrUnauthorizedClient)
+		}
+		// an enabled password grant may be kept by the owner, but only admins can enable it
+		if passwordGrant && (!client.PasswordGrant || client.Type != repos.ClientTypeConfidential) {
+			err = c.checkPasswordGrant(ctx, userID, client.Type)
+			if err != nil {
+				return fmt.Errorf("update client: %w", err)
+			}
+		}
+	}
+	_, err = c.clientRepo.Update(ctx, userID, clientID, name, description, website, redirectURIs, postLogoutRedirectURIs, backchannelLogoutURI, clientCredentialsScopes, tokenExchange, passwordGrant, accessTokenFormat, idTokenSignedResponseAlg)
 	return err
 }
 
+// checkPasswordGrant checks that the user may enable the password grant for a client of the given type.
+// App passwords are valid for every client with the password grant, so only admins can enable it and only for confidential clients.
+func (c *clientService) checkPasswordGrant(ctx context.Context, userID ulid.ULID, clientType repos.ClientType) error {
+	if clientType != repos.ClientTypeConfidential {
+		return fmt.Errorf("%w: public clients cannot use the password grant", ErrPasswordGrantNotAllowed)
+	}
+	user, err := c.userRepo.Find(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Admin {
+		return fmt.Errorf("%w: only admins can enable the password grant", ErrPasswordGrantNotAllowed)
+	}
+	return nil
+}
+
+// checkClientCredentialsScopes checks that scopes only contains custom scopes of clients owned by ownerID.
+// A client acting on its own behalf must not get access to the APIs of other users or to scopes that describe a user.
+func checkClientCredentialsScopes(ctx context.Context, clientRepo repos.ClientRepository, ownerID ulid.ULID, scopes []string) error {
+	for _, s := range scopes {
+		if !isScopeToken(s) || slices.Contains(userScopes, s) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, s)
+		}
+		scope, err := clientRepo.FindScopeByName(ctx, s)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				return fmt.Errorf("%w: %s", ErrInvalidScope, s)
+			}
+			return err
+		}
+		client, err := clientRepo.Find(ctx, scope.ClientID)
+		if err != nil {
+			return err
+		}
+		if client.UserID != ownerID {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, s)
+		}
+	}
+	return nil
+}
+
+// isScopeToken reports whether s is a valid scope token (RFC 6749, section 3.3).
+func isScopeToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientRotateSecret replaces the secret of a confidential client. Public clients don't have a secret.
 func (c *clientService) ClientRotateSecret(ctx context.Context, userID, clientID ulid.ULID) (string, error) {
+	client, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return "", fmt.Errorf("rotate client secret: %w", err)
+	}
+	if client.Type != repos.ClientTypeConfidential {
+		return "", fmt.Errorf("rotate client secret: %w: public clients don't have a secret", ErrInvalidRequest)
+	}
 	secret := GenerateToken(64)
 	secretHash := hashToken(secret)
-	err := c.clientRepo.UpdateSecret(ctx, userID, clientID, secretHash)
+	err = c.clientRepo.UpdateSecret(ctx, userID, clientID, secretHash)
 	if err != nil {
 		return "", fmt.Errorf("rotate client secret: %w", err)
 	}
@@ -70,3 +204,116 @@ func (c *clientService) ClientRotateSecret(ctx context.Context, userID, clientID
 func (c *clientService) Delete(ctx context.Context, userID, clientID ulid.ULID) error {
 	return c.clientRepo.Delete(ctx, userID, clientID)
 }
+
+func (c *clientService) FindScopes(ctx context.Context, userID, clientID ulid.ULID) ([]*repos.ScopeModel, error) {
+	_, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("find scopes: %w", err)
+	}
+	return c.clientRepo.FindScopesByClient(ctx, clientID)
+}
+
+func (c *clientService) FindScope(ctx context.Context, userID, clientID, scopeID ulid.ULID) (*repos.ScopeModel, error) {
+	_, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("find scope: %w", err)
+	}
+	return c.clientRepo.FindScope(ctx, clientID, scopeID)
+}
+
+// CreateScope registers a custom scope that other clients can request from users.
+// Scope names are unique across all clients. If audience is empty, the client ID is used.
+func (c *clientService) CreateScope(ctx context.Context, userID, clientID ulid.ULID, name, audience string, descriptions map[string]string) (*repos.ScopeModel, error) {
+	_, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("create scope: %w", err)
+	}
+	if !isScopeToken(name) || slices.Contains(userScopes, name) {
+		return nil, fmt.Errorf("create scope: %w: %s", ErrInvalidScope, name)
+	}
+	audience, err = c.checkScopeAudience(ctx, userID, clientID, audience, "")
+	if err != nil {
+		return nil, fmt.Errorf("create scope: %w", err)
+	}
+	scope, err := c.clientRepo.CreateScope(ctx, clientID, name, audience, cleanScopeDescriptions(descriptions))
+	if err != nil {
+		return nil, fmt.Errorf("create scope: %w", err)
+	}
+	return scope, nil
+}
+
+func (c *clientService) UpdateScope(ctx context.Context, userID, clientID, scopeID ulid.ULID, audience string, descriptions map[string]string) error {
+	_, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return fmt.Errorf("update scope: %w", err)
+	}
+	scope, err := c.clientRepo.FindScope(ctx, clientID, scopeID)
+	if err != nil {
+		return fmt.Errorf("update scope: %w", err)
+	}
+	audience, err = c.checkScopeAudience(ctx, userID, clientID, audience, scope.Audience)
+	if err != nil {
+		return fmt.Errorf("update scope: %w", err)
+	}
+	_, err = c.clientRepo.UpdateScope(ctx, clientID, scopeID, audience, cleanScopeDescriptions(descriptions))
+	if err != nil {
+		return fmt.Errorf("update scope: %w", err)
+	}
+	return nil
+}
+
+func (c *clientService) DeleteScope(ctx context.Context, userID, clientID, scopeID ulid.ULID) error {
+	_, err := c.clientRepo.FindByUserAndID(ctx, userID, clientID)
+	if err != nil {
+		return fmt.Errorf("delete scope: %w", err)
+	}
+	return c.clientRepo.DeleteScope(ctx, clientID, scopeID)
+}
+
+// checkScopeAudience returns the audience to store for a scope of the client.
+// Audiences other than the client ID identify APIs outside of H-ID, so only admins can assign them,
+// otherwise clients could make H-ID issue access tokens for APIs they don't own.
+// An audience may only be used by the scopes of a single client. The current audience of the scope may be kept.
+func (c *clientService) checkScopeAudience(ctx context.Context, userID, clientID ulid.ULID, audience, current string) (string, error) {
+	if audience == "" || audience == clientID.String() {
+		return clientID.String(), nil
+	}
+	if !isScopeToken(audience) || audience == config.BaseURL() {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAudience, audience)
+	}
+	if _, err := ulid.Parse(audience); err == nil {
+		// IDs of other clients
+		return "", fmt.Errorf("%w: %s", ErrInvalidAudience, audience)
+	}
+	if audience != current {
+		user, err := c.userRepo.Find(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if !user.Admin {
+			return "", fmt.Errorf("%w: only admins can assign audiences: %s", ErrInvalidAudience, audience)
+		}
+	}
+	scopes, err := c.clientRepo.FindScopesByAudience(ctx, audience)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range scopes {
+		if s.ClientID != clientID {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAudience, audience)
+		}
+	}
+	return audience, nil
+}
+
+// cleanScopeDescriptions removes empty descriptions, so that the consent page falls back to English.
+func cleanScopeDescriptions(descriptions map[string]string) map[string]string {
+	cleaned := make(map[string]string, len(descriptions))
+	for lang, d := range descriptions {
+		d = strings.TrimSpace(d)
+		if d != "" {
+			cleaned[lang] = d
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestCheckClientCredentialsScopes(t *testing.T) {
+	owner, other := ulid.Make(), ulid.Make()
+	own := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: owner}
+	ownAPI := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: owner}
+	foreign := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: other}
+	clientRepo := &fakeClientRepo{
+		clients: []*repos.ClientModel{own, ownAPI, foreign},
+		scopes: []*repos.ScopeModel{
+			{ClientID: own.ID, Name: "reports:read"},
+			{ClientID: ownAPI.ID, Name: "api"},
+			{ClientID: foreign.ID, Name: "payments:write"},
+		},
+	}
+	tests := []struct {
+		name   string
+		scopes []string
+		want   error
+	}{
+		{"none", nil, nil},
+		{"scope of the client", []string{"reports:read"}, nil},
+		{"scope of another app of the owner", []string{"reports:read", "api"}, nil},
+		{"scope of another user", []string{"reports:read", "payments:write"}, ErrInvalidScope},
+		{"unregistered scope", []string{"backup"}, ErrInvalidScope},
+		{"user scope", []string{"openid"}, ErrInvalidScope},
+		{"groups", []string{"groups"}, ErrInvalidScope},
+		{"invalid scope token", []string{"a\"b"}, ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkClientCredentialsScopes(context.Background(), clientRepo, owner, tt.scopes)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("checkClientCredentialsScopes() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	owner, other := ulid.Make(), ulid.Make()
+	client := &repos.ClientModel{
+		BaseModel:               repos.BaseModel{ID: ulid.Make()},
+		UserID:                  owner,
+		Type:                    repos.ClientTypeConfidential,
+		AccessTokenFormat:       repos.AccessTokenFormatOpaque,
+		ClientCredentialsScopes: []string{"reports:read", "backup"},
+	}
+	transferred := &repos.ClientModel{
+		BaseModel:               repos.BaseModel{ID: ulid.Make()},
+		UserID:                  owner,
+		Type:                    repos.ClientTypeConfidential,
+		AccessTokenFormat:       repos.AccessTokenFormatOpaque,
+		ClientCredentialsScopes: []string{"payments:write"},
+	}
+	public := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: owner, Type: repos.ClientTypePublic, ClientCredentialsScopes: []string{"reports:read"}}
+	disabled := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: owner, Type: repos.ClientTypeConfidential}
+	foreign := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, UserID: other}
+	clientRepo := &fakeClientRepo{
+		clients: []*repos.ClientModel{client, transferred, public, disabled, foreign},
+		scopes: []*repos.ScopeModel{
+			{ClientID: client.ID, Name: "reports:read"},
+			{ClientID: client.ID, Name: "backup"},
+			// the scope was assigned before its app was transferred to another user
+			{ClientID: foreign.ID, Name: "payments:write"},
+		},
+	}
+	tests := []struct {
+		name   string
+		client *repos.ClientModel
+		scope  string
+		want   error
+		scopes []string
+	}{
+		{name: "all scopes", client: client, scopes: []string{"reports:read", "backup"}},
+		{name: "requested scope", client: client, scope: "backup", scopes: []string{"backup"}},
+		{name: "scope that is not allowed", client: client, scope: "backup admin", want: ErrInvalidScope},
+		{name: "user scope", client: client, scope: "openid", want: ErrInvalidScope},
+		{name: "scope no longer owned", client: transferred, want: ErrInvalidScope},
+		{name: "public client", client: public, want: ErrUnauthorizedClient},
+		{name: "grant disabled", client: disabled, want: ErrUnauthorizedClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauthRepo := &fakeOAuthRepo{}
+			a := &authService{
+				clientRepo: clientRepo,
+				oauthRepo:  oauthRepo,
+			}
+			access, err := a.oauthClientCredentials(context.Background(), tt.client, tt.scope)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("oauthClientCredentials() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			issued, err := oauthRepo.Find(context.Background(), repos.OAuthTokenAccess, hashTokenWeak(access))
+			if err != nil {
+				t.Fatal("issued token not stored")
+			}
+			if issued.UserID != nil || issued.ClientID != tt.client.ID {
+				t.Errorf("issued token belongs to client %s and user %v", issued.ClientID, issued.UserID)
+			}
+			if !slices.Equal(issued.Scopes, tt.scopes) {
+				t.Errorf("scopes = %v, want %v", issued.Scopes, tt.scopes)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+// GrantTypeDeviceCode is the grant type used by devices to poll the token endpoint (RFC 8628).
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	deviceCodeLifetime = 10 * time.Minute
+	// deviceCodeInterval is the minimum time a device has to wait between polling requests.
+	deviceCodeInterval = 5 * time.Second
+	// deviceCodeSlowDown is added to the interval every time a device polls too fast.
+	deviceCodeSlowDown = 5 * time.Second
+	// userCodeCharset contains no vowels to avoid accidentally forming words and no easily confused characters.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+const (
+	deviceStatusPending  = "pending"
+	deviceStatusApproved = "approved"
+	deviceStatusDenied   = "denied"
+)
+
+type DeviceAuthorization struct {
+	DeviceCode string
+	// UserCode is formatted for display, e.g. WDJB-MJHT.
+	UserCode  string
+	ExpiresIn time.Duration
+	Interval  time.Duration
+}
+
+// OAuthDeviceAuthorization starts a device authorization (RFC 8628).
+// The pending authorization is stored as two rows: the device code, which is polled by the device,
+// and the user code, which the user enters on /user/device and which records the decision of the user.
+func (a *authService) OAuthDeviceAuthorization(ctx context.Context, clientID ulid.ULID, clientSecret, scope string) (*DeviceAuthorization, error) {
+	client, err := a.VerifyClientCredentials(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	scopes, err := a.parseUserScopes(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	deviceCode := GenerateToken(64)
+	userCode := generateUserCode()
+	userCodeHash := hashTokenWeak(userCode)
+	grantID := ulid.Make()
+
+	deviceData, err := json.Marshal(oauthTokenData{
+		UserCodeHash: userCodeHash,
+		Interval:     int64(deviceCodeInterval.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+	_, err = a.oauthRepo.Create(ctx, client.ID, nil, grantID, repos.OAuthTokenDeviceCode, hashTokenWeak(deviceCode), nil, scopes, deviceData, deviceCodeLifetime)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	userCodeData, err := json.Marshal(oauthTokenData{
+		DeviceStatus: deviceStatusPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+	_, err = a.oauthRepo.Create(ctx, client.ID, nil, grantID, repos.OAuthTokenUserCode, userCodeHash, nil, scopes, userCodeData, deviceCodeLifetime)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	return &DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:],
+		ExpiresIn:  deviceCodeLifetime,
+		Interval:   deviceCodeInterval,
+	}, nil
+}
+
+// StartOAuthDeviceFlow looks up a pending device authorization by the user code the user entered
+// and stores it as the auth request of the session, so it can be approved on the consent page.
+// Devices always require consent, because the user has to confirm that they want to sign in on the device.
+func (a *authService) StartOAuthDeviceFlow(ctx context.Context, userCode string) error {
+	userID := a.AuthenticatedUserID(ctx)
+	if userID == (ulid.ULID{}) {
+		return ErrLoginRequired
+	}
+
+	hash := hashTokenWeak(normalizeUserCode(userCode))
+	token, err := a.oauthRepo.Find(ctx, repos.OAuthTokenUserCode, hash)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			err = ErrInvalidGrant
+		}
+		return fmt.Errorf("start OAuth device flow: %w", err)
+	}
+	var data oauthTokenData
+	err = json.Unmarshal(token.Data, &data)
+	if err != nil {
+		return fmt.Errorf("start OAuth device flow: decode token data: %w", err)
+	}
+	if data.DeviceStatus != deviceStatusPending {
+		return fmt.Errorf("start OAuth device flow: %w", ErrInvalidGrant)
+	}
+
+	a.sessionManager.Put(ctx, "authRequest", AuthRequest{
+		ClientID:     token.ClientID,
+		Scopes:       token.Scopes,
+		NeedsConsent: true,
+		AuthTime:     a.AuthTime(ctx),
+		AMR:          a.AMR(ctx),
+		SID:          a.sessionManager.GetString(ctx, "sid"),
+		UserCodeHash: hash,
+	})
+	return nil
+}
+
+// OAuthDeviceConsent records the decision of the user for the device authorization of the session.
+// The device receives its tokens the next time it polls the token endpoint.
+func (a *authService) OAuthDeviceConsent(ctx context.Context, accept bool) error {
+	req, err := a.GetAuthRequest(ctx)
+	if err != nil {
+		return fmt.Errorf("OAuth device consent: %w", err)
+	}
+	if req.UserCodeHash == nil {
+		return fmt.Errorf("OAuth device consent: %w", ErrMissingRequiredSessionData)
+	}
+	a.sessionManager.Remove(ctx, "authRequest")
+
+	token, err := a.oauthRepo.Find(ctx, repos.OAuthTokenUserCode, req.UserCodeHash)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			err = ErrInvalidGrant
+		}
+		return fmt.Errorf("OAuth device consent: %w", err)
+	}
+	var tokenData oauthTokenData
+	err = json.Unmarshal(token.Data, &tokenData)
+	if err != nil {
+		return fmt.Errorf("OAuth device consent: decode token data: %w", err)
+	}
+	if tokenData.DeviceStatus != deviceStatusPending {
+		return fmt.Errorf("OAuth device consent: %w", ErrInvalidGrant)
+	}
+
+	if !accept {
+		data, err := json.Marshal(oauthTokenData{
+			DeviceStatus: deviceStatusDenied,
+		})
+		if err != nil {
+			return fmt.Errorf("OAuth device consent: %w", err)
+		}
+		err = a.oauthRepo.Update(ctx, token.ClientID, repos.OAuthTokenUserCode, token.TokenHash, nil, data)
+		if err != nil {
+			return fmt.Errorf("OAuth device consent: %w", err)
+		}
+		return nil
+	}
+
+	userID := a.AuthenticatedUserID(ctx)
+	_, err = a.oauthRepo.SetPermissions(ctx, token.ClientID, userID, token.Scopes)
+	if err != nil {
+		return fmt.Errorf("OAuth device consent: %w", err)
+	}
+
+	data, err := json.Marshal(oauthTokenData{
+		DeviceStatus: deviceStatusApproved,
+		AuthTime:     unixTime(req.AuthTime),
+		AMR:          req.AMR,
+		SID:          req.SID,
+	})
+	if err != nil {
+		return fmt.Errorf("OAuth device consent: %w", err)
+	}
+	err = a.oauthRepo.Update(ctx, token.ClientID, repos.OAuthTokenUserCode, token.TokenHash, &userID, data)
+	if err != nil {
+		return fmt.Errorf("OAuth device consent: %w", err)
+	}
+
+	a.rememberSessionClient(ctx, token.ClientID)
+	return nil
+}
+
+// checkDeviceAuthorization is called when a device polls the token endpoint.
+// It returns the user code row once the user approved the device. Its user ID and data are used to issue the tokens.
+// While the user has not decided yet, ErrAuthorizationPending or, if the device polls too fast, ErrSlowDown is returned.
+func (a *authService) checkDeviceAuthorization(ctx context.Context, device *repos.OAuthTokenModel) (*repos.OAuthTokenModel, error) {
+	var deviceData oauthTokenData
+	err := json.Unmarshal(device.Data, &deviceData)
+	if err != nil {
+		return nil, fmt.Errorf("check device authorization: decode token data: %w", err)
+	}
+
+	userCode, err := a.oauthRepo.Find(ctx, repos.OAuthTokenUserCode, deviceData.UserCodeHash)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoRecord) {
+			err = ErrInvalidGrant
+		}
+		return nil, fmt.Errorf("check device authorization: %w", err)
+	}
+	if userCode.ClientID != device.ClientID {
+		return nil, fmt.Errorf("check device authorization: %w", ErrInvalidGrant)
+	}
+	var userCodeData oauthTokenData
+	err = json.Unmarshal(userCode.Data, &userCodeData)
+	if err != nil {
+		return nil, fmt.Errorf("check device authorization: decode token data: %w", err)
+	}
+
+	switch userCodeData.DeviceStatus {
+	case deviceStatusApproved:
+		// concurrent polls can both see the approval, but only one of them can use up the device code in OAuthGenerateTokens
+		err = a.oauthRepo.Delete(ctx, userCode.ClientID, repos.OAuthTokenUserCode, userCode.TokenHash)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				err = ErrInvalidGrant
+			}
+			return nil, fmt.Errorf("check device authorization: %w", err)
+		}
+		return userCode, nil
+	case deviceStatusDenied:
+		err = a.oauthRepo.DeleteByGrant(ctx, device.ClientID, device.GrantID)
+		if err != nil {
+			return nil, fmt.Errorf("check device authorization: %w", err)
+		}
+		return nil, ErrAccessDenied
+	}
+
+	now := time.Now().Unix()
+	pollErr := ErrAuthorizationPending
+	if now-deviceData.LastPoll < deviceData.Interval {
+		deviceData.Interval += int64(deviceCodeSlowDown.Seconds())
+		pollErr = ErrSlowDown
+	}
+	deviceData.LastPoll = now
+	data, err := json.Marshal(deviceData)
+	if err != nil {
+		return nil, fmt.Errorf("check device authorization: %w", err)
+	}
+	err = a.oauthRepo.Update(ctx, device.ClientID, repos.OAuthTokenDeviceCode, device.TokenHash, nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("check device authorization: %w", err)
+	}
+	return nil, pollErr
+}
+
+func generateUserCode() string {
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharset))))
+		if err != nil {
+			panic(err)
+		}
+		code[i] = userCodeCharset[num.Int64()]
+	}
+	return string(code)
+}
+
+// normalizeUserCode makes user input case insensitive and removes separators like dashes and spaces.
+func normalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 'A' || r > 'Z' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+}
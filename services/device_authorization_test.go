@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestNormalizeUserCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"WDJB-MJHT", "WDJBMJHT"},
+		{"wdjb mjht", "WDJBMJHT"},
+		{" WdJb-mJhT\n", "WDJBMJHT"},
+		{"WDJB–MJHT", "WDJBMJHT"},
+		{"1234", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeUserCode(tt.input); got != tt.want {
+			t.Errorf("normalizeUserCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateUserCode(t *testing.T) {
+	code := generateUserCode()
+	if len(code) != userCodeLength || normalizeUserCode(code) != code {
+		t.Fatalf("invalid user code %q", code)
+	}
+	for _, c := range code {
+		if !slices.Contains([]rune(userCodeCharset), c) {
+			t.Fatalf("user code %q contains %q", code, c)
+		}
+	}
+}
+
+func TestCheckDeviceAuthorization(t *testing.T) {
+	clientID, otherClientID, userID := ulid.Make(), ulid.Make(), ulid.Make()
+	interval := int64(deviceCodeInterval.Seconds())
+	tests := []struct {
+		name         string
+		status       string
+		userCodeFrom ulid.ULID
+		lastPoll     int64
+		want         error
+		interval     int64
+	}{
+		{name: "pending", status: deviceStatusPending, userCodeFrom: clientID, lastPoll: time.Now().Add(-time.Minute).Unix(), want: ErrAuthorizationPending, interval: interval},
+		{name: "first poll", status: deviceStatusPending, userCodeFrom: clientID, want: ErrAuthorizationPending, interval: interval},
+		{name: "too fast", status: deviceStatusPending, userCodeFrom: clientID, lastPoll: time.Now().Unix(), want: ErrSlowDown, interval: interval + int64(deviceCodeSlowDown.Seconds())},
+		{name: "approved", status: deviceStatusApproved, userCodeFrom: clientID},
+		{name: "denied", status: deviceStatusDenied, userCodeFrom: clientID, want: ErrAccessDenied},
+		{name: "user code of another client", status: deviceStatusApproved, userCodeFrom: otherClientID, want: ErrInvalidGrant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grantID := ulid.Make()
+			userCodeHash := hashTokenWeak("BCDFGHJK")
+			deviceData, _ := json.Marshal(oauthTokenData{UserCodeHash: userCodeHash, Interval: interval, LastPoll: tt.lastPoll})
+			userCodeData, _ := json.Marshal(oauthTokenData{DeviceStatus: tt.status})
+			device := &repos.OAuthTokenModel{Category: repos.OAuthTokenDeviceCode, TokenHash: hashTokenWeak("device"), ClientID: clientID, GrantID: grantID, Data: deviceData}
+			userCode := &repos.OAuthTokenModel{Category: repos.OAuthTokenUserCode, TokenHash: userCodeHash, ClientID: tt.userCodeFrom, GrantID: grantID, Data: userCodeData}
+			if tt.status == deviceStatusApproved {
+				userCode.UserID = &userID
+			}
+			oauthRepo := &fakeOAuthRepo{tokens: []*repos.OAuthTokenModel{device, userCode}}
+			a := &authService{oauthRepo: oauthRepo}
+
+			approved, err := a.checkDeviceAuthorization(context.Background(), device)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("checkDeviceAuthorization() = %v, want %v", err, tt.want)
+			}
+			switch {
+			case tt.want == nil:
+				if approved == nil || approved.UserID == nil || *approved.UserID != userID {
+					t.Fatalf("expected the approved user code, got %+v", approved)
+				}
+				// the approval can only be used once
+				_, err = a.checkDeviceAuthorization(context.Background(), device)
+				if !errors.Is(err, ErrInvalidGrant) {
+					t.Errorf("second poll after approval = %v, want %v", err, ErrInvalidGrant)
+				}
+			case errors.Is(tt.want, ErrAccessDenied):
+				if len(oauthRepo.tokens) != 0 {
+					t.Errorf("denied authorization was not deleted")
+				}
+			case tt.interval != 0:
+				var data oauthTokenData
+				_ = json.Unmarshal(device.Data, &data)
+				if data.Interval != tt.interval {
+					t.Errorf("interval = %d, want %d", data.Interval, tt.interval)
+				}
+				if time.Now().Unix()-data.LastPoll > 1 {
+					t.Errorf("last poll was not updated")
+				}
+			}
+		})
+	}
+}
+
+func TestDeviceAuthorizationFlow(t *testing.T) {
+	userID := ulid.Make()
+	client := &repos.ClientModel{BaseModel: repos.BaseModel{ID: ulid.Make()}, Type: repos.ClientTypePublic}
+	oauthRepo := &fakeOAuthRepo{}
+	sessionManager := scs.New()
+	a := &authService{
+		clientRepo:     &fakeClientRepo{clients: []*repos.ClientModel{client}},
+		oauthRepo:      oauthRepo,
+		sessionManager: sessionManager,
+	}
+	ctx, err := sessionManager.Load(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := a.OAuthDeviceAuthorization(ctx, client.ID, "", "openid profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	device, err := oauthRepo.Find(ctx, repos.OAuthTokenDeviceCode, hashTokenWeak(auth.DeviceCode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.checkDeviceAuthorization(ctx, device)
+	if !errors.Is(err, ErrAuthorizationPending) {
+		t.Fatalf("poll before approval = %v, want %v", err, ErrAuthorizationPending)
+	}
+
+	err = a.StartOAuthDeviceFlow(ctx, auth.UserCode)
+	if !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("StartOAuthDeviceFlow() without login = %v, want %v", err, ErrLoginRequired)
+	}
+	sessionManager.Put(ctx, "authUserID", userID)
+	err = a.StartOAuthDeviceFlow(ctx, "BCDF-GHJK")
+	if !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("StartOAuthDeviceFlow() with unknown code = %v, want %v", err, ErrInvalidGrant)
+	}
+	// users may type the code in lower case and without the dash
+	err = a.StartOAuthDeviceFlow(ctx, normalizeUserCode(auth.UserCode)[:4]+" "+auth.UserCode[5:])
+	if err != nil {
+		t.Fatalf("StartOAuthDeviceFlow() = %v", err)
+	}
+	err = a.OAuthDeviceConsent(ctx, true)
+	if err != nil {
+		t.Fatalf("OAuthDeviceConsent() = %v", err)
+	}
+	err = a.StartOAuthDeviceFlow(ctx, auth.UserCode)
+	if !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("StartOAuthDeviceFlow() after approval = %v, want %v", err, ErrInvalidGrant)
+	}
+
+	approved, err := a.checkDeviceAuthorization(ctx, device)
+	if err != nil {
+		t.Fatalf("poll after approval = %v", err)
+	}
+	if approved.UserID == nil || *approved.UserID != userID || !slices.Equal(approved.Scopes, []string{"openid", "profile"}) {
+		t.Errorf("approved authorization = %+v", approved)
+	}
+	if p, err := oauthRepo.FindPermissions(ctx, client.ID, userID); err != nil || !slices.Equal(p.Scopes, []string{"openid", "profile"}) {
+		t.Errorf("permissions were not stored")
+	}
+}
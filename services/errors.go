@@ -12,6 +12,23 @@ var (
 	ErrReusedToken                = errors.New("reused-token")
 	ErrInvalidGrant               = errors.New("invalid-grant")
 	ErrUnsupportedGrantType       = errors.New("unsupported-grant-type")
+	ErrInvalidRequest             = errors.New("invalid-request")
+	ErrInvalidCodeVerifier        = errors.New("invalid-code-verifier")
+	ErrLoginRequired              = errors.New("login-required")
+	ErrReauthenticationRequired   = errors.New("reauthentication-required")
+	ErrConsentRequired            = errors.New("consent-required")
+	ErrUnauthorizedClient         = errors.New("unauthorized-client")
+	ErrAuthorizationPending       = errors.New("authorization-pending")
+	ErrSlowDown                   = errors.New("slow-down")
+	ErrAccessDenied               = errors.New("access-denied")
+	ErrInvalidTarget              = errors.New("invalid-target")
+	ErrInvalidAudience            = errors.New("invalid-audience")
+	ErrPasswordGrantNotAllowed    = errors.New("password-grant-not-allowed")
+	ErrInvalidGroupName           = errors.New("invalid-group-name")
+	ErrInvalidDomain              = errors.New("invalid-domain")
+	ErrInvalidGatewayRule         = errors.New("invalid-gateway-rule")
+	ErrInvalidMaxAuthAge          = errors.New("invalid-max-auth-age")
+	ErrInvalidGatewayHeader       = errors.New("invalid-gateway-header")
 
 	ErrInsufficientScope = errors.New("insufficient-scope")
 )
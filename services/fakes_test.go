@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("BASE_URL", "https://id.example.com")
+	os.Exit(m.Run())
+}
+
+// The fakes implement the repository methods used by the tested services.
+// Calling any other method panics because of the nil embedded interface.
+
+type fakeClientRepo struct {
+	repos.ClientRepository
+	clients []*repos.ClientModel
+	scopes  []*repos.ScopeModel
+}
+
+func (f *fakeClientRepo) Find(ctx context.Context, id ulid.ULID) (*repos.ClientModel, error) {
+	for _, c := range f.clients {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
+
+func (f *fakeClientRepo) FindByUserAndID(ctx context.Context, userID, clientID ulid.ULID) (*repos.ClientModel, error) {
+	c, err := f.Find(ctx, clientID)
+	if err != nil || c.UserID != userID {
+		return nil, repos.ErrNoRecord
+	}
+	return c, nil
+}
+
+func (f *fakeClientRepo) FindScopeByName(ctx context.Context, name string) (*repos.ScopeModel, error) {
+	for _, s := range f.scopes {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
+
+type fakeUserRepo struct {
+	repos.UserRepository
+	users []*repos.UserModel
+	// appPasswords maps password hashes to app passwords.
+	appPasswords map[string]*repos.AppPassword
+}
+
+func (f *fakeUserRepo) Find(ctx context.Context, id ulid.ULID) (*repos.UserModel, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
+
+func (f *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*repos.UserModel, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
+
+func (f *fakeUserRepo) FindAppPasswordByHash(ctx context.Context, userID ulid.ULID, passwordHash []byte) (*repos.AppPassword, error) {
+	p, ok := f.appPasswords[string(passwordHash)]
+	if !ok || p.UserID != userID {
+		return nil, repos.ErrNoRecord
+	}
+	return p, nil
+}
+
+func (f *fakeUserRepo) UpdateAppPasswordLastUsed(ctx context.Context, userID, id ulid.ULID) error {
+	for _, p := range f.appPasswords {
+		if p.UserID == userID && p.ID == id {
+			p.LastUsed = time.Now()
+			return nil
+		}
+	}
+	return repos.ErrNoRecord
+}
+
+type fakeOAuthRepo struct {
+	repos.OAuthRepository
+	permissions []*repos.PermissionsModel
+	tokens      []*repos.OAuthTokenModel
+}
+
+func (f *fakeOAuthRepo) Create(ctx context.Context, clientID ulid.ULID, userID *ulid.ULID, grantID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte, redirectURI *url.URL, scopes []string, data []byte, lifetime time.Duration) (*repos.OAuthTokenModel, error) {
+	token := &repos.OAuthTokenModel{
+		CreatedAt:   time.Now(),
+		Category:    category,
+		TokenHash:   tokenHash,
+		RedirectURI: redirectURI,
+		ClientID:    clientID,
+		UserID:      userID,
+		Scopes:      scopes,
+		Data:        data,
+		Expires:     time.Now().Add(lifetime),
+		GrantID:     grantID,
+	}
+	f.tokens = append(f.tokens, token)
+	return token, nil
+}
+
+func (f *fakeOAuthRepo) Find(ctx context.Context, category repos.OAuthTokenCategory, tokenHash []byte) (*repos.OAuthTokenModel, error) {
+	for _, t := range f.tokens {
+		if t.Category == category && bytes.Equal(t.TokenHash, tokenHash) {
+			return t, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
+
+func (f *fakeOAuthRepo) Update(ctx context.Context, clientID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte, userID *ulid.ULID, data []byte) error {
+	t, err := f.Find(ctx, category, tokenHash)
+	if err != nil || t.ClientID != clientID {
+		return repos.ErrNoRecord
+	}
+	if userID != nil {
+		t.UserID = userID
+	}
+	t.Data = data
+	return nil
+}
+
+func (f *fakeOAuthRepo) Delete(ctx context.Context, clientID ulid.ULID, category repos.OAuthTokenCategory, tokenHash []byte) error {
+	for i, t := range f.tokens {
+		if t.ClientID == clientID && t.Category == category && bytes.Equal(t.TokenHash, tokenHash) {
+			f.tokens = append(f.tokens[:i], f.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return repos.ErrNoRecord
+}
+
+func (f *fakeOAuthRepo) DeleteByGrant(ctx context.Context, clientID, grantID ulid.ULID) error {
+	f.tokens = slices.DeleteFunc(f.tokens, func(t *repos.OAuthTokenModel) bool {
+		return t.ClientID == clientID && t.GrantID == grantID
+	})
+	return nil
+}
+
+func (f *fakeOAuthRepo) SetPermissions(ctx context.Context, clientID, userID ulid.ULID, scopes []string) (*repos.PermissionsModel, error) {
+	f.permissions = slices.DeleteFunc(f.permissions, func(p *repos.PermissionsModel) bool {
+		return p.ClientID == clientID && p.UserID == userID
+	})
+	p := &repos.PermissionsModel{CreatedAt: time.Now(), ClientID: clientID, UserID: userID, Scopes: scopes}
+	f.permissions = append(f.permissions, p)
+	return p, nil
+}
+
+func (f *fakeOAuthRepo) FindPermissions(ctx context.Context, clientID, userID ulid.ULID) (*repos.PermissionsModel, error) {
+	for _, p := range f.permissions {
+		if p.ClientID == clientID && p.UserID == userID {
+			return p, nil
+		}
+	}
+	return nil, repos.ErrNoRecord
+}
@@ -1,67 +1,167 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/juho05/h-id/config"
+	"github.com/juho05/log"
 	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/config"
+	"github.com/juho05/h-id/repos"
 )
 
 type AuthGatewayService interface {
-	IsAuthorized(userID ulid.ULID, domain string) bool
+	// Authorize decides whether the user may send a request with method to path on domain.
+	// userID is the zero ID for unauthenticated requests.
+	Authorize(userID ulid.ULID, domain, method, path string) GatewayDecision
+	// RequiresStepUp reports whether a session with authTime and amr does not satisfy the step-up policy of domain
+	// and the user has to verify their second factor again.
+	RequiresStepUp(domain string, authTime time.Time, amr []string) bool
+	// Headers returns the identity headers that are returned for allowed requests to domain.
+	Headers(domain string) []repos.GatewayHeader
+	// BearerScope returns the scope access tokens need to access domain. It is empty if access tokens are not accepted.
+	BearerScope(domain string) string
 	IsAllowedURL(url string) bool
 	IsAllowedDomain(url string) bool
+	// Groups returns the groups of the user. They are included in ID tokens and user info responses for the groups scope.
+	Groups(userID ulid.ULID) []string
+
+	// Reload replaces the in-memory access rules with the current state of the database.
+	Reload(ctx context.Context) error
+	RunConfigWatcher(ctx context.Context, reload <-chan os.Signal)
+
+	FindGroups(ctx context.Context) ([]*repos.GatewayGroupModel, error)
+	FindGroup(ctx context.Context, id ulid.ULID) (*repos.GatewayGroupModel, error)
+	CreateGroup(ctx context.Context, name string) (*repos.GatewayGroupModel, error)
+	UpdateGroup(ctx context.Context, id ulid.ULID, name string) (*repos.GatewayGroupModel, error)
+	DeleteGroup(ctx context.Context, id ulid.ULID) error
+	FindGroupMembers(ctx context.Context, groupID ulid.ULID) ([]*repos.UserModel, error)
+	AddGroupMember(ctx context.Context, groupID, userID ulid.ULID) error
+	RemoveGroupMember(ctx context.Context, groupID, userID ulid.ULID) error
+
+	FindDomains(ctx context.Context) ([]*repos.GatewayDomainModel, error)
+	FindDomain(ctx context.Context, id ulid.ULID) (*repos.GatewayDomainModel, error)
+	CreateDomain(ctx context.Context, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error)
+	UpdateDomain(ctx context.Context, id ulid.ULID, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error)
+	DeleteDomain(ctx context.Context, id ulid.ULID) error
 }
 
+// gatewayConfigPollInterval is the interval in which the config file is checked for changes.
+const gatewayConfigPollInterval = 10 * time.Second
+
+var (
+	gatewayGroupNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+	gatewayDomainRegex    = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// GatewayHeaders contains all supported identity headers.
+var GatewayHeaders = []repos.GatewayHeader{repos.GatewayHeaderUser, repos.GatewayHeaderName, repos.GatewayHeaderEmail, repos.GatewayHeaderGroups, repos.GatewayHeaderAssertion}
+
 type domainConfig struct {
-	users  map[string]struct{}
-	groups map[string]struct{}
+	users  map[ulid.ULID]struct{}
+	groups map[ulid.ULID]struct{}
+	rules  []gatewayRule
+	// maxAuthAge is the maximum time since the last login or step-up. Zero disables the check.
+	maxAuthAge time.Duration
+	require2FA bool
+	headers    []repos.GatewayHeader
+	// bearerScope is the scope required for access tokens. Empty disables access tokens.
+	bearerScope string
 }
 
-type userConfig struct {
-	name   string
-	groups []string
+// gatewayConfig is an immutable snapshot of the access rules stored in the database.
+type gatewayConfig struct {
+	// userGroups contains the IDs of the groups of every user.
+	userGroups map[ulid.ULID][]ulid.ULID
+	groupNames map[ulid.ULID]string
+	domains    map[string]domainConfig
 }
 
 type authGatewayService struct {
-	users   map[ulid.ULID]userConfig
-	domains map[string]domainConfig
+	gatewayRepo repos.GatewayRepository
+	userRepo    repos.UserRepository
+	clientRepo  repos.ClientRepository
+
+	config   atomic.Pointer[gatewayConfig]
+	reloadMu sync.Mutex
+	// configHash is the SHA-256 hash of the contents of the config file when it was last applied.
+	configHash [32]byte
+	// invalidConfigHash is the SHA-256 hash of the last config file that failed validation.
+	invalidConfigHash [32]byte
+	// configReadFailed is true while the config file cannot be read.
+	configReadFailed bool
 }
 
-func NewAuthGatewayService() (AuthGatewayService, error) {
+// NewAuthGatewayService loads the access rules from the database.
+// If AUTH_GATEWAY_CONFIG is set and no config file was imported before, the file is imported first.
+func NewAuthGatewayService(ctx context.Context, gatewayRepo repos.GatewayRepository, userRepo repos.UserRepository, clientRepo repos.ClientRepository) (AuthGatewayService, error) {
 	a := &authGatewayService{
-		users:   make(map[ulid.ULID]userConfig),
-		domains: make(map[string]domainConfig),
+		gatewayRepo: gatewayRepo,
+		userRepo:    userRepo,
+		clientRepo:  clientRepo,
+	}
+	err := a.importConfig(ctx)
+	if err != nil {
+		return nil, err
 	}
-	err := a.loadConfig()
+	err = a.Reload(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
-func (a *authGatewayService) IsAuthorized(userID ulid.ULID, domain string) bool {
-	d, ok := a.findDomainConfig(domain)
+func (a *authGatewayService) Authorize(userID ulid.ULID, domain, method, path string) GatewayDecision {
+	c := a.config.Load()
+	d, ok := c.findDomainConfig(domain)
 	if !ok {
-		return false
+		return GatewayDeny
 	}
-	user, ok := a.users[userID]
+	return d.authorize(userID, c.userGroups[userID], method, path)
+}
+
+func (a *authGatewayService) RequiresStepUp(domain string, authTime time.Time, amr []string) bool {
+	d, ok := a.config.Load().findDomainConfig(domain)
 	if !ok {
 		return false
 	}
-	if _, ok := d.users[user.name]; ok {
+	if d.maxAuthAge > 0 && time.Since(authTime) > d.maxAuthAge {
 		return true
 	}
-	for _, group := range user.groups {
-		if _, ok := d.groups[group]; ok {
-			return true
-		}
+	return d.require2FA && !slices.Contains(amr, AMROTP) && !slices.Contains(amr, AMRPasskey)
+}
+
+func (a *authGatewayService) Headers(domain string) []repos.GatewayHeader {
+	d, _ := a.config.Load().findDomainConfig(domain)
+	return d.headers
+}
+
+func (a *authGatewayService) BearerScope(domain string) string {
+	d, _ := a.config.Load().findDomainConfig(domain)
+	return d.bearerScope
+}
+
+func (a *authGatewayService) Groups(userID ulid.ULID) []string {
+	c := a.config.Load()
+	groups := make([]string, 0, len(c.userGroups[userID]))
+	for _, id := range c.userGroups[userID] {
+		groups = append(groups, c.groupNames[id])
 	}
-	return false
+	slices.Sort(groups)
+	return groups
 }
 
 func (a *authGatewayService) IsAllowedURL(uri string) bool {
@@ -73,15 +173,15 @@ func (a *authGatewayService) IsAllowedURL(uri string) bool {
 }
 
 func (a *authGatewayService) IsAllowedDomain(domain string) bool {
-	_, ok := a.findDomainConfig(domain)
+	_, ok := a.config.Load().findDomainConfig(domain)
 	return ok
 }
 
-func (a *authGatewayService) findDomainConfig(domain string) (domainConfig, bool) {
+func (c *gatewayConfig) findDomainConfig(domain string) (domainConfig, bool) {
 	if domain == "" {
 		return domainConfig{}, false
 	}
-	d, ok := a.domains[domain]
+	d, ok := c.domains[domain]
 	for !ok {
 		parts := strings.Split(domain, ".")
 		if parts[0] == "*" {
@@ -92,61 +192,657 @@ func (a *authGatewayService) findDomainConfig(domain string) (domainConfig, bool
 		}
 		parts[0] = "*"
 		domain = strings.Join(parts, ".")
-		d, ok = a.domains[domain]
+		d, ok = c.domains[domain]
 	}
 	return d, ok
 }
 
-func (a *authGatewayService) loadConfig() error {
-	if config.AuthGatewayConfig() == "" {
+func (a *authGatewayService) Reload(ctx context.Context) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	groups, err := a.gatewayRepo.FindGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("reload auth gateway config: %w", err)
+	}
+	members, err := a.gatewayRepo.FindAllGroupMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("reload auth gateway config: %w", err)
+	}
+	domains, err := a.gatewayRepo.FindDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("reload auth gateway config: %w", err)
+	}
+
+	c := &gatewayConfig{
+		userGroups: make(map[ulid.ULID][]ulid.ULID),
+		groupNames: make(map[ulid.ULID]string, len(groups)),
+		domains:    make(map[string]domainConfig, len(domains)),
+	}
+	for _, g := range groups {
+		c.groupNames[g.ID] = g.Name
+	}
+	for _, m := range members {
+		c.userGroups[m.UserID] = append(c.userGroups[m.UserID], m.GroupID)
+	}
+	for _, d := range domains {
+		domainConf := domainConfig{
+			users:       make(map[ulid.ULID]struct{}, len(d.Users)),
+			groups:      make(map[ulid.ULID]struct{}, len(d.Groups)),
+			rules:       make([]gatewayRule, len(d.Rules)),
+			maxAuthAge:  d.MaxAuthAge,
+			require2FA:  d.Require2FA,
+			headers:     d.Headers,
+			bearerScope: d.BearerScope,
+		}
+		for i, rule := range d.Rules {
+			domainConf.rules[i], err = compileGatewayRule(rule)
+			if err != nil {
+				return fmt.Errorf("reload auth gateway config: domain %s: %w", d.Domain, err)
+			}
+		}
+		for _, user := range d.Users {
+			domainConf.users[user] = struct{}{}
+		}
+		for _, group := range d.Groups {
+			domainConf.groups[group] = struct{}{}
+		}
+		c.domains[d.Domain] = domainConf
+	}
+	a.config.Store(c)
+	return nil
+}
+
+// reload is called after every change to the access rules.
+// The change has already been persisted, so a failure is only logged and picked up by the next reload.
+func (a *authGatewayService) reload(ctx context.Context) {
+	err := a.Reload(ctx)
+	if err != nil {
+		log.Errorf("Failed to reload auth gateway config: %s", err)
+	}
+}
+
+func (a *authGatewayService) FindGroups(ctx context.Context) ([]*repos.GatewayGroupModel, error) {
+	return a.gatewayRepo.FindGroups(ctx)
+}
+
+func (a *authGatewayService) FindGroup(ctx context.Context, id ulid.ULID) (*repos.GatewayGroupModel, error) {
+	return a.gatewayRepo.FindGroup(ctx, id)
+}
+
+func (a *authGatewayService) CreateGroup(ctx context.Context, name string) (*repos.GatewayGroupModel, error) {
+	if !gatewayGroupNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("create gateway group: %w", ErrInvalidGroupName)
+	}
+	group, err := a.gatewayRepo.CreateGroup(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("create gateway group: %w", err)
+	}
+	a.reload(ctx)
+	return group, nil
+}
+
+func (a *authGatewayService) UpdateGroup(ctx context.Context, id ulid.ULID, name string) (*repos.GatewayGroupModel, error) {
+	if !gatewayGroupNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("update gateway group: %w", ErrInvalidGroupName)
+	}
+	group, err := a.gatewayRepo.UpdateGroup(ctx, id, name)
+	if err != nil {
+		return nil, fmt.Errorf("update gateway group: %w", err)
+	}
+	a.reload(ctx)
+	return group, nil
+}
+
+// DeleteGroup deletes the group and its memberships. Domains keep the ID of the group,
+// but it no longer grants access to anyone.
+func (a *authGatewayService) DeleteGroup(ctx context.Context, id ulid.ULID) error {
+	err := a.gatewayRepo.DeleteGroup(ctx, id)
+	if err != nil {
+		return fmt.Errorf("delete gateway group: %w", err)
+	}
+	a.reload(ctx)
+	return nil
+}
+
+func (a *authGatewayService) FindGroupMembers(ctx context.Context, groupID ulid.ULID) ([]*repos.UserModel, error) {
+	members, err := a.gatewayRepo.FindGroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("find gateway group members: %w", err)
+	}
+	users := make([]*repos.UserModel, 0, len(members))
+	for _, m := range members {
+		user, err := a.userRepo.Find(ctx, m.UserID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			return nil, fmt.Errorf("find gateway group members: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (a *authGatewayService) AddGroupMember(ctx context.Context, groupID, userID ulid.ULID) error {
+	err := a.gatewayRepo.AddGroupMember(ctx, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("add gateway group member: %w", err)
+	}
+	a.reload(ctx)
+	return nil
+}
+
+func (a *authGatewayService) RemoveGroupMember(ctx context.Context, groupID, userID ulid.ULID) error {
+	err := a.gatewayRepo.RemoveGroupMember(ctx, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("remove gateway group member: %w", err)
+	}
+	a.reload(ctx)
+	return nil
+}
+
+func (a *authGatewayService) FindDomains(ctx context.Context) ([]*repos.GatewayDomainModel, error) {
+	return a.gatewayRepo.FindDomains(ctx)
+}
+
+func (a *authGatewayService) FindDomain(ctx context.Context, id ulid.ULID) (*repos.GatewayDomainModel, error) {
+	return a.gatewayRepo.FindDomain(ctx, id)
+}
+
+func (a *authGatewayService) CreateDomain(ctx context.Context, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error) {
+	if !gatewayDomainRegex.MatchString(domain) {
+		return nil, fmt.Errorf("create gateway domain: %w", ErrInvalidDomain)
+	}
+	if maxAuthAge < 0 {
+		return nil, fmt.Errorf("create gateway domain: %w", ErrInvalidMaxAuthAge)
+	}
+	for _, header := range headers {
+		if !slices.Contains(GatewayHeaders, header) {
+			return nil, fmt.Errorf("create gateway domain: %w", ErrInvalidGatewayHeader)
+		}
+	}
+	err := a.checkBearerScope(ctx, bearerScope)
+	if err != nil {
+		return nil, fmt.Errorf("create gateway domain: %w", err)
+	}
+	for _, rule := range rules {
+		if _, err := compileGatewayRule(rule); err != nil {
+			return nil, fmt.Errorf("create gateway domain: %w", err)
+		}
+	}
+	d, err := a.gatewayRepo.CreateDomain(ctx, domain, groups, users, rules, maxAuthAge, require2FA, headers, bearerScope)
+	if err != nil {
+		return nil, fmt.Errorf("create gateway domain: %w", err)
+	}
+	a.reload(ctx)
+	return d, nil
+}
+
+func (a *authGatewayService) UpdateDomain(ctx context.Context, id ulid.ULID, domain string, groups, users []ulid.ULID, rules []repos.GatewayRuleModel, maxAuthAge time.Duration, require2FA bool, headers []repos.GatewayHeader, bearerScope string) (*repos.GatewayDomainModel, error) {
+	if !gatewayDomainRegex.MatchString(domain) {
+		return nil, fmt.Errorf("update gateway domain: %w", ErrInvalidDomain)
+	}
+	if maxAuthAge < 0 {
+		return nil, fmt.Errorf("update gateway domain: %w", ErrInvalidMaxAuthAge)
+	}
+	for _, header := range headers {
+		if !slices.Contains(GatewayHeaders, header) {
+			return nil, fmt.Errorf("update gateway domain: %w", ErrInvalidGatewayHeader)
+		}
+	}
+	err := a.checkBearerScope(ctx, bearerScope)
+	if err != nil {
+		return nil, fmt.Errorf("update gateway domain: %w", err)
+	}
+	for _, rule := range rules {
+		if _, err := compileGatewayRule(rule); err != nil {
+			return nil, fmt.Errorf("update gateway domain: %w", err)
+		}
+	}
+	d, err := a.gatewayRepo.UpdateDomain(ctx, id, domain, groups, users, rules, maxAuthAge, require2FA, headers, bearerScope)
+	if err != nil {
+		return nil, fmt.Errorf("update gateway domain: %w", err)
+	}
+	a.reload(ctx)
+	return d, nil
+}
+
+// checkBearerScope checks that scope is empty or a custom scope registered by a client.
+// Scopes that describe a user (openid, profile, email, groups) are part of almost every access token, so they are rejected.
+func (a *authGatewayService) checkBearerScope(ctx context.Context, scope string) error {
+	if scope == "" {
 		return nil
 	}
-	file, err := os.Open(config.AuthGatewayConfig())
+	if !isScopeToken(scope) || slices.Contains(userScopes, scope) {
+		return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+	}
+	_, err := a.clientRepo.FindScopeByName(ctx, scope)
 	if err != nil {
-		return fmt.Errorf("load auth gateway config: %w", err)
+		if errors.Is(err, repos.ErrNoRecord) {
+			err = ErrInvalidScope
+		}
+		return fmt.Errorf("%w: %s", err, scope)
+	}
+	return nil
+}
+
+func (a *authGatewayService) DeleteDomain(ctx context.Context, id ulid.ULID) error {
+	err := a.gatewayRepo.DeleteDomain(ctx, id)
+	if err != nil {
+		return fmt.Errorf("delete gateway domain: %w", err)
 	}
-	defer file.Close()
-	type conf struct {
-		AllowedDomains []string `json:"allowedDomains"`
-		Users          map[string]struct {
-			ID     string   `json:"id"`
-			Groups []string `json:"groups"`
-		} `json:"users"`
-		Domains map[string]struct {
-			Users  []string `json:"users"`
-			Groups []string `json:"groups"`
+	a.reload(ctx)
+	return nil
+}
+
+// gatewayConfigFile is the format of the file at AUTH_GATEWAY_CONFIG.
+type gatewayConfigFile struct {
+	Users map[string]struct {
+		ID     string   `json:"id"`
+		Groups []string `json:"groups"`
+	} `json:"users"`
+	Domains map[string]struct {
+		Users  []string                `json:"users"`
+		Groups []string                `json:"groups"`
+		Rules  []gatewayConfigFileRule `json:"rules"`
+		// MaxAuthAge is the maximum time since the last login in minutes.
+		MaxAuthAge int  `json:"maxAuthAge"`
+		Require2FA bool `json:"require2FA"`
+		// Headers defaults to repos.DefaultGatewayHeaders if it is not set.
+		Headers     *[]repos.GatewayHeader `json:"headers"`
+		BearerScope string                 `json:"bearerScope"`
+	} `json:"domains"`
+
+	// userIDs contains the parsed ID of every user in Users.
+	userIDs map[string]ulid.ULID
+	// groups contains the names of all groups referenced in Users and Domains.
+	groups []string
+}
+
+type gatewayConfigFileRule struct {
+	Policy  repos.GatewayPolicy `json:"policy"`
+	Methods []string            `json:"methods"`
+	Path    string              `json:"path"`
+	Users   []string            `json:"users"`
+	Groups  []string            `json:"groups"`
+}
+
+// model converts the rule to a repos.GatewayRuleModel with the user and group names resolved to IDs.
+func (r gatewayConfigFileRule) model(userIDs, groupIDs map[string]ulid.ULID) repos.GatewayRuleModel {
+	rule := repos.GatewayRuleModel{
+		Policy: r.Policy,
+		Path:   r.Path,
+	}
+	for _, m := range r.Methods {
+		rule.Methods = append(rule.Methods, strings.ToUpper(m))
+	}
+	for _, name := range r.Users {
+		if id, ok := userIDs[name]; ok {
+			rule.Users = append(rule.Users, id)
+		}
+	}
+	for _, name := range r.Groups {
+		if id, ok := groupIDs[name]; ok {
+			rule.Groups = append(rule.Groups, id)
 		}
 	}
-	var c conf
-	err = json.NewDecoder(file).Decode(&c)
+	return rule
+}
+
+// parseConfigFile decodes and validates the contents of an auth gateway config file.
+func parseConfigFile(data []byte) (*gatewayConfigFile, error) {
+	var c gatewayConfigFile
+	err := json.Unmarshal(data, &c)
 	if err != nil {
-		return fmt.Errorf("load auth gateway config: %w", err)
+		return nil, err
 	}
+
+	c.userIDs = make(map[string]ulid.ULID, len(c.Users))
 	for name, u := range c.Users {
 		id, err := ulid.Parse(u.ID)
 		if err != nil {
-			return fmt.Errorf("load auth gateway config: load user config %s: invalid id: %w", name, err)
+			return nil, fmt.Errorf("load user config %s: invalid id: %w", name, err)
+		}
+		c.userIDs[name] = id
+		c.groups = append(c.groups, u.Groups...)
+	}
+	for domain, d := range c.Domains {
+		if !gatewayDomainRegex.MatchString(domain) {
+			return nil, fmt.Errorf("domain %s: %w", domain, ErrInvalidDomain)
 		}
-		if u.Groups == nil {
-			u.Groups = make([]string, 0)
+		if d.MaxAuthAge < 0 {
+			return nil, fmt.Errorf("domain %s: %w", domain, ErrInvalidMaxAuthAge)
 		}
-		a.users[id] = userConfig{
-			name:   name,
-			groups: u.Groups,
+		if d.Headers != nil {
+			for _, header := range *d.Headers {
+				if !slices.Contains(GatewayHeaders, header) {
+					return nil, fmt.Errorf("domain %s: header %s: %w", domain, header, ErrInvalidGatewayHeader)
+				}
+			}
 		}
-	}
-	for name, d := range c.Domains {
-		domainConf := domainConfig{
-			users:  make(map[string]struct{}),
-			groups: make(map[string]struct{}),
+		if d.BearerScope != "" && (!isScopeToken(d.BearerScope) || slices.Contains(userScopes, d.BearerScope)) {
+			return nil, fmt.Errorf("domain %s: %w: %s", domain, ErrInvalidScope, d.BearerScope)
 		}
 		for _, user := range d.Users {
-			domainConf.users[user] = struct{}{}
+			if _, ok := c.userIDs[user]; !ok {
+				return nil, fmt.Errorf("domain %s: unknown user %s", domain, user)
+			}
 		}
-		for _, group := range d.Groups {
-			domainConf.groups[group] = struct{}{}
+		c.groups = append(c.groups, d.Groups...)
+		for i, rule := range d.Rules {
+			for _, user := range rule.Users {
+				if _, ok := c.userIDs[user]; !ok {
+					return nil, fmt.Errorf("domain %s: rule %d: unknown user %s", domain, i+1, user)
+				}
+			}
+			c.groups = append(c.groups, rule.Groups...)
+			// group IDs are not known yet, so a placeholder ID is used for every group
+			placeholders := make(map[string]ulid.ULID, len(rule.Groups))
+			for _, g := range rule.Groups {
+				placeholders[g] = ulid.ULID{}
+			}
+			_, err := compileGatewayRule(rule.model(c.userIDs, placeholders))
+			if err != nil {
+				return nil, fmt.Errorf("domain %s: rule %d: %w", domain, i+1, err)
+			}
+		}
+	}
+	slices.Sort(c.groups)
+	c.groups = slices.Compact(c.groups)
+	for _, name := range c.groups {
+		if !gatewayGroupNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("group %s: %w", name, ErrInvalidGroupName)
+		}
+	}
+	return &c, nil
+}
+
+// readConfigFile reads and validates the file at AUTH_GATEWAY_CONFIG.
+// The returned hash is used to detect changes to the file.
+func readConfigFile() (c *gatewayConfigFile, data []byte, hash [32]byte, err error) {
+	data, err = os.ReadFile(config.AuthGatewayConfig())
+	if err != nil {
+		return nil, nil, hash, err
+	}
+	hash = sha256.Sum256(data)
+	c, err = parseConfigFile(data)
+	return c, data, hash, err
+}
+
+// importConfig applies the file at AUTH_GATEWAY_CONFIG to the database if it differs from the last applied file.
+// This imports the file on the first start and applies changes that were made while H-ID was not running.
+// Later changes to the file are applied by RunConfigWatcher.
+func (a *authGatewayService) importConfig(ctx context.Context) error {
+	if config.AuthGatewayConfig() == "" {
+		return nil
+	}
+	c, data, hash, err := readConfigFile()
+	if err != nil {
+		return fmt.Errorf("import auth gateway config: %w", err)
+	}
+	applied, err := a.applyConfigFile(ctx, c, data, hash)
+	if err != nil {
+		return fmt.Errorf("import auth gateway config: %w", err)
+	}
+	a.configHash = hash
+	if applied {
+		log.Infof("Imported %d auth gateway groups and %d domains from %s", len(c.groups), len(c.Domains), config.AuthGatewayConfig())
+	}
+	return nil
+}
+
+// applyConfigFile writes the contents of the config file to the database in a single transaction
+// unless hash matches the last applied file. It returns false if nothing was applied.
+// The file is authoritative for the users and domains it contains: the group memberships of these users
+// and the access rules of these domains are replaced. Domains that were removed from the file since it was last applied
+// are deleted and removed users lose the group memberships the file gave them.
+// All other groups, members and domains, e.g. the ones created in the admin UI, are left untouched.
+// Users that do not exist in the database are skipped.
+func (a *authGatewayService) applyConfigFile(ctx context.Context, c *gatewayConfigFile, data []byte, hash [32]byte) (bool, error) {
+	for domain, d := range c.Domains {
+		err := a.checkBearerScope(ctx, d.BearerScope)
+		if err != nil {
+			return false, fmt.Errorf("domain %s: %w", domain, err)
+		}
+	}
+	userIDs := make(map[string]ulid.ULID, len(c.userIDs))
+	for name, id := range c.userIDs {
+		_, err := a.userRepo.Find(ctx, id)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				log.Warnf("Skipping unknown auth gateway user %s (%s)", name, id)
+				continue
+			}
+			return false, err
+		}
+		userIDs[name] = id
+	}
+
+	applied := false
+	err := a.gatewayRepo.Transaction(ctx, func(repo repos.GatewayRepository) error {
+		var previous *gatewayConfigFile
+		file, err := repo.FindConfigFile(ctx)
+		if err == nil {
+			if bytes.Equal(file.Hash, hash[:]) {
+				return nil
+			}
+			previous, err = parseConfigFile(file.Data)
+			if err != nil {
+				log.Warnf("Failed to parse the previously applied auth gateway config, removed users and domains are kept: %s", err)
+				previous = nil
+			}
+		} else if !errors.Is(err, repos.ErrNoRecord) {
+			return err
+		}
+
+		err = writeConfigFile(ctx, repo, c, previous, userIDs)
+		if err != nil {
+			return err
+		}
+		applied = true
+		return repo.SetConfigFile(ctx, hash[:], data)
+	})
+	return applied, err
+}
+
+// writeConfigFile writes c to repo. userIDs contains the users of c that exist in the database.
+// previous is the last applied file or nil.
+func writeConfigFile(ctx context.Context, repo repos.GatewayRepository, c, previous *gatewayConfigFile, userIDs map[string]ulid.ULID) error {
+	repoGroups, err := repo.FindGroups(ctx)
+	if err != nil {
+		return err
+	}
+	groupIDs := make(map[string]ulid.ULID, len(repoGroups))
+	for _, g := range repoGroups {
+		groupIDs[g.Name] = g.ID
+	}
+	for _, name := range c.groups {
+		if _, ok := groupIDs[name]; ok {
+			continue
+		}
+		group, err := repo.CreateGroup(ctx, name)
+		if err != nil {
+			return err
+		}
+		groupIDs[name] = group.ID
+	}
+
+	members, err := repo.FindAllGroupMembers(ctx)
+	if err != nil {
+		return err
+	}
+	memberships := make(map[ulid.ULID][]ulid.ULID)
+	for _, m := range members {
+		memberships[m.UserID] = append(memberships[m.UserID], m.GroupID)
+	}
+	for name, u := range c.Users {
+		id, ok := userIDs[name]
+		if !ok {
+			continue
+		}
+		groups := make([]ulid.ULID, len(u.Groups))
+		for i, group := range u.Groups {
+			groups[i] = groupIDs[group]
+			if slices.Contains(memberships[id], groups[i]) {
+				continue
+			}
+			err = repo.AddGroupMember(ctx, groups[i], id)
+			if err != nil && !errors.Is(err, repos.ErrExists) {
+				return err
+			}
+		}
+		for _, group := range memberships[id] {
+			if slices.Contains(groups, group) {
+				continue
+			}
+			err = repo.RemoveGroupMember(ctx, group, id)
+			if err != nil && !errors.Is(err, repos.ErrNoRecord) {
+				return err
+			}
+		}
+	}
+
+	repoDomains, err := repo.FindDomains(ctx)
+	if err != nil {
+		return err
+	}
+	domainIDs := make(map[string]ulid.ULID, len(repoDomains))
+	for _, d := range repoDomains {
+		domainIDs[d.Domain] = d.ID
+	}
+	for domain, d := range c.Domains {
+		users := make([]ulid.ULID, 0, len(d.Users))
+		for _, name := range d.Users {
+			if id, ok := userIDs[name]; ok {
+				users = append(users, id)
+			}
+		}
+		groups := make([]ulid.ULID, len(d.Groups))
+		for i, name := range d.Groups {
+			groups[i] = groupIDs[name]
+		}
+		rules := make([]repos.GatewayRuleModel, len(d.Rules))
+		for i, rule := range d.Rules {
+			// users that do not exist are kept, so that the rule does not apply to everyone instead
+			rules[i] = rule.model(c.userIDs, groupIDs)
+		}
+		maxAuthAge := time.Duration(d.MaxAuthAge) * time.Minute
+		headers := repos.DefaultGatewayHeaders
+		if d.Headers != nil {
+			headers = *d.Headers
+		}
+		if id, ok := domainIDs[domain]; ok {
+			_, err = repo.UpdateDomain(ctx, id, domain, groups, users, rules, maxAuthAge, d.Require2FA, headers, d.BearerScope)
+		} else {
+			_, err = repo.CreateDomain(ctx, domain, groups, users, rules, maxAuthAge, d.Require2FA, headers, d.BearerScope)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if previous == nil {
+		return nil
+	}
+	for domain := range previous.Domains {
+		if _, ok := c.Domains[domain]; ok {
+			continue
+		}
+		id, ok := domainIDs[domain]
+		if !ok {
+			continue
+		}
+		err = repo.DeleteDomain(ctx, id)
+		if err != nil && !errors.Is(err, repos.ErrNoRecord) {
+			return err
+		}
+	}
+	listed := make(map[ulid.ULID]struct{}, len(c.userIDs))
+	for _, id := range c.userIDs {
+		listed[id] = struct{}{}
+	}
+	for name, u := range previous.Users {
+		id := previous.userIDs[name]
+		if _, ok := listed[id]; ok {
+			continue
+		}
+		for _, group := range u.Groups {
+			groupID, ok := groupIDs[group]
+			if !ok || !slices.Contains(memberships[id], groupID) {
+				continue
+			}
+			err = repo.RemoveGroupMember(ctx, groupID, id)
+			if err != nil && !errors.Is(err, repos.ErrNoRecord) {
+				return err
+			}
 		}
-		a.domains[name] = domainConf
 	}
 	return nil
 }
+
+// checkConfigFile applies the file at AUTH_GATEWAY_CONFIG if its contents changed since it was last applied.
+// If the file is invalid, the current access rules are kept.
+func (a *authGatewayService) checkConfigFile(ctx context.Context) {
+	if config.AuthGatewayConfig() == "" {
+		return
+	}
+	data, err := os.ReadFile(config.AuthGatewayConfig())
+	if err != nil {
+		// Only log the first failure until the file can be read again.
+		if !a.configReadFailed {
+			a.configReadFailed = true
+			log.Errorf("Failed to read auth gateway config %s, keeping the current config: %s", config.AuthGatewayConfig(), err)
+		}
+		return
+	}
+	if a.configReadFailed {
+		a.configReadFailed = false
+		log.Infof("Auth gateway config %s is readable again", config.AuthGatewayConfig())
+	}
+	hash := sha256.Sum256(data)
+	if hash == a.configHash || hash == a.invalidConfigHash {
+		return
+	}
+	c, err := parseConfigFile(data)
+	if err != nil {
+		// Only log invalid files once.
+		a.invalidConfigHash = hash
+		log.Errorf("Failed to load auth gateway config %s, keeping the current config: %s", config.AuthGatewayConfig(), err)
+		return
+	}
+	_, err = a.applyConfigFile(ctx, c, data, hash)
+	if err != nil {
+		// The hash is not updated, so applying the file is retried on the next check.
+		log.Errorf("Failed to apply auth gateway config %s: %s", config.AuthGatewayConfig(), err)
+		return
+	}
+	a.configHash = hash
+	err = a.Reload(ctx)
+	if err != nil {
+		log.Errorf("Failed to reload auth gateway config: %s", err)
+		return
+	}
+	log.Infof("Applied changes of auth gateway config %s", config.AuthGatewayConfig())
+}
+
+// RunConfigWatcher applies changes to the file at AUTH_GATEWAY_CONFIG until ctx is canceled.
+// Every value received from reload (e.g. SIGHUP) additionally reloads the access rules from the database.
+func (a *authGatewayService) RunConfigWatcher(ctx context.Context, reload <-chan os.Signal) {
+	ticker := time.NewTicker(gatewayConfigPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-reload:
+			log.Info("Reloading auth gateway config...")
+			err := a.Reload(ctx)
+			if err != nil {
+				log.Errorf("Failed to reload auth gateway config: %s", err)
+			}
+		}
+		a.checkConfigFile(ctx)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/config"
+)
+
+// gatewayAssertionLifetime is short, because a new assertion is issued for every request checked by the gateway.
+const gatewayAssertionLifetime = 1 * time.Minute
+
+// GatewayAuthSource is the credential that identified the user of a request checked by the gateway.
+type GatewayAuthSource string
+
+const (
+	GatewayAuthSession     GatewayAuthSource = "session"
+	GatewayAuthBearer      GatewayAuthSource = "bearer"
+	GatewayAuthAppPassword GatewayAuthSource = "app_password"
+)
+
+// CreateGatewayAssertion creates a JWT that proves the identity of the user to the service at audience
+// (the origin of the request checked by the gateway). It is signed with RS256 and can be verified with the keys at /oauth/certs.
+// auth_time, amr and acr describe the login of the session, so they are only included if source is GatewayAuthSession.
+func (a *authService) CreateGatewayAssertion(ctx context.Context, userID ulid.ULID, audience string, source GatewayAuthSource) (string, error) {
+	type claims struct {
+		jwt.RegisteredClaims
+		AuthTime      *jwt.NumericDate `json:"auth_time,omitempty"`
+		AMR           []string         `json:"amr,omitempty"`
+		ACR           string           `json:"acr,omitempty"`
+		Name          string           `json:"name"`
+		Email         string           `json:"email"`
+		EmailVerified bool             `json:"email_verified"`
+		Groups        []string         `json:"groups"`
+	}
+	user, err := a.userRepo.Find(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("create gateway assertion: %w", err)
+	}
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.BaseURL(),
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(gatewayAssertionLifetime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        ulid.Make().String(),
+		},
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailConfirmed,
+		Groups:        a.gatewayService.Groups(userID),
+	}
+	if source == GatewayAuthSession {
+		c.AMR = a.AMR(ctx)
+		c.ACR = acrFromAMR(c.AMR)
+		if authTime := a.AuthTime(ctx); !authTime.IsZero() {
+			c.AuthTime = jwt.NewNumericDate(authTime)
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	token.Header["typ"] = "hid-assertion+jwt"
+	assertion, err := a.signJWT(token)
+	if err != nil {
+		return "", fmt.Errorf("create gateway assertion: %w", err)
+	}
+	return assertion, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+// GatewayDecision is the result of evaluating the access rules of a domain for a request.
+type GatewayDecision int
+
+const (
+	GatewayDeny GatewayDecision = iota
+	GatewayAllow
+	// GatewayBypass allows the request without authentication.
+	GatewayBypass
+	// GatewayLoginRequired is returned for unauthenticated requests that can only be decided for a user.
+	GatewayLoginRequired
+)
+
+// gatewayRegexPrefix marks rule paths that are regular expressions instead of glob patterns.
+const gatewayRegexPrefix = "re:"
+
+var gatewayMethodRegex = regexp.MustCompile(`^[A-Z]+$`)
+
+type gatewayRule struct {
+	policy  repos.GatewayPolicy
+	methods []string
+	path    *regexp.Regexp
+	users   map[ulid.ULID]struct{}
+	groups  map[ulid.ULID]struct{}
+}
+
+// compileGatewayRule validates rule and compiles its path pattern.
+func compileGatewayRule(rule repos.GatewayRuleModel) (gatewayRule, error) {
+	if rule.Policy != repos.GatewayPolicyAllow && rule.Policy != repos.GatewayPolicyDeny && rule.Policy != repos.GatewayPolicyBypass {
+		return gatewayRule{}, fmt.Errorf("%w: unknown policy %q", ErrInvalidGatewayRule, rule.Policy)
+	}
+	if rule.Policy == repos.GatewayPolicyBypass && (len(rule.Users) > 0 || len(rule.Groups) > 0) {
+		return gatewayRule{}, fmt.Errorf("%w: bypass rules cannot have users or groups", ErrInvalidGatewayRule)
+	}
+	for _, m := range rule.Methods {
+		if !gatewayMethodRegex.MatchString(m) {
+			return gatewayRule{}, fmt.Errorf("%w: invalid method %q", ErrInvalidGatewayRule, m)
+		}
+	}
+
+	var pattern string
+	if expr, ok := strings.CutPrefix(rule.Path, gatewayRegexPrefix); ok {
+		pattern = expr
+	} else if rule.Path == "*" || strings.HasPrefix(rule.Path, "/") {
+		pattern = globToRegex(rule.Path)
+	} else {
+		return gatewayRule{}, fmt.Errorf("%w: path %q must start with / or %s", ErrInvalidGatewayRule, rule.Path, gatewayRegexPrefix)
+	}
+	path, err := regexp.Compile(pattern)
+	if err != nil {
+		return gatewayRule{}, fmt.Errorf("%w: %w", ErrInvalidGatewayRule, err)
+	}
+
+	r := gatewayRule{
+		policy:  rule.Policy,
+		methods: rule.Methods,
+		path:    path,
+		users:   make(map[ulid.ULID]struct{}, len(rule.Users)),
+		groups:  make(map[ulid.ULID]struct{}, len(rule.Groups)),
+	}
+	for _, u := range rule.Users {
+		r.users[u] = struct{}{}
+	}
+	for _, g := range rule.Groups {
+		r.groups[g] = struct{}{}
+	}
+	return r, nil
+}
+
+// ValidateGatewayRule reports whether rule has a known policy, valid methods and a valid path pattern.
+func ValidateGatewayRule(rule repos.GatewayRuleModel) error {
+	_, err := compileGatewayRule(rule)
+	return err
+}
+
+// globToRegex converts a glob pattern, in which * matches any sequence of characters (including /)
+// and ? matches a single character, to an anchored regular expression.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func (r gatewayRule) matchesRequest(method, path string) bool {
+	if len(r.methods) > 0 && !slices.Contains(r.methods, method) {
+		return false
+	}
+	return r.path.MatchString(path)
+}
+
+func (r gatewayRule) hasSubjects() bool {
+	return len(r.users) > 0 || len(r.groups) > 0
+}
+
+func (r gatewayRule) matchesUser(userID ulid.ULID, groups []ulid.ULID) bool {
+	if _, ok := r.users[userID]; ok {
+		return true
+	}
+	for _, g := range groups {
+		if _, ok := r.groups[g]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize evaluates the rules of the domain in order and falls back to the users and groups of the domain
+// if no rule matches. userID is the zero ID for unauthenticated requests.
+func (d domainConfig) authorize(userID ulid.ULID, groups []ulid.ULID, method, path string) GatewayDecision {
+	authenticated := userID != (ulid.ULID{})
+	for _, rule := range d.rules {
+		if !rule.matchesRequest(method, path) {
+			continue
+		}
+		switch rule.policy {
+		case repos.GatewayPolicyBypass:
+			return GatewayBypass
+		case repos.GatewayPolicyDeny:
+			if !rule.hasSubjects() {
+				return GatewayDeny
+			}
+			if !authenticated {
+				return GatewayLoginRequired
+			}
+			if rule.matchesUser(userID, groups) {
+				return GatewayDeny
+			}
+		case repos.GatewayPolicyAllow:
+			if !authenticated {
+				return GatewayLoginRequired
+			}
+			if !rule.hasSubjects() || rule.matchesUser(userID, groups) {
+				return GatewayAllow
+			}
+		}
+	}
+
+	if !authenticated {
+		return GatewayLoginRequired
+	}
+	if _, ok := d.users[userID]; ok {
+		return GatewayAllow
+	}
+	for _, group := range groups {
+		if _, ok := d.groups[group]; ok {
+			return GatewayAllow
+		}
+	}
+	return GatewayDeny
+}
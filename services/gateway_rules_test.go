@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestCompileGatewayRule(t *testing.T) {
+	user := ulid.Make()
+	tests := []struct {
+		name  string
+		rule  repos.GatewayRuleModel
+		valid bool
+	}{
+		{"allow glob", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "/api/*"}, true},
+		{"any path", repos.GatewayRuleModel{Policy: repos.GatewayPolicyDeny, Path: "*"}, true},
+		{"regex", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "re:^/v[0-9]+/"}, true},
+		{"methods", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "/", Methods: []string{"GET", "PROPFIND"}}, true},
+		{"bypass", repos.GatewayRuleModel{Policy: repos.GatewayPolicyBypass, Path: "/public/*"}, true},
+		{"unknown policy", repos.GatewayRuleModel{Policy: "maybe", Path: "/"}, false},
+		{"bypass with users", repos.GatewayRuleModel{Policy: repos.GatewayPolicyBypass, Path: "/", Users: []ulid.ULID{user}}, false},
+		{"lowercase method", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "/", Methods: []string{"get"}}, false},
+		{"relative path", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "api/*"}, false},
+		{"invalid regex", repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: "re:("}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGatewayRule(tt.rule)
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid rule, got %s", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidGatewayRule) {
+				t.Fatalf("expected ErrInvalidGatewayRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGatewayRuleMatchesRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		methods []string
+		method  string
+		request string
+		want    bool
+	}{
+		{"glob prefix", "/api/*", nil, "GET", "/api/users/1", true},
+		{"glob needs separator", "/api/*", nil, "GET", "/apix", false},
+		{"glob is anchored", "/api/*", nil, "GET", "/v1/api/x", false},
+		{"question mark", "/file?.txt", nil, "GET", "/file1.txt", true},
+		{"question mark is one character", "/file?.txt", nil, "GET", "/file12.txt", false},
+		{"dot is literal", "/a.b", nil, "GET", "/axb", false},
+		{"any path", "*", nil, "DELETE", "/anything", true},
+		{"exact path", "/admin", nil, "GET", "/admin/", false},
+		{"regex", "re:^/v[0-9]+/", nil, "GET", "/v2/items", true},
+		{"regex is not anchored at the end", "re:^/v[0-9]+/", nil, "GET", "/v10/items/x", true},
+		{"regex mismatch", "re:^/v[0-9]+/", nil, "GET", "/vx/items", false},
+		{"method allowed", "/dav/*", []string{"GET", "PROPFIND"}, "PROPFIND", "/dav/x", true},
+		{"method not allowed", "/dav/*", []string{"GET", "PROPFIND"}, "PUT", "/dav/x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileGatewayRule(repos.GatewayRuleModel{Policy: repos.GatewayPolicyAllow, Path: tt.path, Methods: tt.methods})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.matchesRequest(tt.method, tt.request); got != tt.want {
+				t.Errorf("matchesRequest(%q, %q) = %t, want %t", tt.method, tt.request, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainConfigAuthorize(t *testing.T) {
+	alice, bob, carol := ulid.Make(), ulid.Make(), ulid.Make()
+	admins, devs := ulid.Make(), ulid.Make()
+	rule := func(policy repos.GatewayPolicy, path string, users, groups []ulid.ULID, methods ...string) gatewayRule {
+		r, err := compileGatewayRule(repos.GatewayRuleModel{Policy: policy, Path: path, Methods: methods, Users: users, Groups: groups})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	d := domainConfig{
+		users:  map[ulid.ULID]struct{}{carol: {}},
+		groups: map[ulid.ULID]struct{}{devs: {}},
+		rules: []gatewayRule{
+			rule(repos.GatewayPolicyBypass, "/public/*", nil, nil),
+			rule(repos.GatewayPolicyDeny, "/public/secret", nil, nil),
+			rule(repos.GatewayPolicyAllow, "/admin/*", nil, []ulid.ULID{admins}),
+			rule(repos.GatewayPolicyDeny, "/admin/*", nil, nil),
+			rule(repos.GatewayPolicyDeny, "*", []ulid.ULID{bob}, nil, "DELETE"),
+			rule(repos.GatewayPolicyAllow, "/shared/*", nil, nil),
+		},
+	}
+	groups := map[ulid.ULID][]ulid.ULID{
+		alice: {admins},
+		bob:   {devs},
+	}
+	tests := []struct {
+		name   string
+		user   ulid.ULID
+		method string
+		path   string
+		want   GatewayDecision
+	}{
+		{"bypass without login", ulid.ULID{}, "GET", "/public/index.html", GatewayBypass},
+		{"first match wins", ulid.ULID{}, "GET", "/public/secret", GatewayBypass},
+		{"group rule", alice, "GET", "/admin/users", GatewayAllow},
+		{"group rule does not match", bob, "GET", "/admin/users", GatewayDeny},
+		{"allow rule without login", ulid.ULID{}, "GET", "/admin/users", GatewayLoginRequired},
+		{"deny rule for user", bob, "DELETE", "/files/1", GatewayDeny},
+		{"deny rule for other users", carol, "DELETE", "/files/1", GatewayAllow},
+		{"deny rule with subjects without login", ulid.ULID{}, "DELETE", "/files/1", GatewayLoginRequired},
+		{"deny rule for other methods", bob, "GET", "/files/1", GatewayAllow},
+		{"allow rule without subjects", alice, "GET", "/shared/x", GatewayAllow},
+		{"fallback to domain user", carol, "GET", "/files/1", GatewayAllow},
+		{"fallback to domain group", bob, "GET", "/files/1", GatewayAllow},
+		{"fallback denies others", alice, "GET", "/files/1", GatewayDeny},
+		{"fallback without login", ulid.ULID{}, "GET", "/files/1", GatewayLoginRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.authorize(tt.user, groups[tt.user], tt.method, tt.path); got != tt.want {
+				t.Errorf("authorize(%s %s) = %d, want %d", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
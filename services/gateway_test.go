@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	const user = `"alice": {"id": "01HGW2N7EHJZ4C9C1ZQ1G6Y7KD", "groups": ["dev", "ops"]}`
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+		groups  []string
+	}{
+		{
+			name:   "valid",
+			data:   `{"users": {` + user + `}, "domains": {"app.example.com": {"users": ["alice"], "groups": ["dev"], "rules": [{"policy": "allow", "path": "/admin/*", "groups": ["admins"]}]}}}`,
+			groups: []string{"admins", "dev", "ops"},
+		},
+		{
+			name:   "wildcard domain",
+			data:   `{"domains": {"*.example.com": {"groups": ["dev"]}}}`,
+			groups: []string{"dev"},
+		},
+		{
+			name: "invalid json",
+			data: `{"users": [}`,
+		},
+		{
+			name: "invalid user id",
+			data: `{"users": {"alice": {"id": "alice"}}}`,
+		},
+		{
+			name:    "invalid domain",
+			data:    `{"domains": {"https://app.example.com": {}}}`,
+			wantErr: ErrInvalidDomain,
+		},
+		{
+			name:    "negative max auth age",
+			data:    `{"domains": {"app.example.com": {"maxAuthAge": -1}}}`,
+			wantErr: ErrInvalidMaxAuthAge,
+		},
+		{
+			name:    "unknown header",
+			data:    `{"domains": {"app.example.com": {"headers": ["X-Secret"]}}}`,
+			wantErr: ErrInvalidGatewayHeader,
+		},
+		{
+			name:    "user scope as bearer scope",
+			data:    `{"domains": {"app.example.com": {"bearerScope": "openid"}}}`,
+			wantErr: ErrInvalidScope,
+		},
+		{
+			name: "unknown domain user",
+			data: `{"domains": {"app.example.com": {"users": ["bob"]}}}`,
+		},
+		{
+			name: "unknown rule user",
+			data: `{"users": {` + user + `}, "domains": {"app.example.com": {"rules": [{"policy": "deny", "path": "*", "users": ["bob"]}]}}}`,
+		},
+		{
+			name:    "invalid rule",
+			data:    `{"domains": {"app.example.com": {"rules": [{"policy": "bypass", "path": "/", "groups": ["dev"]}]}}}`,
+			wantErr: ErrInvalidGatewayRule,
+		},
+		{
+			name:    "invalid group name",
+			data:    `{"domains": {"app.example.com": {"groups": ["bad group"]}}}`,
+			wantErr: ErrInvalidGroupName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseConfigFile([]byte(tt.data))
+			if tt.groups != nil {
+				if err != nil {
+					t.Fatalf("expected valid config, got %s", err)
+				}
+				if !slices.Equal(c.groups, tt.groups) {
+					t.Errorf("groups = %v, want %v", c.groups, tt.groups)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %s, got %s", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRequiresStepUp(t *testing.T) {
+	a := &authGatewayService{}
+	a.config.Store(&gatewayConfig{
+		domains: map[string]domainConfig{
+			"open.example.com":   {},
+			"recent.example.com": {maxAuthAge: 10 * time.Minute},
+			"*.2fa.example.com":  {require2FA: true},
+			"both.example.com":   {maxAuthAge: 10 * time.Minute, require2FA: true},
+		},
+	})
+	now := time.Now()
+	tests := []struct {
+		name     string
+		domain   string
+		authTime time.Time
+		amr      []string
+		want     bool
+	}{
+		{"no policy", "open.example.com", now.Add(-24 * time.Hour), []string{AMRPassword}, false},
+		{"unknown domain", "other.example.com", time.Time{}, nil, false},
+		{"recent login", "recent.example.com", now.Add(-5 * time.Minute), []string{AMRPassword}, false},
+		{"old login", "recent.example.com", now.Add(-15 * time.Minute), []string{AMRPassword, AMROTP}, true},
+		{"password only", "app.2fa.example.com", now, []string{AMRPassword}, true},
+		{"otp", "app.2fa.example.com", now, []string{AMRPassword, AMROTP}, false},
+		{"passkey", "app.2fa.example.com", now, []string{AMRPasskey}, false},
+		{"recovery code", "app.2fa.example.com", now, []string{AMRPassword, AMRRecoveryCode}, true},
+		{"recent login without 2fa", "both.example.com", now, []string{AMRPassword}, true},
+		{"old login with 2fa", "both.example.com", now.Add(-time.Hour), []string{AMRPassword, AMROTP}, true},
+		{"recent login with 2fa", "both.example.com", now, []string{AMRPassword, AMROTP}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.RequiresStepUp(tt.domain, tt.authTime, tt.amr); got != tt.want {
+				t.Errorf("RequiresStepUp(%s) = %t, want %t", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ var translations = map[string]map[string]string{
 		"login":                           "Login",
 		"signup":                          "Sign up",
 		"logout":                          "Logout",
+		"logoutConfirm":                   "Do you want to sign out of H-ID?",
 		"confirmEmail":                    "Confirm Email",
 		"changeEmail":                     "Change Email",
 		"confirm":                         "Confirm",
@@ -21,6 +22,42 @@ var translations = map[string]map[string]string{
 		"description":                     "Description",
 		"website":                         "Website",
 		"redirectURI":                     "Redirect URI",
+		"postLogoutRedirectURI":           "Post logout redirect URI",
+		"postLogoutRedirectURIHint":       "Optional. The app may redirect here after signing the user out of H-ID.",
+		"backchannelLogoutURI":            "Back-channel logout URI",
+		"backchannelLogoutURIHint":        "Optional. H-ID sends a logout token to this URI when a user signs out.",
+		"clientCredentialsScopes":         "Client credentials scopes",
+		"device":                          "Connect device",
+		"userCode":                        "Code",
+		"userCodeHint":                    "Enter the code that is shown on your device.",
+		"invalidUserCode":                 "Invalid or expired code.",
+		"deviceApproved":                  "Your device is now connected. You can return to your device.",
+		"deviceDenied":                    "The device was denied access.",
+		"clientCredentialsScopesHint":     "Optional, space separated. Scopes the app may request for itself with the client credentials grant (confidential apps only).",
+		"invalidClientCredentialsScopes":  "Invalid scopes. Only confidential apps may use the client credentials grant and only custom scopes of your own apps are allowed.",
+		"tokenExchange":                   "Token exchange",
+		"tokenExchangeDisabled":           "Disabled",
+		"tokenExchangeEnabled":            "Enabled",
+		"tokenExchangeHint":               "Allows the app to exchange access tokens of users that were issued to it or are intended for its scopes for down-scoped tokens (confidential apps only).",
+		"tokenExchangePublicClient":       "Only confidential apps may use token exchange.",
+		"passwordGrant":                   "Password grant",
+		"passwordGrantDisabled":           "Disabled",
+		"passwordGrantEnabled":            "Enabled",
+		"passwordGrantHint":               "Allows the app to exchange app passwords of users for access tokens (confidential apps only, can only be enabled by admins).",
+		"passwordGrantNotAllowed":         "Only admins can enable the password grant and only for confidential apps.",
+		"manageScopes":                    "manage scopes",
+		"scopes":                          "Scopes",
+		"scope":                           "Scope",
+		"createScope":                     "Create Scope",
+		"scopeNameHint":                   "Unique name of the scope, e.g. invoices:read. It cannot be changed later.",
+		"audience":                        "Audience",
+		"audienceHint":                    "Optional. Added to the aud claim of access tokens with this scope, e.g. the URL of your API. Only admins can assign audiences other than the ID of this app, which is the default.",
+		"scopeDescriptionEN":              "Description (English)",
+		"scopeDescriptionDE":              "Description (German)",
+		"scopeDescriptionHint":            "Shown to users on the consent page, e.g. \"View your invoices\".",
+		"invalidScopeName":                "Invalid scope name. Scope names must not contain spaces, quotes or backslashes and openid, profile, email and groups are reserved.",
+		"scopeExists":                     "A scope with this name already exists.",
+		"invalidAudience":                 "Invalid audience. The audience must not contain spaces, must not be used by another app and can only be assigned by admins.",
 		"create":                          "Create",
 		"email":                           "Email",
 		"password":                        "Password",
@@ -46,6 +83,7 @@ var translations = map[string]map[string]string{
 		"invalidCredentials":              "Invalid credentials",
 		"scopesProfile":                   "View user and account information",
 		"scopesEmail":                     "View your email address",
+		"scopesGroups":                    "View your groups",
 		"pressUpdateToUpload":             "Press 'Update' to upload your new profile picture.",
 		"activate2FA":                     "Activate 2FA",
 		"secretKey":                       "Secret key",
@@ -91,6 +129,14 @@ var translations = map[string]map[string]string{
 		"id":                              "ID",
 		"created":                         "Created",
 		"clientSecretWarning":             "This is the only time you will be shown the client secret. Copy it now and store it somewhere safe.",
+		"clientType":                      "Client type",
+		"clientTypeConfidential":          "Confidential (server-side app with client secret)",
+		"clientTypePublic":                "Public (SPA or native app, PKCE)",
+		"clientTypeHint":                  "Public clients don't get a client secret and must use PKCE.",
+		"accessTokenFormat":               "Access token format",
+		"accessTokenFormatOpaque":         "Opaque",
+		"accessTokenFormatJWT":            "JWT (RFC 9068)",
+		"idTokenSignedResponseAlg":        "ID token signing algorithm",
 		"done":                            "Done",
 		"secret":                          "Secret",
 		"managePasskeys":                  "manage passkeys",
@@ -98,6 +144,25 @@ var translations = map[string]map[string]string{
 		"passkey":                         "Passkey",
 		"createPasskey":                   "Create Passkey",
 		"createdAt":                       "Created at",
+		"manageAppPasswords":              "manage app passwords",
+		"appPasswords":                    "App Passwords",
+		"appPassword":                     "App password",
+		"createAppPassword":               "Create App Password",
+		"appPasswordScopesHint":           "Space separated, e.g. openid profile. The app password can only be used for these scopes.",
+		"invalidAppPasswordScopes":        "Invalid scopes. Only openid, profile, email, groups and scopes registered by apps are allowed.",
+		"appPasswordWarning":              "This is the only time you will be shown the app password. Copy it now and store it somewhere safe.",
+		"username":                        "Username",
+		"lastUsed":                        "Last used",
+		"never":                           "Never",
+		"manageSessions":                  "manage sessions",
+		"sessions":                        "Sessions",
+		"currentSession":                  "this session",
+		"unknownDevice":                   "Unknown device",
+		"ipAddress":                       "IP address",
+		"loginTime":                       "Signed in at",
+		"lastActivity":                    "Last activity",
+		"logoutSession":                   "Sign out",
+		"logoutOtherSessions":             "Sign out everywhere else",
 		"usePasskey":                      "Use passkey",
 		"or":                              "or",
 		"invite":                          "Invite",
@@ -109,6 +174,43 @@ var translations = map[string]map[string]string{
 		"invitationSuccess":               "Invitation sent.",
 		"listUsers":                       "Users",
 		"isAdmin":                         "Admin",
+		"users":                           "Users",
+		"groups":                          "Groups",
+		"group":                           "Group",
+		"createGroup":                     "Create Group",
+		"groupNameHint":                   "Letters, digits, dots, dashes and underscores. Included in the groups claim of ID tokens.",
+		"invalidGroupName":                "Invalid group name.",
+		"groupExists":                     "A group with this name already exists.",
+		"members":                         "Members",
+		"addMember":                       "Add member",
+		"remove":                          "Remove",
+		"unknownUser":                     "There is no user with this email address.",
+		"unknownUsers":                    "One or more email addresses do not belong to a user.",
+		"unknownGroups":                   "One or more groups do not exist.",
+		"domains":                         "Domains",
+		"domain":                          "Domain",
+		"createDomain":                    "Create Domain",
+		"domainHint":                      "For example app.example.com, or *.example.com for all subdomains.",
+		"invalidDomain":                   "Invalid domain.",
+		"domainExists":                    "This domain already exists.",
+		"domainGroupsHint":                "Optional, space separated. Members of these groups may access the domain.",
+		"domainUsersHint":                 "Optional, space separated email addresses of users who may access the domain.",
+		"rules":                           "Rules",
+		"gatewayRulesHint":                "Optional, one rule per line: policy methods path [group:name]... [user:email]..., e.g. bypass GET /public/* or allow * /admin/* group:admins. Policies: allow, deny, bypass (no login). Methods: * or GET,POST,… Paths: glob (* matches anything) or regular expression prefixed with re:. The first matching rule decides, otherwise the groups and users above.",
+		"invalidGatewayRule":              "Invalid rule:",
+		"stepUp":                          "Verify Your Identity",
+		"stepUpHint":                      "This page requires a recent verification with your passkey or authenticator app.",
+		"maxAuthAge":                      "Maximum login age",
+		"maxAuthAgeHint":                  "Optional, in minutes. Users who logged in longer ago have to verify their second factor again. 0 disables the check.",
+		"invalidMaxAuthAge":               "Invalid maximum login age.",
+		"require2FA":                      "Require 2FA",
+		"require2FAHint":                  "Users have to verify their passkey or authenticator app in the current session, even if they skipped 2FA on a remembered device.",
+		"gatewayHeaders":                  "Identity headers",
+		"gatewayHeadersHint":              "Optional, space separated headers that are returned to the reverse proxy for allowed requests: Remote-User (ID), Remote-Name, Remote-Email, Remote-Groups (comma separated) and X-HID-Assertion (signed JWT, verifiable with /oauth/certs).",
+		"invalidGatewayHeader":            "Unknown header.",
+		"bearerScope":                     "Access token scope",
+		"bearerScopeHint":                 "Optional. Scripts and API clients can access the domain with an H-ID access token or an app password with this scope in the Authorization header.",
+		"invalidBearerScope":              "The scope must be a custom scope of an app.",
 		"accountDeleted":                  "Account Deleted",
 		"accountWithEmailDeletedByAdmin1": "Your account with the email address",
 		"accountWithEmailDeletedByAdmin2": "has been deleted by an administrator",
@@ -122,6 +224,7 @@ var translations = map[string]map[string]string{
 		"login":                           "Anmelden",
 		"signup":                          "Registrieren",
 		"logout":                          "Abmelden",
+		"logoutConfirm":                   "Möchtest du dich von H-ID abmelden?",
 		"confirmEmail":                    "Email Bestätigen",
 		"changeEmail":                     "Email Ändern",
 		"confirm":                         "Bestätigen",
@@ -131,6 +234,42 @@ var translations = map[string]map[string]string{
 		"description":                     "Beschreibung",
 		"website":                         "Webseite",
 		"redirectURI":                     "Umleitungs-URI",
+		"postLogoutRedirectURI":           "Umleitungs-URI nach Abmeldung",
+		"postLogoutRedirectURIHint":       "Optional. Die App kann nach dem Abmelden von H-ID hierhin weiterleiten.",
+		"backchannelLogoutURI":            "Back-Channel-Logout-URI",
+		"backchannelLogoutURIHint":        "Optional. H-ID sendet ein Logout-Token an diese URI, wenn sich ein Benutzer abmeldet.",
+		"clientCredentialsScopes":         "Client-Credentials-Scopes",
+		"device":                          "Gerät verbinden",
+		"userCode":                        "Code",
+		"userCodeHint":                    "Gib den Code ein, der auf deinem Gerät angezeigt wird.",
+		"invalidUserCode":                 "Ungültiger oder abgelaufener Code.",
+		"deviceApproved":                  "Dein Gerät ist jetzt verbunden. Du kannst zu deinem Gerät zurückkehren.",
+		"deviceDenied":                    "Dem Gerät wurde der Zugriff verweigert.",
+		"clientCredentialsScopesHint":     "Optional, durch Leerzeichen getrennt. Scopes, die die App mit dem Client-Credentials-Grant für sich selbst anfordern darf (nur vertrauliche Apps).",
+		"invalidClientCredentialsScopes":  "Ungültige Scopes. Nur vertrauliche Apps dürfen den Client-Credentials-Grant verwenden und nur eigene Scopes deiner Apps sind erlaubt.",
+		"tokenExchange":                   "Token-Austausch",
+		"tokenExchangeDisabled":           "Deaktiviert",
+		"tokenExchangeEnabled":            "Aktiviert",
+		"tokenExchangeHint":               "Erlaubt der App, Access-Tokens von Benutzern, die für sie ausgestellt wurden oder für ihre Scopes bestimmt sind, gegen eingeschränkte Tokens auszutauschen (nur vertrauliche Apps).",
+		"tokenExchangePublicClient":       "Nur vertrauliche Apps dürfen den Token-Austausch verwenden.",
+		"passwordGrant":                   "Password Grant",
+		"passwordGrantDisabled":           "Deaktiviert",
+		"passwordGrantEnabled":            "Aktiviert",
+		"passwordGrantHint":               "Erlaubt der App, App-Passwörter von Benutzern gegen Access-Tokens einzutauschen (nur vertrauliche Apps, kann nur von Admins aktiviert werden).",
+		"passwordGrantNotAllowed":         "Nur Admins können den Password Grant aktivieren und nur für vertrauliche Apps.",
+		"manageScopes":                    "Scopes verwalten",
+		"scopes":                          "Scopes",
+		"scope":                           "Scope",
+		"createScope":                     "Scope erstellen",
+		"scopeNameHint":                   "Eindeutiger Name des Scopes, z.B. invoices:read. Er kann später nicht geändert werden.",
+		"audience":                        "Zielgruppe (Audience)",
+		"audienceHint":                    "Optional. Wird zum aud-Claim von Access Tokens mit diesem Scope hinzugefügt, z.B. die URL deiner API. Nur Admins können eine andere Zielgruppe als die ID dieser App festlegen, die standardmäßig verwendet wird.",
+		"scopeDescriptionEN":              "Beschreibung (Englisch)",
+		"scopeDescriptionDE":              "Beschreibung (Deutsch)",
+		"scopeDescriptionHint":            "Wird Nutzern auf der Zustimmungsseite angezeigt, z.B. \"Deine Rechnungen ansehen\".",
+		"invalidScopeName":                "Ungültiger Scope-Name. Scope-Namen dürfen keine Leerzeichen, Anführungszeichen oder Backslashes enthalten und openid, profile, email und groups sind reserviert.",
+		"scopeExists":                     "Ein Scope mit diesem Namen existiert bereits.",
+		"invalidAudience":                 "Ungültige Zielgruppe. Die Zielgruppe darf keine Leerzeichen enthalten, nicht von einer anderen App verwendet werden und kann nur von Admins festgelegt werden.",
 		"create":                          "Erstellen",
 		"email":                           "Email",
 		"password":                        "Passwort",
@@ -156,6 +295,7 @@ var translations = map[string]map[string]string{
 		"invalidCredentials":              "Ungültige Zugangsdaten",
 		"scopesProfile":                   "Profil und Account Informationen ansehen",
 		"scopesEmail":                     "Email Adresse ansehen",
+		"scopesGroups":                    "Deine Gruppen ansehen",
 		"pressUpdateToUpload":             "Drücke 'Aktualisieren', um dein neues Profilbild hochzuladen.",
 		"activate2FA":                     "2FA Aktivieren",
 		"secretKey":                       "Geheimschlüssel",
@@ -201,6 +341,14 @@ var translations = map[string]map[string]string{
 		"id":                              "ID",
 		"created":                         "Erstellt",
 		"clientSecretWarning":             "Dies ist das einzige Mal, dass dir das Client Secret gezeigt wird. Kopiere es jetzt und verwahre es sicher.",
+		"clientType":                      "Client-Typ",
+		"clientTypeConfidential":          "Vertraulich (serverseitige App mit Client Secret)",
+		"clientTypePublic":                "Öffentlich (SPA oder native App, PKCE)",
+		"clientTypeHint":                  "Öffentliche Clients erhalten kein Client Secret und müssen PKCE verwenden.",
+		"accessTokenFormat":               "Access-Token-Format",
+		"accessTokenFormatOpaque":         "Opak",
+		"accessTokenFormatJWT":            "JWT (RFC 9068)",
+		"idTokenSignedResponseAlg":        "ID-Token-Signaturalgorithmus",
 		"done":                            "Fertig",
 		"secret":                          "Secret",
 		"managePasskeys":                  "Passkeys verwalten",
@@ -208,6 +356,25 @@ var translations = map[string]map[string]string{
 		"passkey":                         "Passkey",
 		"createPasskey":                   "Passkey Erstellen",
 		"createdAt":                       "Erstellt am",
+		"manageAppPasswords":              "App-Passwörter verwalten",
+		"appPasswords":                    "App-Passwörter",
+		"appPassword":                     "App-Passwort",
+		"createAppPassword":               "App-Passwort Erstellen",
+		"appPasswordScopesHint":           "Durch Leerzeichen getrennt, z.B. openid profile. Das App-Passwort kann nur für diese Scopes verwendet werden.",
+		"invalidAppPasswordScopes":        "Ungültige Scopes. Nur openid, profile, email, groups und von Apps registrierte Scopes sind erlaubt.",
+		"appPasswordWarning":              "Dies ist das einzige Mal, dass dir das App-Passwort gezeigt wird. Kopiere es jetzt und verwahre es sicher.",
+		"username":                        "Benutzername",
+		"lastUsed":                        "Zuletzt verwendet",
+		"never":                           "Nie",
+		"manageSessions":                  "Sitzungen verwalten",
+		"sessions":                        "Sitzungen",
+		"currentSession":                  "diese Sitzung",
+		"unknownDevice":                   "Unbekanntes Gerät",
+		"ipAddress":                       "IP-Adresse",
+		"loginTime":                       "Angemeldet am",
+		"lastActivity":                    "Letzte Aktivität",
+		"logoutSession":                   "Abmelden",
+		"logoutOtherSessions":             "Überall sonst abmelden",
 		"usePasskey":                      "Passkey verwenden",
 		"or":                              "oder",
 		"invite":                          "Einladen",
@@ -219,6 +386,43 @@ var translations = map[string]map[string]string{
 		"invitationSuccess":               "Einladung gesendet.",
 		"listUsers":                       "Nutzer",
 		"isAdmin":                         "Admin",
+		"users":                           "Nutzer",
+		"groups":                          "Gruppen",
+		"group":                           "Gruppe",
+		"createGroup":                     "Gruppe Erstellen",
+		"groupNameHint":                   "Buchstaben, Ziffern, Punkte, Binde- und Unterstriche. Wird im groups-Claim von ID-Tokens angegeben.",
+		"invalidGroupName":                "Ungültiger Gruppenname.",
+		"groupExists":                     "Es existiert bereits eine Gruppe mit diesem Namen.",
+		"members":                         "Mitglieder",
+		"addMember":                       "Mitglied hinzufügen",
+		"remove":                          "Entfernen",
+		"unknownUser":                     "Es gibt keinen Nutzer mit dieser E-Mail-Adresse.",
+		"unknownUsers":                    "Mindestens eine E-Mail-Adresse gehört zu keinem Nutzer.",
+		"unknownGroups":                   "Mindestens eine Gruppe existiert nicht.",
+		"domains":                         "Domains",
+		"domain":                          "Domain",
+		"createDomain":                    "Domain Erstellen",
+		"domainHint":                      "Zum Beispiel app.example.com oder *.example.com für alle Subdomains.",
+		"invalidDomain":                   "Ungültige Domain.",
+		"domainExists":                    "Diese Domain existiert bereits.",
+		"domainGroupsHint":                "Optional, durch Leerzeichen getrennt. Mitglieder dieser Gruppen dürfen auf die Domain zugreifen.",
+		"domainUsersHint":                 "Optional, durch Leerzeichen getrennte E-Mail-Adressen von Nutzern, die auf die Domain zugreifen dürfen.",
+		"rules":                           "Regeln",
+		"gatewayRulesHint":                "Optional, eine Regel pro Zeile: Policy Methoden Pfad [group:Name]... [user:E-Mail]..., z.B. bypass GET /public/* oder allow * /admin/* group:admins. Policies: allow, deny, bypass (ohne Anmeldung). Methoden: * oder GET,POST,… Pfade: Glob (* passt auf alles) oder regulärer Ausdruck mit dem Präfix re:. Die erste passende Regel entscheidet, sonst die Gruppen und Nutzer oben.",
+		"invalidGatewayRule":              "Ungültige Regel:",
+		"stepUp":                          "Identität Bestätigen",
+		"stepUpHint":                      "Diese Seite erfordert eine aktuelle Bestätigung mit deinem Passkey oder deiner Authentifizierungs-App.",
+		"maxAuthAge":                      "Maximales Login-Alter",
+		"maxAuthAgeHint":                  "Optional, in Minuten. Nutzer, deren Anmeldung länger zurückliegt, müssen ihren zweiten Faktor erneut bestätigen. 0 deaktiviert die Prüfung.",
+		"invalidMaxAuthAge":               "Ungültiges maximales Login-Alter.",
+		"require2FA":                      "2FA erforderlich",
+		"require2FAHint":                  "Nutzer müssen ihren Passkey oder ihre Authentifizierungs-App in der aktuellen Sitzung bestätigen, auch wenn sie 2FA auf einem gemerkten Gerät übersprungen haben.",
+		"gatewayHeaders":                  "Identitäts-Header",
+		"gatewayHeadersHint":              "Optional, durch Leerzeichen getrennte Header, die bei erlaubten Anfragen an den Reverse Proxy zurückgegeben werden: Remote-User (ID), Remote-Name, Remote-Email, Remote-Groups (durch Kommas getrennt) und X-HID-Assertion (signiertes JWT, überprüfbar mit /oauth/certs).",
+		"invalidGatewayHeader":            "Unbekannter Header.",
+		"bearerScope":                     "Access-Token-Scope",
+		"bearerScopeHint":                 "Optional. Skripte und API-Clients können mit einem H-ID Access Token oder einem App-Passwort mit diesem Scope im Authorization-Header auf die Domain zugreifen.",
+		"invalidBearerScope":              "Der Scope muss ein benutzerdefinierter Scope einer App sein.",
 		"accountDeleted":                  "Account Gelöscht",
 		"accountWithEmailDeletedByAdmin1": "Dein Account mit der Email-Adresse",
 		"accountWithEmailDeletedByAdmin2": "wurde von einem Administrator gelöscht",
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/juho05/log"
+
+	"github.com/juho05/h-id/repos"
+)
+
+const (
+	// signedTokenLifetime is the lifetime of all JWTs signed by H-ID.
+	signedTokenLifetime = 30 * time.Minute
+	// jwtKeyPrepublishPeriod is the time a new key is published in the JWKS before it is used for signing.
+	jwtKeyPrepublishPeriod = 1 * time.Hour
+	// jwtKeyReloadInterval is the interval in which the web server reloads the keys from the database.
+	jwtKeyReloadInterval = 1 * time.Minute
+)
+
+// IDTokenSigningAlgorithms contains all algorithms clients can choose from to sign their ID tokens.
+var IDTokenSigningAlgorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+type JWTPublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+type jwtKeySet struct {
+	// signing contains the current signing key per algorithm.
+	signing map[string]*repos.JWTKeyModel
+	// published contains all keys that are published in the JWKS by key ID.
+	published map[string]*repos.JWTKeyModel
+	// ordered contains all published keys ordered by activation time.
+	ordered []*repos.JWTKeyModel
+}
+
+func (a *authService) initKeys(ctx context.Context) error {
+	err := a.ReloadJWTKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("init keys: %w", err)
+	}
+
+	generated := false
+	for _, alg := range IDTokenSigningAlgorithms {
+		if _, ok := a.jwtKeys.Load().signing[alg]; ok {
+			continue
+		}
+		generated = true
+
+		if alg == jwt.SigningMethodRS256.Alg() {
+			if priv, _, err := a.systemRepo.GetJWTKeys(ctx); err == nil {
+				log.Info("Importing existing RS256 JWT key...")
+				err = a.createJWTKey(ctx, alg, priv, time.Now())
+				if err != nil {
+					return fmt.Errorf("init keys: %w", err)
+				}
+				continue
+			} else if !errors.Is(err, repos.ErrNoRecord) {
+				return fmt.Errorf("init keys: %w", err)
+			}
+		}
+
+		log.Infof("Generating new %s JWT key...", alg)
+		err = a.generateJWTKey(ctx, alg, time.Now())
+		if err != nil {
+			return fmt.Errorf("init keys: %w", err)
+		}
+	}
+	if !generated {
+		log.Info("Using existing JWT keys...")
+		return nil
+	}
+
+	err = a.ReloadJWTKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("init keys: %w", err)
+	}
+	return nil
+}
+
+// ReloadJWTKeys loads all published keys from the database and selects the current signing keys.
+func (a *authService) ReloadJWTKeys(ctx context.Context) error {
+	now := time.Now()
+	err := a.systemRepo.DeleteRetiredJWTKeys(ctx, now.Add(-signedTokenLifetime))
+	if err != nil {
+		return fmt.Errorf("reload JWT keys: %w", err)
+	}
+	keys, err := a.systemRepo.FindJWTKeys(ctx, now.Add(-signedTokenLifetime))
+	if err != nil {
+		return fmt.Errorf("reload JWT keys: %w", err)
+	}
+	set := &jwtKeySet{
+		signing:   make(map[string]*repos.JWTKeyModel),
+		published: make(map[string]*repos.JWTKeyModel, len(keys)),
+		ordered:   keys,
+	}
+	for _, k := range keys {
+		set.published[k.ID] = k
+		if k.ActiveAt.After(now) || (!k.RetiredAt.IsZero() && !k.RetiredAt.After(now)) {
+			continue
+		}
+		if s, ok := set.signing[k.Algorithm]; !ok || s.ActiveAt.Before(k.ActiveAt) {
+			set.signing[k.Algorithm] = k
+		}
+	}
+	a.jwtKeys.Store(set)
+	return nil
+}
+
+// RotateJWTKeys generates a new signing key for every algorithm.
+// Unless immediate is true, the new keys are published for jwtKeyPrepublishPeriod before they are used for signing,
+// so relying parties have time to refresh their cached JWKS.
+// The old keys stay published until all tokens signed by them have expired.
+func (a *authService) RotateJWTKeys(ctx context.Context, immediate bool) error {
+	activeAt := time.Now()
+	if !immediate {
+		activeAt = activeAt.Add(jwtKeyPrepublishPeriod)
+	}
+	for _, alg := range IDTokenSigningAlgorithms {
+		err := a.generateJWTKey(ctx, alg, activeAt)
+		if err != nil {
+			return fmt.Errorf("rotate JWT keys: %w", err)
+		}
+	}
+	err := a.ReloadJWTKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("rotate JWT keys: %w", err)
+	}
+	return nil
+}
+
+// RunJWTKeyRotation periodically reloads the JWT keys and rotates them every rotationInterval until ctx is canceled.
+// A rotationInterval of 0 disables automatic rotation.
+func (a *authService) RunJWTKeyRotation(ctx context.Context, rotationInterval time.Duration) {
+	ticker := time.NewTicker(jwtKeyReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := a.ReloadJWTKeys(ctx)
+		if err != nil {
+			log.Errorf("Failed to reload JWT keys: %s", err)
+			continue
+		}
+		if rotationInterval <= 0 {
+			continue
+		}
+		var newest time.Time
+		for _, k := range a.jwtKeys.Load().ordered {
+			if k.CreatedAt.After(newest) {
+				newest = k.CreatedAt
+			}
+		}
+		if time.Since(newest) < rotationInterval {
+			continue
+		}
+		log.Info("Rotating JWT keys...")
+		err = a.RotateJWTKeys(ctx, false)
+		if err != nil {
+			log.Errorf("Failed to rotate JWT keys: %s", err)
+		}
+	}
+}
+
+func (a *authService) PublicJWTKeys() []JWTPublicKey {
+	keys := a.jwtKeys.Load().ordered
+	pubKeys := make([]JWTPublicKey, len(keys))
+	for i, k := range keys {
+		pubKeys[i] = JWTPublicKey{
+			ID:        k.ID,
+			Algorithm: k.Algorithm,
+			Key:       k.PublicKey,
+		}
+	}
+	return pubKeys
+}
+
+func (a *authService) generateJWTKey(ctx context.Context, algorithm string, activeAt time.Time) error {
+	var key crypto.Signer
+	var err error
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256.Alg():
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return fmt.Errorf("generate JWT key: unsupported algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return fmt.Errorf("generate JWT key: %w", err)
+	}
+	return a.createJWTKey(ctx, algorithm, key, activeAt)
+}
+
+func (a *authService) createJWTKey(ctx context.Context, algorithm string, key crypto.Signer, activeAt time.Time) error {
+	id, err := jwtKeyID(key.Public())
+	if err != nil {
+		return fmt.Errorf("create JWT key: %w", err)
+	}
+	_, err = a.systemRepo.CreateJWTKey(ctx, id, algorithm, key, activeAt)
+	if err != nil {
+		return fmt.Errorf("create JWT key: %w", err)
+	}
+	err = a.systemRepo.RetireJWTKeys(ctx, algorithm, id, activeAt)
+	if err != nil {
+		return fmt.Errorf("create JWT key: %w", err)
+	}
+	return nil
+}
+
+// signingKey returns the current signing key for algorithm.
+func (a *authService) signingKey(algorithm string) (*repos.JWTKeyModel, error) {
+	key, ok := a.jwtKeys.Load().signing[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("no signing key for algorithm %s", algorithm)
+	}
+	return key, nil
+}
+
+// signJWT signs token with the current signing key of its signing method and sets the kid header.
+func (a *authService) signJWT(token *jwt.Token) (string, error) {
+	key, err := a.signingKey(token.Method.Alg())
+	if err != nil {
+		return "", err
+	}
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// jwtKeyFunc looks up the public key referenced by the kid header of a token.
+func (a *authService) jwtKeyFunc(t *jwt.Token) (any, error) {
+	set := a.jwtKeys.Load()
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		// tokens signed before key rotation was introduced don't have a kid header
+		key, ok := set.signing[t.Method.Alg()]
+		if !ok {
+			return nil, errors.New("unknown key")
+		}
+		return key.PublicKey, nil
+	}
+	key, ok := set.published[kid]
+	if !ok || key.Algorithm != t.Method.Alg() {
+		return nil, errors.New("unknown key")
+	}
+	return key.PublicKey, nil
+}
+
+// jwtKeyID returns the base64 encoded SHA-1 hash of the public key.
+// RSA keys are hashed in PKCS #1 form to keep the IDs of keys created before key rotation was introduced.
+func jwtKeyID(pub crypto.PublicKey) (string, error) {
+	var der []byte
+	if rsaKey, ok := pub.(*rsa.PublicKey); ok {
+		der = x509.MarshalPKCS1PublicKey(rsaKey)
+	} else {
+		var err error
+		der, err = x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return "", fmt.Errorf("key ID: %w", err)
+		}
+	}
+	hash := sha1.Sum(der)
+	return base64.URLEncoding.EncodeToString(hash[:]), nil
+}
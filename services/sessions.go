@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+// sessionActivityInterval limits how often the last activity of a session is written to the session store.
+const sessionActivityInterval = time.Minute
+
+// UserSession is an active login of a user.
+type UserSession struct {
+	// ID is the sid of the session, which is also included in ID tokens. The session token itself is never exposed.
+	ID           string
+	LoginTime    time.Time
+	LastActivity time.Time
+	IP           string
+	UserAgent    string
+	// Device is an approximate description of the browser and operating system derived from the user agent.
+	Device  string
+	Current bool
+}
+
+type sessionStore struct {
+	repos.SessionRepository
+	codec scs.Codec
+}
+
+// NewSessionStore wraps sessionRepository to index the committed sessions by the ID of their authenticated user.
+// codec must be the codec of the session manager that uses the store.
+func NewSessionStore(sessionRepository repos.SessionRepository, codec scs.Codec) repos.SessionRepository {
+	return &sessionStore{
+		SessionRepository: sessionRepository,
+		codec:             codec,
+	}
+}
+
+func (s *sessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	return s.CommitCtx(context.Background(), token, b, expiry)
+}
+
+func (s *sessionStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	_, values, err := s.codec.Decode(b)
+	if err != nil {
+		return err
+	}
+	var userID *ulid.ULID
+	if id, ok := values["authUserID"].(ulid.ULID); ok {
+		userID = &id
+	}
+	return s.SessionRepository.CommitUserCtx(ctx, token, b, expiry, userID)
+}
+
+func (a *authService) IndexSessions(ctx context.Context) error {
+	sessions, err := a.sessionRepo.FindUnindexedCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("index sessions: %w", err)
+	}
+	for _, s := range sessions {
+		// the session store decodes the session to find its user
+		err = a.sessionRepo.CommitCtx(ctx, s.Token, s.Data, s.Expires)
+		if err != nil {
+			return fmt.Errorf("index sessions: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *authService) RecordSessionActivity(ctx context.Context, ip, userAgent string) {
+	if ip != "" && a.sessionManager.GetString(ctx, "ip") != ip {
+		a.sessionManager.Put(ctx, "ip", ip)
+	}
+	if userAgent != "" && a.sessionManager.GetString(ctx, "userAgent") != userAgent {
+		a.sessionManager.Put(ctx, "userAgent", userAgent)
+	}
+	if time.Since(time.Unix(a.sessionManager.GetInt64(ctx, "lastActivity"), 0)) > sessionActivityInterval {
+		a.sessionManager.Put(ctx, "lastActivity", time.Now().Unix())
+	}
+}
+
+func (a *authService) GetSessions(ctx context.Context, userID ulid.ULID) ([]*UserSession, error) {
+	sessions, _, err := a.userSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (a *authService) LogoutSession(ctx context.Context, userID ulid.ULID, sessionID string) error {
+	sessions, stored, err := a.userSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("logout session: %w", err)
+	}
+	for _, s := range sessions {
+		if s.ID != sessionID {
+			continue
+		}
+		if s.Current {
+			return a.Logout(ctx)
+		}
+		err = a.endSession(ctx, userID, stored[s.ID])
+		if err != nil {
+			return fmt.Errorf("logout session: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("logout session: %w", repos.ErrNoRecord)
+}
+
+func (a *authService) LogoutOtherSessions(ctx context.Context, userID ulid.ULID) error {
+	sessions, stored, err := a.userSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("logout other sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Current {
+			continue
+		}
+		err = a.endSession(ctx, userID, stored[s.ID])
+		if err != nil {
+			return fmt.Errorf("logout other sessions: %w", err)
+		}
+	}
+	return nil
+}
+
+// storedSession is a decoded session of the session store.
+type storedSession struct {
+	token  string
+	values map[string]any
+}
+
+// userSessions decodes the sessions of the user. The second return value maps the session IDs to the stored sessions.
+func (a *authService) userSessions(ctx context.Context, userID ulid.ULID) ([]*UserSession, map[string]storedSession, error) {
+	models, err := a.sessionRepo.FindByUserCtx(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	currentToken := a.sessionManager.Token(ctx)
+	sessions := make([]*UserSession, 0, len(models))
+	stored := make(map[string]storedSession, len(models))
+	for _, m := range models {
+		_, values, err := a.sessionManager.Codec.Decode(m.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode session: %w", err)
+		}
+		// the index might be outdated if the session was logged out or taken over by another user without a commit
+		if id, ok := values["authUserID"].(ulid.ULID); !ok || id != userID {
+			continue
+		}
+		sid, _ := values["sid"].(string)
+		if sid == "" {
+			continue
+		}
+		ip, _ := values["ip"].(string)
+		userAgent, _ := values["userAgent"].(string)
+		loginTime, _ := values["loginTime"].(int64)
+		if loginTime == 0 {
+			loginTime, _ = values["authTime"].(int64)
+		}
+		lastActivity, _ := values["lastActivity"].(int64)
+		if lastActivity < loginTime {
+			lastActivity = loginTime
+		}
+		sessions = append(sessions, &UserSession{
+			ID:           sid,
+			LoginTime:    time.Unix(loginTime, 0),
+			LastActivity: time.Unix(lastActivity, 0),
+			IP:           ip,
+			UserAgent:    userAgent,
+			Device:       deviceFromUserAgent(userAgent),
+			Current:      m.Token == currentToken,
+		})
+		stored[sid] = storedSession{
+			token:  m.Token,
+			values: values,
+		}
+	}
+	slices.SortFunc(sessions, func(s1, s2 *UserSession) int {
+		if s1.Current != s2.Current {
+			if s1.Current {
+				return -1
+			}
+			return 1
+		}
+		return s2.LastActivity.Compare(s1.LastActivity)
+	})
+	return sessions, stored, nil
+}
+
+// endSession deletes a session that is not the current session and notifies the clients that were used in it via back-channel logout.
+func (a *authService) endSession(ctx context.Context, userID ulid.ULID, session storedSession) error {
+	err := a.sessionRepo.DeleteCtx(ctx, session.token)
+	if err != nil {
+		return err
+	}
+	sid, _ := session.values["sid"].(string)
+	clients, _ := session.values["oauthClients"].([]string)
+	a.backchannelLogoutSession(ctx, userID, sid, clients)
+	return nil
+}
+
+// deviceFromUserAgent returns the browser and operating system of a user agent string, e.g. "Firefox, Linux".
+// The result is only an approximation and empty if neither could be detected.
+func deviceFromUserAgent(userAgent string) string {
+	var browser string
+	switch {
+	case strings.Contains(userAgent, "Edg/") || strings.Contains(userAgent, "EdgA/") || strings.Contains(userAgent, "EdgiOS/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/") || strings.Contains(userAgent, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/") || strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	var os string
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "iPhone"):
+		os = "iPhone"
+	case strings.Contains(userAgent, "iPad"):
+		os = "iPad"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "Macintosh"):
+		os = "macOS"
+	case strings.Contains(userAgent, "CrOS"):
+		os = "ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	if browser == "" || os == "" {
+		return browser + os
+	}
+	return browser + ", " + os
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/juho05/h-id/repos"
+)
+
+const (
+	// GrantTypeTokenExchange is the grant type of token exchange requests (RFC 8693).
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// TokenTypeAccessToken is the only token type that can be exchanged and issued by token exchange.
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// Actor identifies the client that acts on behalf of the subject of a token (RFC 8693, section 4.1).
+// If the subject token was itself issued by token exchange, Act contains the previous actor.
+type Actor struct {
+	Subject string `json:"sub"`
+	Act     *Actor `json:"act,omitempty"`
+}
+
+// oauthTokenExchange exchanges an access token of a user for a new access token with the same or fewer scopes.
+// The requesting client must be allowed to use token exchange and is recorded as the actor of the new token.
+// The subject token must have been issued to the requesting client or contain a custom scope of it, i.e. the requesting client
+// must be the client or one of the APIs the token is intended for.
+// If audience is set, it must be the audience of one of the custom scopes of the new token. The new token is then only valid for
+// that audience and cannot be used for H-ID itself.
+// The new token never outlives the subject token and no refresh token is issued.
+func (a *authService) oauthTokenExchange(ctx context.Context, client *repos.ClientModel, subjectToken, scope, audience string) (string, error) {
+	if client.Type != repos.ClientTypeConfidential || !client.TokenExchange {
+		return "", ErrUnauthorizedClient
+	}
+
+	subject, err := a.VerifyAccessToken(ctx, subjectToken, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return "", fmt.Errorf("token exchange: %w: invalid subject token", ErrInvalidGrant)
+		}
+		return "", fmt.Errorf("token exchange: %w", err)
+	}
+	if subject.UserID == nil {
+		return "", fmt.Errorf("token exchange: %w: subject token does not belong to a user", ErrInvalidGrant)
+	}
+
+	scopes := subject.Scopes
+	if scope != "" {
+		scopes = strings.Fields(scope)
+		for _, s := range scopes {
+			if !slices.Contains(subject.Scopes, s) {
+				return "", fmt.Errorf("token exchange: %w: %s", ErrInvalidScope, s)
+			}
+		}
+	}
+
+	customScopes, err := a.findCustomScopes(ctx, subject.Scopes)
+	if err != nil {
+		return "", fmt.Errorf("token exchange: %w", err)
+	}
+	if subject.ClientID != client.ID && !slices.ContainsFunc(customScopes, func(s *repos.ScopeModel) bool { return s.ClientID == client.ID }) {
+		return "", fmt.Errorf("token exchange: %w: subject token is not intended for the client", ErrUnauthorizedClient)
+	}
+
+	if audience != "" && !slices.ContainsFunc(customScopes, func(s *repos.ScopeModel) bool {
+		return s.Audience == audience && slices.Contains(scopes, s.Name)
+	}) {
+		return "", fmt.Errorf("token exchange: %w: %s", ErrInvalidTarget, audience)
+	}
+
+	var subjectData oauthTokenData
+	if len(subject.Data) > 0 {
+		err = json.Unmarshal(subject.Data, &subjectData)
+		if err != nil {
+			return "", fmt.Errorf("token exchange: decode token data: %w", err)
+		}
+	}
+	act := &Actor{
+		Subject: client.ID.String(),
+		Act:     subjectData.Act,
+	}
+
+	lifetime := min(time.Until(subject.Expires), signedTokenLifetime)
+	if lifetime <= 0 {
+		return "", fmt.Errorf("token exchange: %w: subject token expired", ErrInvalidGrant)
+	}
+
+	var access string
+	if client.AccessTokenFormat == repos.AccessTokenFormatJWT {
+		audiences := []string{audience}
+		if audience == "" {
+			audiences, err = a.accessTokenAudiences(ctx, scopes, nil)
+			if err != nil {
+				return "", fmt.Errorf("token exchange: %w", err)
+			}
+		}
+		access, err = a.createJWTAccessToken(client.ID, subject.UserID.String(), audiences, scopes, act, lifetime)
+		if err != nil {
+			return "", fmt.Errorf("token exchange: %w", err)
+		}
+	} else {
+		access = GenerateToken(64)
+	}
+
+	data, err := json.Marshal(oauthTokenData{
+		Audience: audience,
+		Act:      act,
+	})
+	if err != nil {
+		return "", fmt.Errorf("token exchange: %w", err)
+	}
+	_, err = a.oauthRepo.Create(ctx, client.ID, subject.UserID, subject.GrantID, repos.OAuthTokenAccess, hashTokenWeak(access), nil, scopes, data, lifetime)
+	if err != nil {
+		return "", fmt.Errorf("token exchange: %w", err)
+	}
+	return access, nil
+}
+
+// findCustomScopes returns the registered custom scopes among scopes.
+func (a *authService) findCustomScopes(ctx context.Context, scopes []string) ([]*repos.ScopeModel, error) {
+	custom := make([]*repos.ScopeModel, 0, len(scopes))
+	for _, s := range scopes {
+		if slices.Contains(userScopes, s) {
+			continue
+		}
+		scope, err := a.clientRepo.FindScopeByName(ctx, s)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoRecord) {
+				continue
+			}
+			return nil, err
+		}
+		custom = append(custom, scope)
+	}
+	return custom, nil
+}
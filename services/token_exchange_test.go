@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/juho05/h-id/repos"
+)
+
+func TestOAuthTokenExchange(t *testing.T) {
+	userID := ulid.Make()
+	client := func(typ repos.ClientType, tokenExchange bool) *repos.ClientModel {
+		return &repos.ClientModel{
+			BaseModel:         repos.BaseModel{ID: ulid.Make()},
+			Type:              typ,
+			AccessTokenFormat: repos.AccessTokenFormatOpaque,
+			TokenExchange:     tokenExchange,
+		}
+	}
+	requester := client(repos.ClientTypeConfidential, true)
+	frontend := client(repos.ClientTypePublic, false)
+	api := client(repos.ClientTypeConfidential, true)
+	disabled := client(repos.ClientTypeConfidential, false)
+	public := client(repos.ClientTypePublic, true)
+	clientRepo := &fakeClientRepo{
+		clients: []*repos.ClientModel{requester, frontend, api, disabled, public},
+		scopes: []*repos.ScopeModel{
+			{ClientID: requester.ID, Name: "reports:read", Audience: requester.ID.String()},
+			{ClientID: api.ID, Name: "invoices:read", Audience: "https://api.example.com"},
+		},
+	}
+
+	subjectTokens := map[string]*repos.OAuthTokenModel{
+		"own":       {ClientID: requester.ID, UserID: &userID, Scopes: []string{"openid", "invoices:read"}},
+		"for api":   {ClientID: frontend.ID, UserID: &userID, Scopes: []string{"openid", "reports:read", "invoices:read"}},
+		"other":     {ClientID: frontend.ID, UserID: &userID, Scopes: []string{"openid", "invoices:read"}},
+		"no user":   {ClientID: requester.ID, Scopes: []string{"reports:read"}},
+		"expired":   {ClientID: requester.ID, UserID: &userID, Scopes: []string{"openid"}, Expires: time.Now().Add(-time.Second)},
+		"exchanged": {ClientID: requester.ID, UserID: &userID, Scopes: []string{"openid", "invoices:read"}, Data: []byte(`{"act":{"sub":"previous"}}`)},
+	}
+
+	tests := []struct {
+		name     string
+		client   *repos.ClientModel
+		subject  string
+		scope    string
+		audience string
+		want     error
+		scopes   []string
+	}{
+		{name: "own token", client: requester, subject: "own", scopes: []string{"openid", "invoices:read"}},
+		{name: "narrower scope", client: requester, subject: "own", scope: "invoices:read", scopes: []string{"invoices:read"}},
+		{name: "wider scope", client: requester, subject: "own", scope: "openid profile", want: ErrInvalidScope},
+		{name: "token with scope of the client", client: requester, subject: "for api", scopes: []string{"openid", "reports:read", "invoices:read"}},
+		{name: "token of another client", client: requester, subject: "other", want: ErrUnauthorizedClient},
+		{name: "audience of a custom scope", client: requester, subject: "own", audience: "https://api.example.com", scopes: []string{"openid", "invoices:read"}},
+		{name: "audience of a removed scope", client: requester, subject: "own", scope: "openid", audience: "https://api.example.com", want: ErrInvalidTarget},
+		{name: "unknown audience", client: requester, subject: "own", audience: "https://evil.example.com", want: ErrInvalidTarget},
+		{name: "client ID as audience without scope", client: requester, subject: "own", audience: requester.ID.String(), want: ErrInvalidTarget},
+		{name: "token exchange disabled", client: disabled, subject: "own", want: ErrUnauthorizedClient},
+		{name: "public client", client: public, subject: "own", want: ErrUnauthorizedClient},
+		{name: "unknown subject token", client: requester, subject: "unknown", want: ErrInvalidGrant},
+		{name: "subject token without user", client: requester, subject: "no user", want: ErrInvalidGrant},
+		{name: "expired subject token", client: requester, subject: "expired", want: ErrInvalidGrant},
+		{name: "exchanged token", client: requester, subject: "exchanged", scopes: []string{"openid", "invoices:read"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauthRepo := &fakeOAuthRepo{}
+			for token, model := range subjectTokens {
+				m := *model
+				m.Category = repos.OAuthTokenAccess
+				m.TokenHash = hashTokenWeak(token)
+				if m.Expires.IsZero() {
+					m.Expires = time.Now().Add(10 * time.Minute)
+				}
+				oauthRepo.tokens = append(oauthRepo.tokens, &m)
+			}
+			a := &authService{
+				clientRepo: clientRepo,
+				oauthRepo:  oauthRepo,
+			}
+			access, err := a.oauthTokenExchange(context.Background(), tt.client, tt.subject, tt.scope, tt.audience)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("oauthTokenExchange() = %v, want %v", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("oauthTokenExchange() = %v", err)
+			}
+
+			issued, err := oauthRepo.Find(context.Background(), repos.OAuthTokenAccess, hashTokenWeak(access))
+			if err != nil {
+				t.Fatal("issued token not stored")
+			}
+			subject := subjectTokens[tt.subject]
+			if issued.ClientID != tt.client.ID || issued.UserID == nil || *issued.UserID != userID {
+				t.Errorf("issued token belongs to client %s and user %v", issued.ClientID, issued.UserID)
+			}
+			if !slices.Equal(issued.Scopes, tt.scopes) {
+				t.Errorf("scopes = %v, want %v", issued.Scopes, tt.scopes)
+			}
+			if issued.Expires.After(time.Now().Add(10 * time.Minute)) {
+				t.Errorf("issued token outlives the subject token")
+			}
+			var data oauthTokenData
+			err = json.Unmarshal(issued.Data, &data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Audience != tt.audience {
+				t.Errorf("audience = %q, want %q", data.Audience, tt.audience)
+			}
+			if data.Act == nil || data.Act.Subject != tt.client.ID.String() {
+				t.Fatalf("act = %+v, want the requesting client", data.Act)
+			}
+			if len(subject.Data) > 0 && (data.Act.Act == nil || data.Act.Act.Subject != "previous") {
+				t.Errorf("previous actor is not kept: %+v", data.Act.Act)
+			}
+		})
+	}
+}
+
+func TestVerifyAccessTokenRejectsExchangedAudience(t *testing.T) {
+	userID := ulid.Make()
+	oauthRepo := &fakeOAuthRepo{tokens: []*repos.OAuthTokenModel{
+		{Category: repos.OAuthTokenAccess, TokenHash: hashTokenWeak("api"), UserID: &userID, Scopes: []string{"openid"}, Data: []byte(`{"aud":"https://api.example.com"}`), Expires: time.Now().Add(time.Minute)},
+	}}
+	a := &authService{oauthRepo: oauthRepo}
+	_, err := a.VerifyAccessToken(context.Background(), "api", nil)
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("VerifyAccessToken() = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
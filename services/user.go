@@ -39,6 +39,9 @@ type UserService interface {
 	GetPasskey(ctx context.Context, userID, id ulid.ULID) (*repos.Passkey, error)
 	UpdatePasskey(ctx context.Context, userID, id ulid.ULID, name string) error
 	DeletePasskey(ctx context.Context, userID, id ulid.ULID) error
+	GetAppPasswords(ctx context.Context, userID ulid.ULID) ([]*repos.AppPassword, error)
+	GetAppPassword(ctx context.Context, userID, id ulid.ULID) (*repos.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userID, id ulid.ULID) error
 	Delete(ctx context.Context, id ulid.ULID) error
 }
 
@@ -210,8 +213,25 @@ func (u *userService) DeletePasskey(ctx context.Context, userID, id ulid.ULID) e
 	return u.userRepo.DeletePasskey(ctx, userID, id)
 }
 
+func (u *userService) GetAppPasswords(ctx context.Context, userID ulid.ULID) ([]*repos.AppPassword, error) {
+	return u.userRepo.GetAppPasswords(ctx, userID)
+}
+
+func (u *userService) GetAppPassword(ctx context.Context, userID, id ulid.ULID) (*repos.AppPassword, error) {
+	return u.userRepo.GetAppPassword(ctx, userID, id)
+}
+
+func (u *userService) DeleteAppPassword(ctx context.Context, userID, id ulid.ULID) error {
+	return u.userRepo.DeleteAppPassword(ctx, userID, id)
+}
+
 func (u *userService) Delete(ctx context.Context, id ulid.ULID) error {
-	err := u.userRepo.Delete(ctx, id)
+	err := u.authService.BackchannelLogoutUser(ctx, id)
+	if err != nil {
+		log.Errorf("Failed to send back-channel logout for deleted user %s: %s", id, err)
+	}
+
+	err = u.userRepo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}